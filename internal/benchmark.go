@@ -0,0 +1,201 @@
+/*
+ * Copyright Elasticsearch B.V. and/or licensed to Elasticsearch B.V. under one
+ * or more contributor license agreements. See the NOTICE file distributed with
+ * this work for additional information regarding copyright
+ * ownership. Elasticsearch B.V. licenses this file to you under
+ * the Apache License, Version 2.0 (the "License"); you may
+ * not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *	http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing,
+ * software distributed under the License is distributed on an
+ * "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+ * KIND, either express or implied.  See the License for the
+ * specific language governing permissions and limitations
+ * under the License.
+ */
+
+package internal
+
+import (
+	"math"
+	"runtime"
+	"slices"
+	"time"
+
+	"go.opentelemetry.io/collector/pdata/plog"
+	"go.opentelemetry.io/collector/pdata/pmetric"
+	"go.opentelemetry.io/collector/pdata/pprofile"
+	"go.opentelemetry.io/collector/pdata/ptrace"
+)
+
+const (
+	// maxBenchmarkRuns and maxBenchmarkDuration bound every benchmark, whatever
+	// its limits, as the runs block the playground until they are done.
+	maxBenchmarkRuns     = 100_000
+	maxBenchmarkDuration = 30 * time.Second
+)
+
+// benchmarkSettings holds the limits of a benchmark, see WithBenchmark.
+type benchmarkSettings struct {
+	runs     int
+	duration time.Duration
+}
+
+// clamped returns the limits bounded by maxBenchmarkRuns and
+// maxBenchmarkDuration, which also replace the disabled ones.
+func (s benchmarkSettings) clamped() benchmarkSettings {
+	if s.runs <= 0 || s.runs > maxBenchmarkRuns {
+		s.runs = maxBenchmarkRuns
+	}
+	if s.duration <= 0 || s.duration > maxBenchmarkDuration {
+		s.duration = maxBenchmarkDuration
+	}
+	return s
+}
+
+// done reports whether a benchmark that performed the given number of runs
+// since start reached its limits.
+func (s benchmarkSettings) done(runs int, start time.Time) bool {
+	if s.runs > 0 && runs >= s.runs {
+		return true
+	}
+	return s.duration > 0 && time.Since(start) >= s.duration
+}
+
+// Benchmark holds the statistics of the runs of a benchmark, see WithBenchmark.
+// Times are in microseconds, and only account for the components processing
+// the payload, not for copying it between runs.
+type Benchmark struct {
+	Runs int   `json:"runs"`
+	Min  int64 `json:"min"`
+	P50  int64 `json:"p50"`
+	P95  int64 `json:"p95"`
+	Max  int64 `json:"max"`
+	// RecordsPerSecond is the number of records, such as log records, spans,
+	// data points or samples, processed per second.
+	RecordsPerSecond float64 `json:"recordsPerSecond"`
+	// AllocsPerRun and BytesPerRun are the average number of heap allocations,
+	// and of bytes allocated, by a run.
+	AllocsPerRun uint64 `json:"allocsPerRun"`
+	BytesPerRun  uint64 `json:"bytesPerRun"`
+}
+
+// runBenchmark runs consume against copies of input until the execution's
+// benchmark limits are reached, or it's cancelled, and sets the statistics of
+// the runs as the result's Benchmark. A cancelled benchmark holds the runs
+// completed, and the result is marked as cancelled. The data the runs record,
+// such as logs, is discarded.
+func runBenchmark[T any](
+	exec *execution,
+	res *Result,
+	input T,
+	copyInput func(T) T,
+	countRecords func(T) int,
+	consume func(T) (T, error),
+) error {
+	durations, allocs, bytes, err := measureRuns(exec, exec.settings.benchmark, input, copyInput, consume)
+	if err != nil {
+		return err
+	}
+	res.Benchmark = newBenchmark(durations, countRecords(input), allocs, bytes)
+	if cause := exec.cancellationCause(); cause != nil {
+		res.Cancelled = true
+		res.CancellationReason = cause.Error()
+	}
+	return nil
+}
+
+// measureRuns runs consume against copies of input until the given limits,
+// bounded by maxBenchmarkRuns and maxBenchmarkDuration, are reached, or the
+// execution is cancelled. It returns the duration of each completed run, and
+// the number of heap allocations and bytes allocated by all of them.
+func measureRuns[T any](
+	exec *execution,
+	limits benchmarkSettings,
+	input T,
+	copyInput func(T) T,
+	consume func(T) (T, error),
+) (durations []time.Duration, allocs, bytes uint64, err error) {
+	limits = limits.clamped()
+	var before, after runtime.MemStats
+	start := time.Now()
+	for !limits.done(len(durations), start) && exec.cancellationCause() == nil {
+		runInput := copyInput(input)
+		runtime.ReadMemStats(&before)
+		runStart := time.Now()
+		_, err = consume(runInput)
+		elapsed := time.Since(runStart)
+		runtime.ReadMemStats(&after)
+		if err != nil {
+			// The run interrupted by a cancellation isn't measured.
+			if exec.cancellationCause() != nil {
+				return durations, allocs, bytes, nil
+			}
+			return nil, 0, 0, err
+		}
+		durations = append(durations, elapsed)
+		allocs += after.Mallocs - before.Mallocs
+		bytes += after.TotalAlloc - before.TotalAlloc
+		exec.discardTelemetry()
+	}
+	return durations, allocs, bytes, nil
+}
+
+// newBenchmark computes the statistics of runs that took the given durations,
+// processing records each, and allocated allocs objects for a total of bytes.
+func newBenchmark(durations []time.Duration, records int, allocs, bytes uint64) *Benchmark {
+	benchmark := &Benchmark{Runs: len(durations)}
+	if len(durations) == 0 {
+		return benchmark
+	}
+
+	slices.Sort(durations)
+	var total time.Duration
+	for _, d := range durations {
+		total += d
+	}
+	benchmark.Min = durations[0].Microseconds()
+	benchmark.P50 = percentile(durations, 0.50).Microseconds()
+	benchmark.P95 = percentile(durations, 0.95).Microseconds()
+	benchmark.Max = durations[len(durations)-1].Microseconds()
+	if total > 0 {
+		benchmark.RecordsPerSecond = float64(records*len(durations)) / total.Seconds()
+	}
+	benchmark.AllocsPerRun = allocs / uint64(len(durations))
+	benchmark.BytesPerRun = bytes / uint64(len(durations))
+	return benchmark
+}
+
+// percentile returns the nearest-rank percentile q, between 0 and 1, of the
+// sorted durations.
+func percentile(sorted []time.Duration, q float64) time.Duration {
+	rank := int(math.Ceil(q * float64(len(sorted))))
+	return sorted[max(rank, 1)-1]
+}
+
+func copyLogs(logs plog.Logs) plog.Logs {
+	dest := plog.NewLogs()
+	logs.CopyTo(dest)
+	return dest
+}
+
+func copyTraces(traces ptrace.Traces) ptrace.Traces {
+	dest := ptrace.NewTraces()
+	traces.CopyTo(dest)
+	return dest
+}
+
+func copyMetrics(metrics pmetric.Metrics) pmetric.Metrics {
+	dest := pmetric.NewMetrics()
+	metrics.CopyTo(dest)
+	return dest
+}
+
+func copyProfiles(profiles pprofile.Profiles) pprofile.Profiles {
+	dest := pprofile.NewProfiles()
+	profiles.CopyTo(dest)
+	return dest
+}
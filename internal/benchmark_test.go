@@ -0,0 +1,126 @@
+/*
+ * Copyright Elasticsearch B.V. and/or licensed to Elasticsearch B.V. under one
+ * or more contributor license agreements. See the NOTICE file distributed with
+ * this work for additional information regarding copyright
+ * ownership. Elasticsearch B.V. licenses this file to you under
+ * the Apache License, Version 2.0 (the "License"); you may
+ * not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *	http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing,
+ * software distributed under the License is distributed on an
+ * "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+ * KIND, either express or implied.  See the License for the
+ * specific language governing permissions and limitations
+ * under the License.
+ */
+
+package internal
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_newBenchmark(t *testing.T) {
+	durations := []time.Duration{
+		5 * time.Millisecond, 1 * time.Millisecond, 3 * time.Millisecond, 2 * time.Millisecond, 4 * time.Millisecond,
+		10 * time.Millisecond, 9 * time.Millisecond, 8 * time.Millisecond, 7 * time.Millisecond, 6 * time.Millisecond,
+	}
+
+	benchmark := newBenchmark(durations, 2, 100, 1000)
+	assert.Equal(t, &Benchmark{
+		Runs:             10,
+		Min:              1000,
+		P50:              5000,
+		P95:              10000,
+		Max:              10000,
+		RecordsPerSecond: 20 / 0.055,
+		AllocsPerRun:     10,
+		BytesPerRun:      100,
+	}, benchmark)
+}
+
+func Test_newBenchmark_NoRuns(t *testing.T) {
+	assert.Equal(t, &Benchmark{}, newBenchmark(nil, 1, 0, 0))
+}
+
+func Test_benchmarkSettings_done(t *testing.T) {
+	start := time.Now()
+	assert.False(t, benchmarkSettings{runs: 2}.done(1, start))
+	assert.True(t, benchmarkSettings{runs: 2}.done(2, start))
+	assert.False(t, benchmarkSettings{duration: time.Hour}.done(100, start))
+	assert.True(t, benchmarkSettings{duration: time.Millisecond}.done(0, start.Add(-time.Second)))
+	assert.True(t, benchmarkSettings{runs: 2, duration: time.Hour}.done(2, start))
+}
+
+func Test_benchmarkSettings_clamped(t *testing.T) {
+	assert.Equal(t, benchmarkSettings{runs: 5, duration: time.Second}, benchmarkSettings{runs: 5, duration: time.Second}.clamped())
+	assert.Equal(t, benchmarkSettings{runs: maxBenchmarkRuns, duration: maxBenchmarkDuration}, benchmarkSettings{runs: maxBenchmarkRuns + 1, duration: time.Hour}.clamped())
+	// Disabled limits are bounded as well
+	assert.Equal(t, benchmarkSettings{runs: 5, duration: maxBenchmarkDuration}, benchmarkSettings{runs: 5}.clamped())
+	assert.Equal(t, benchmarkSettings{runs: maxBenchmarkRuns, duration: time.Second}, benchmarkSettings{duration: time.Second}.clamped())
+}
+
+func Test_Executor_ExecuteLogsBenchmark(t *testing.T) {
+	executor := NewTransformProcessorExecutor()
+	config := "transform:\n  log_statements:\n    - set(log.attributes[\"a\"], log.body)"
+	payload := `{"resourceLogs":[{"scopeLogs":[{"logRecords":[{"body":{"stringValue":"first"}},{"body":{"stringValue":"second"}}]}]}]}`
+
+	result, err := executor.ExecuteLogs(config, payload, WithBenchmark(5, 0))
+	require.NoError(t, err)
+	require.NotNil(t, result.Benchmark)
+	assert.Equal(t, 5, result.Benchmark.Runs)
+	assert.LessOrEqual(t, result.Benchmark.Min, result.Benchmark.P50)
+	assert.LessOrEqual(t, result.Benchmark.P50, result.Benchmark.P95)
+	assert.LessOrEqual(t, result.Benchmark.P95, result.Benchmark.Max)
+	assert.Positive(t, result.Benchmark.RecordsPerSecond)
+	assert.Positive(t, result.Benchmark.AllocsPerRun)
+	// The runs don't change the reported output
+	assert.Contains(t, result.Value, `"key":"a","value":{"stringValue":"first"}`)
+
+	result, err = executor.ExecuteLogs(config, payload, WithBenchmark(0, 10*time.Millisecond))
+	require.NoError(t, err)
+	require.NotNil(t, result.Benchmark)
+	assert.Positive(t, result.Benchmark.Runs)
+
+	result, err = executor.ExecuteLogs(config, payload)
+	require.NoError(t, err)
+	assert.Nil(t, result.Benchmark)
+}
+
+func Test_Executor_ExecuteTracesBenchmark_Cancelled(t *testing.T) {
+	executor := NewTransformProcessorExecutor()
+	config := "transform:\n  trace_statements:\n    - set(span.attributes[\"a\"], span.name)"
+	payload := `{"resourceSpans":[{"scopeSpans":[{"spans":[{"name":"span"}]}]}]}`
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	result, err := executor.ExecuteTraces(config, payload, WithContext(ctx), WithBenchmark(0, time.Hour))
+	require.NoError(t, err)
+	assert.True(t, result.Cancelled)
+	assert.Nil(t, result.Benchmark)
+}
+
+func Test_Executor_ExecuteLogsBenchmark_CancelledDuringRuns(t *testing.T) {
+	executor := NewTransformProcessorExecutor()
+	config := "transform:\n  log_statements:\n    - set(log.attributes[\"a\"], log.body)"
+	payload := `{"resourceLogs":[{"scopeLogs":[{"logRecords":[{"body":{"stringValue":"a"}}]}]}]}`
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	result, err := executor.ExecuteLogs(config, payload, WithContext(ctx), WithBenchmark(0, time.Hour))
+	require.NoError(t, err)
+	assert.Contains(t, result.Value, `{"key":"a","value":{"stringValue":"a"}}`)
+	// The benchmark is cut short, and reports the runs completed
+	assert.True(t, result.Cancelled)
+	assert.Equal(t, context.DeadlineExceeded.Error(), result.CancellationReason)
+	require.NotNil(t, result.Benchmark)
+	assert.Positive(t, result.Benchmark.Runs)
+}
@@ -0,0 +1,296 @@
+/*
+ * Copyright Elasticsearch B.V. and/or licensed to Elasticsearch B.V. under one
+ * or more contributor license agreements. See the NOTICE file distributed with
+ * this work for additional information regarding copyright
+ * ownership. Elasticsearch B.V. licenses this file to you under
+ * the Apache License, Version 2.0 (the "License"); you may
+ * not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *	http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing,
+ * software distributed under the License is distributed on an
+ * "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+ * KIND, either express or implied.  See the License for the
+ * specific language governing permissions and limitations
+ * under the License.
+ */
+
+package internal
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"maps"
+	"reflect"
+	"slices"
+	"strconv"
+)
+
+// FieldChange is a change made by a debug step to a field of the payload.
+type FieldChange struct {
+	// Index holds the positions of the changed item and of its parents in the
+	// payload, from the resource down, such as [resource, scope, log record].
+	// It's empty for the fields shared by the whole payload, such as the
+	// profiles dictionary.
+	Index []int `json:"index"`
+	// Path is the path of the changed field within the item, using the OTLP
+	// JSON field names, such as `attributes["service.name"]` or
+	// "events[0].name". It's empty if the whole item was added or deleted.
+	Path string `json:"path"`
+	// Operation is either "add", "set" or "delete".
+	Operation string `json:"operation"`
+	OldValue  any    `json:"oldValue,omitempty"`
+	NewValue  any    `json:"newValue,omitempty"`
+}
+
+const (
+	changeOperationAdd    = "add"
+	changeOperationSet    = "set"
+	changeOperationDelete = "delete"
+)
+
+// otlpJSONLevels lists, for the key holding the resources of each signal, the
+// keys holding the items of the levels below, from the scopes down.
+var otlpJSONLevels = map[string][]string{
+	"resourceLogs":     {"scopeLogs", "logRecords"},
+	"resourceSpans":    {"scopeSpans", "spans"},
+	"resourceMetrics":  {"scopeMetrics", "metrics"},
+	"resourceProfiles": {"scopeProfiles", "profiles"},
+}
+
+// anyValueKeys are the keys of the OTLP JSON AnyValue variants.
+var anyValueKeys = []string{"stringValue", "boolValue", "intValue", "doubleValue", "bytesValue", "arrayValue", "kvlistValue"}
+
+// attributeMap is an OTLP JSON list of key-value pairs, such as attributes,
+// whose entries are addressed by key.
+type attributeMap map[string]any
+
+// parsePayloadJSON parses an OTLP JSON payload to be diffed with diffPayloads.
+func parsePayloadJSON(payload []byte) (map[string]any, error) {
+	decoder := json.NewDecoder(bytes.NewReader(payload))
+	decoder.UseNumber()
+	var parsed map[string]any
+	if err := decoder.Decode(&parsed); err != nil {
+		return nil, fmt.Errorf("failed to parse payload: %w", err)
+	}
+	return parsed, nil
+}
+
+// diffPayloads returns the changes turning the old OTLP JSON payload into the
+// new one. Items are matched by their position in the payload.
+func diffPayloads(oldPayload, newPayload map[string]any) []FieldChange {
+	var changes changeSet
+	for _, key := range slices.Sorted(maps.Keys(mergedKeys(oldPayload, newPayload))) {
+		levels, ok := otlpJSONLevels[key]
+		if !ok {
+			changes.diff(nil, key, normalizeJSONValue(oldPayload[key]), normalizeJSONValue(newPayload[key]))
+			continue
+		}
+		oldItems, _ := oldPayload[key].([]any)
+		newItems, _ := newPayload[key].([]any)
+		changes.diffItems(nil, levels, oldItems, newItems)
+	}
+	return changes
+}
+
+type changeSet []FieldChange
+
+// diffItems diffs the items at the same positions of both lists, whose own
+// items are held by the first of the given level keys.
+func (c *changeSet) diffItems(parent []int, levels []string, oldItems, newItems []any) {
+	for i := range max(len(oldItems), len(newItems)) {
+		index := append(slices.Clip(parent), i)
+		switch {
+		case i >= len(newItems):
+			c.add(index, "", changeOperationDelete, normalizeJSONValue(oldItems[i]), nil)
+			continue
+		case i >= len(oldItems):
+			c.add(index, "", changeOperationAdd, nil, normalizeJSONValue(newItems[i]))
+			continue
+		}
+
+		oldItem, _ := oldItems[i].(map[string]any)
+		newItem, _ := newItems[i].(map[string]any)
+		if len(levels) == 0 {
+			c.diff(index, "", normalizeJSONValue(oldItem), normalizeJSONValue(newItem))
+			continue
+		}
+		oldChildren, _ := oldItem[levels[0]].([]any)
+		newChildren, _ := newItem[levels[0]].([]any)
+		c.diff(index, "", normalizeJSONValue(withoutKey(oldItem, levels[0])), normalizeJSONValue(withoutKey(newItem, levels[0])))
+		c.diffItems(index, levels[1:], oldChildren, newChildren)
+	}
+}
+
+// diff diffs two normalized values at the given path of an item.
+func (c *changeSet) diff(index []int, path string, oldValue, newValue any) {
+	if reflect.DeepEqual(oldValue, newValue) {
+		return
+	}
+	// Attributes are diffed by key, even when the whole list is added or deleted.
+	if _, ok := newValue.(attributeMap); ok && oldValue == nil {
+		oldValue = attributeMap{}
+	}
+	if _, ok := oldValue.(attributeMap); ok && newValue == nil {
+		newValue = attributeMap{}
+	}
+
+	switch {
+	case oldValue == nil:
+		c.add(index, path, changeOperationAdd, nil, newValue)
+	case newValue == nil:
+		c.add(index, path, changeOperationDelete, oldValue, nil)
+	case isKind[attributeMap](oldValue, newValue):
+		oldMap, newMap := oldValue.(attributeMap), newValue.(attributeMap)
+		for _, key := range slices.Sorted(maps.Keys(mergedKeys(oldMap, newMap))) {
+			c.diff(index, fmt.Sprintf("%s[%q]", path, key), oldMap[key], newMap[key])
+		}
+	case isKind[map[string]any](oldValue, newValue):
+		oldMap, newMap := oldValue.(map[string]any), newValue.(map[string]any)
+		for _, key := range slices.Sorted(maps.Keys(mergedKeys(oldMap, newMap))) {
+			fieldPath := key
+			if path != "" {
+				fieldPath = path + "." + key
+			}
+			c.diff(index, fieldPath, oldMap[key], newMap[key])
+		}
+	case isKind[[]any](oldValue, newValue):
+		oldSlice, newSlice := oldValue.([]any), newValue.([]any)
+		for i := range max(len(oldSlice), len(newSlice)) {
+			var oldElement, newElement any
+			if i < len(oldSlice) {
+				oldElement = oldSlice[i]
+			}
+			if i < len(newSlice) {
+				newElement = newSlice[i]
+			}
+			c.diff(index, fmt.Sprintf("%s[%d]", path, i), oldElement, newElement)
+		}
+	default:
+		c.add(index, path, changeOperationSet, oldValue, newValue)
+	}
+}
+
+func (c *changeSet) add(index []int, path, operation string, oldValue, newValue any) {
+	*c = append(*c, FieldChange{Index: index, Path: path, Operation: operation, OldValue: oldValue, NewValue: newValue})
+}
+
+func isKind[K any](values ...any) bool {
+	for _, value := range values {
+		if _, ok := value.(K); !ok {
+			return false
+		}
+	}
+	return true
+}
+
+// normalizeJSONValue returns the given OTLP JSON value with its AnyValues
+// replaced by the values they hold, and its lists of key-value pairs by
+// attributeMaps.
+func normalizeJSONValue(value any) any {
+	switch v := value.(type) {
+	case map[string]any:
+		if anyValue, ok := normalizeAnyValue(v); ok {
+			return anyValue
+		}
+		normalized := make(map[string]any, len(v))
+		for key, field := range v {
+			normalized[key] = normalizeJSONValue(field)
+		}
+		return normalized
+	case []any:
+		if attributes, ok := normalizeKeyValues(v); ok {
+			return attributes
+		}
+		normalized := make([]any, len(v))
+		for i, element := range v {
+			normalized[i] = normalizeJSONValue(element)
+		}
+		return normalized
+	default:
+		return value
+	}
+}
+
+// normalizeAnyValue returns the value held by an OTLP JSON AnyValue, and false
+// if the given object isn't one.
+func normalizeAnyValue(object map[string]any) (any, bool) {
+	if len(object) != 1 {
+		return nil, false
+	}
+	for key, value := range object {
+		if !slices.Contains(anyValueKeys, key) {
+			return nil, false
+		}
+		switch key {
+		case "intValue":
+			if s, ok := value.(string); ok {
+				if i, err := strconv.ParseInt(s, 10, 64); err == nil {
+					return json.Number(strconv.FormatInt(i, 10)), true
+				}
+			}
+		case "arrayValue", "kvlistValue":
+			container, _ := value.(map[string]any)
+			values, _ := container["values"].([]any)
+			if key == "kvlistValue" {
+				attributes, _ := normalizeKeyValues(values)
+				if attributes == nil {
+					attributes = attributeMap{}
+				}
+				return attributes, true
+			}
+			normalized := make([]any, len(values))
+			for i, element := range values {
+				normalized[i] = normalizeJSONValue(element)
+			}
+			return normalized, true
+		}
+		return value, true
+	}
+	return nil, false
+}
+
+// normalizeKeyValues returns a list of OTLP JSON key-value pairs as an
+// attributeMap, and false if the given list isn't one.
+func normalizeKeyValues(list []any) (attributeMap, bool) {
+	if len(list) == 0 {
+		return nil, false
+	}
+	attributes := make(attributeMap, len(list))
+	for _, element := range list {
+		pair, ok := element.(map[string]any)
+		if !ok || len(pair) > 2 {
+			return nil, false
+		}
+		key, ok := pair["key"].(string)
+		if !ok {
+			return nil, false
+		}
+		value, _ := pair["value"].(map[string]any)
+		attributes[key], _ = normalizeAnyValue(value)
+	}
+	return attributes, true
+}
+
+func mergedKeys[M ~map[string]V, V any](a, b M) map[string]struct{} {
+	keys := make(map[string]struct{}, len(a)+len(b))
+	for key := range a {
+		keys[key] = struct{}{}
+	}
+	for key := range b {
+		keys[key] = struct{}{}
+	}
+	return keys
+}
+
+func withoutKey(object map[string]any, key string) map[string]any {
+	if _, ok := object[key]; !ok {
+		return object
+	}
+	cp := maps.Clone(object)
+	delete(cp, key)
+	return cp
+}
@@ -0,0 +1,81 @@
+/*
+ * Copyright Elasticsearch B.V. and/or licensed to Elasticsearch B.V. under one
+ * or more contributor license agreements. See the NOTICE file distributed with
+ * this work for additional information regarding copyright
+ * ownership. Elasticsearch B.V. licenses this file to you under
+ * the Apache License, Version 2.0 (the "License"); you may
+ * not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *	http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing,
+ * software distributed under the License is distributed on an
+ * "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+ * KIND, either express or implied.  See the License for the
+ * specific language governing permissions and limitations
+ * under the License.
+ */
+
+package internal
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_diffPayloads(t *testing.T) {
+	oldPayload := `{"resourceLogs":[{"resource":{"attributes":[{"key":"service.name","value":{"stringValue":"a"}}]},"scopeLogs":[{"scope":{"name":"s"},"logRecords":[` +
+		`{"severityText":"info","body":{"stringValue":"a"},"attributes":[{"key":"keep","value":{"intValue":"1"}},{"key":"drop","value":{"boolValue":true}}]},` +
+		`{"body":{"stringValue":"b"}}]}]}]}`
+	newPayload := `{"resourceLogs":[{"resource":{"attributes":[{"key":"service.name","value":{"stringValue":"b"}}]},"scopeLogs":[{"scope":{"name":"s"},"logRecords":[` +
+		`{"body":{"stringValue":"a"},"attributes":[{"key":"keep","value":{"intValue":"1"}},{"key":"list","value":{"arrayValue":{"values":[{"intValue":"2"}]}}}]},` +
+		`{"body":{"stringValue":"b"},"attributes":[{"key":"new","value":{"stringValue":"x"}}]}]}]}]}`
+
+	changes := diffPayloads(parsePayload(t, oldPayload), parsePayload(t, newPayload))
+	assert.Equal(t, []FieldChange{
+		{Index: []int{0}, Path: `resource.attributes["service.name"]`, Operation: "set", OldValue: "a", NewValue: "b"},
+		{Index: []int{0, 0, 0}, Path: `attributes["drop"]`, Operation: "delete", OldValue: true},
+		{Index: []int{0, 0, 0}, Path: `attributes["list"]`, Operation: "add", NewValue: []any{json.Number("2")}},
+		{Index: []int{0, 0, 0}, Path: "severityText", Operation: "delete", OldValue: "info"},
+		// Attributes added to an item without any are reported by key
+		{Index: []int{0, 0, 1}, Path: `attributes["new"]`, Operation: "add", NewValue: "x"},
+	}, changes)
+}
+
+func Test_diffPayloads_AddedAndDeletedItems(t *testing.T) {
+	oldPayload := `{"resourceMetrics":[{"scopeMetrics":[{"metrics":[{"name":"a","gauge":{"dataPoints":[{"asInt":"1"}]}},{"name":"b"}]}]}]}`
+	newPayload := `{"resourceMetrics":[{"scopeMetrics":[{"metrics":[{"name":"a","gauge":{"dataPoints":[{"asInt":"2"},{"asInt":"3"}]}}]}]},{"resource":{}}]}`
+
+	changes := diffPayloads(parsePayload(t, oldPayload), parsePayload(t, newPayload))
+	assert.Equal(t, []FieldChange{
+		{Index: []int{0, 0, 0}, Path: "gauge.dataPoints[0].asInt", Operation: "set", OldValue: "1", NewValue: "2"},
+		{Index: []int{0, 0, 0}, Path: "gauge.dataPoints[1]", Operation: "add", NewValue: map[string]any{"asInt": "3"}},
+		{Index: []int{0, 0, 1}, Operation: "delete", OldValue: map[string]any{"name": "b"}},
+		{Index: []int{1}, Operation: "add", NewValue: map[string]any{"resource": map[string]any{}}},
+	}, changes)
+}
+
+func Test_diffPayloads_NoChanges(t *testing.T) {
+	payload := readTestData(t, "traces.json")
+	assert.Empty(t, diffPayloads(parsePayload(t, payload), parsePayload(t, payload)))
+}
+
+func Test_normalizeJSONValue_KvlistValue(t *testing.T) {
+	value := normalizeJSONValue(map[string]any{
+		"kvlistValue": map[string]any{"values": []any{
+			map[string]any{"key": "a", "value": map[string]any{"doubleValue": 1.5}},
+		}},
+	})
+	assert.Equal(t, attributeMap{"a": 1.5}, value)
+	assert.Equal(t, attributeMap{}, normalizeJSONValue(map[string]any{"kvlistValue": map[string]any{}}))
+}
+
+func parsePayload(t *testing.T, payload string) map[string]any {
+	parsed, err := parsePayloadJSON([]byte(payload))
+	require.NoError(t, err)
+	return parsed
+}
@@ -20,8 +20,13 @@
 package internal
 
 import (
+	"crypto/sha256"
+	"encoding/hex"
 	"fmt"
+	"maps"
+	"slices"
 	"strings"
+	"time"
 
 	"github.com/go-viper/mapstructure/v2"
 	"go.opentelemetry.io/collector/component"
@@ -33,6 +38,10 @@ import (
 type parsedConfig[C any] struct {
 	Key   string
 	Value *C
+	// hash identifies the configuration and the settings it was parsed with,
+	// when it was parsed by an executor, which makes the components running it
+	// reusable across executions. See processorConsumer.acquire.
+	hash string
 }
 
 func (p parsedConfig[C]) clone() (C, error) {
@@ -49,7 +58,40 @@ func (p parsedConfig[C]) clone() (C, error) {
 	return dst, err
 }
 
+// parseConfig parses and validates the configuration, which holds one or more
+// component configurations.
 func parseConfig[C any](id component.ID, yamlConfig string, createDefaultConfig func() *C) ([]parsedConfig[C], error) {
+	cfgs, err := unmarshalConfig(id, yamlConfig, createDefaultConfig)
+	if err != nil {
+		return nil, err
+	}
+	if err = validateConfigs(cfgs); err != nil {
+		return nil, err
+	}
+	return cfgs, nil
+}
+
+// parseExecutionConfig is like parseConfig, but records the time spent
+// unmarshalling and validating the configuration on the execution.
+func parseExecutionConfig[C any](exec *execution, id component.ID, yamlConfig string, createDefaultConfig func() *C) ([]parsedConfig[C], error) {
+	start := time.Now()
+	cfgs, err := unmarshalConfig(id, yamlConfig, createDefaultConfig)
+	exec.timePhase(phaseParse, start)
+	if err != nil {
+		return nil, err
+	}
+
+	start = time.Now()
+	err = validateConfigs(cfgs)
+	exec.timePhase(phaseValidate, start)
+	if err != nil {
+		return nil, err
+	}
+	return cfgs, nil
+}
+
+// unmarshalConfig unmarshals the configuration, without validating it.
+func unmarshalConfig[C any](id component.ID, yamlConfig string, createDefaultConfig func() *C) ([]parsedConfig[C], error) {
 	deserializedYaml, err := confmap.NewRetrievedFromYAML([]byte(yamlConfig))
 	if err != nil {
 		return nil, err
@@ -59,21 +101,22 @@ func parseConfig[C any](id component.ID, yamlConfig string, createDefaultConfig
 	if err != nil {
 		return nil, err
 	}
+	deserializedConf = withoutExtensionsConfig(deserializedConf)
 
 	if hasMultipleConfigs(id, deserializedConf) {
-		return parseMultipleConfigs[C](yamlConfig, deserializedConf, createDefaultConfig)
+		return unmarshalMultipleConfigs[C](yamlConfig, deserializedConf, createDefaultConfig)
 	}
 
 	defaultConfig := createDefaultConfig()
-	err = unmarshalValidConfig(deserializedConf, defaultConfig)
+	err = unmarshalComponentConfig(deserializedConf, defaultConfig)
 	if err != nil {
 		return nil, err
 	}
 
-	return []parsedConfig[C]{{"", defaultConfig}}, nil
+	return []parsedConfig[C]{{Key: "", Value: defaultConfig}}, nil
 }
 
-func parseMultipleConfigs[C any](yamlConfig string, deserializedConf *confmap.Conf, createDefaultConfig func() *C) ([]parsedConfig[C], error) {
+func unmarshalMultipleConfigs[C any](yamlConfig string, deserializedConf *confmap.Conf, createDefaultConfig func() *C) ([]parsedConfig[C], error) {
 	var configs []parsedConfig[C]
 	sortedKeys, err := sortedConfigKeys(yamlConfig)
 	if err != nil {
@@ -86,25 +129,28 @@ func parseMultipleConfigs[C any](yamlConfig string, deserializedConf *confmap.Co
 		}
 
 		defaultConfig := createDefaultConfig()
-		err = unmarshalValidConfig(sub, defaultConfig)
+		err = unmarshalComponentConfig(sub, defaultConfig)
 		if err != nil {
 			return nil, err
 		}
 
-		configs = append(configs, parsedConfig[C]{configKey, defaultConfig})
+		configs = append(configs, parsedConfig[C]{Key: configKey, Value: defaultConfig})
 	}
 
 	return configs, nil
 }
 
-func unmarshalValidConfig[C any](cfg *confmap.Conf, defaultConfig C) error {
+func unmarshalComponentConfig[C any](cfg *confmap.Conf, defaultConfig C) error {
 	subConfigMap := map[string]any{}
 	for k, v := range cfg.ToStringMap() {
 		subConfigMap[k] = escapeDollarSigns(v)
 	}
 
-	err := confmap.NewFromStringMap(subConfigMap).Unmarshal(&defaultConfig)
-	if err != nil {
+	return confmap.NewFromStringMap(subConfigMap).Unmarshal(&defaultConfig)
+}
+
+func unmarshalValidConfig[C any](cfg *confmap.Conf, defaultConfig C) error {
+	if err := unmarshalComponentConfig(cfg, defaultConfig); err != nil {
 		return err
 	}
 
@@ -116,6 +162,20 @@ func unmarshalValidConfig[C any](cfg *confmap.Conf, defaultConfig C) error {
 	return nil
 }
 
+// validateConfigs validates the component configurations, in order.
+func validateConfigs[C any](cfgs []parsedConfig[C]) error {
+	for _, cfg := range cfgs {
+		validator, ok := any(cfg.Value).(xconfmap.Validator)
+		if !ok {
+			continue
+		}
+		if err := validator.Validate(); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
 func sortedConfigKeys(yamlData string) ([]string, error) {
 	var root yaml.Node
 	if err := yaml.Unmarshal([]byte(yamlData), &root); err != nil {
@@ -130,11 +190,25 @@ func sortedConfigKeys(yamlData string) ([]string, error) {
 	}
 	var keys []string
 	for i := 0; i < len(mapNode.Content); i += 2 {
+		if mapNode.Content[i].Value == extensionsConfigKey {
+			continue
+		}
 		keys = append(keys, mapNode.Content[i].Value)
 	}
 	return keys, nil
 }
 
+// withoutExtensionsConfig returns the configuration without the extensions
+// section, which is parsed separately by parseExtensionsConfig.
+func withoutExtensionsConfig(conf *confmap.Conf) *confmap.Conf {
+	if !conf.IsSet(extensionsConfigKey) {
+		return conf
+	}
+	stringMap := conf.ToStringMap()
+	delete(stringMap, extensionsConfigKey)
+	return confmap.NewFromStringMap(stringMap)
+}
+
 func hasMultipleConfigs(id component.ID, conf *confmap.Conf) bool {
 	allKeys := conf.AllKeys()
 	if len(allKeys) == 0 {
@@ -148,6 +222,19 @@ func hasMultipleConfigs(id component.ID, conf *confmap.Conf) bool {
 	return true
 }
 
+// configHash returns the hash identifying a configuration of the executor with
+// the given ID, parsed with the given feature gates.
+func configHash(executorID, yamlConfig string, featureGates map[string]bool) string {
+	hash := sha256.New()
+	hash.Write([]byte(executorID))
+	hash.Write([]byte{0})
+	hash.Write([]byte(yamlConfig))
+	for _, id := range slices.Sorted(maps.Keys(featureGates)) {
+		_, _ = fmt.Fprintf(hash, "\x00%s=%t", id, featureGates[id])
+	}
+	return hex.EncodeToString(hash.Sum(nil))
+}
+
 func escapeDollarSigns(val any) any {
 	switch v := val.(type) {
 	case string:
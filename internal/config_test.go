@@ -126,6 +126,27 @@ func Test_parseConfig_multipleConfig(t *testing.T) {
 	}
 }
 
+func Test_parseConfig_withExtensions(t *testing.T) {
+	newDefaultConfig := func() *transformprocessor.Config {
+		return transformprocessor.NewFactory().CreateDefaultConfig().(*transformprocessor.Config)
+	}
+	id := component.NewID(transformprocessor.NewFactory().Type())
+
+	singleConfig := "extensions:\n  memory_storage:\nerror_mode: ignore\nlog_statements:\n  - set(log.attributes[\"a\"], 1)"
+	allConfigs, err := parseConfig[transformprocessor.Config](id, singleConfig, newDefaultConfig)
+	require.NoError(t, err)
+	require.Len(t, allConfigs, 1)
+	assert.Empty(t, allConfigs[0].Key)
+	assert.Len(t, allConfigs[0].Value.LogStatements, 1)
+
+	multipleConfigs := "extensions:\n  memory_storage:\ntransform/a:\n  log_statements:\n    - set(log.attributes[\"a\"], 1)\ntransform/b:\n  log_statements:\n    - set(log.attributes[\"b\"], 1)"
+	allConfigs, err = parseConfig[transformprocessor.Config](id, multipleConfigs, newDefaultConfig)
+	require.NoError(t, err)
+	require.Len(t, allConfigs, 2)
+	assert.Equal(t, "transform/a", allConfigs[0].Key)
+	assert.Equal(t, "transform/b", allConfigs[1].Key)
+}
+
 func Test_parseConfig_invalidConfig(t *testing.T) {
 	_, err := parseConfig[transformprocessor.Config](
 		component.NewID(transformprocessor.NewFactory().Type()),
@@ -151,3 +172,15 @@ func (m *mockConfig) Validate() error {
 	}
 	return errInvalidMockConfig
 }
+
+func Test_configHash(t *testing.T) {
+	hash := configHash("transform_processor", "transform:", nil)
+	assert.Equal(t, hash, configHash("transform_processor", "transform:", map[string]bool{}))
+	assert.NotEqual(t, hash, configHash("filter_processor", "transform:", nil))
+	assert.NotEqual(t, hash, configHash("transform_processor", "transform: {}", nil))
+	assert.NotEqual(t, hash, configHash("transform_processor", "transform:", map[string]bool{"gate": true}))
+	assert.NotEqual(t,
+		configHash("transform_processor", "transform:", map[string]bool{"gate": true}),
+		configHash("transform_processor", "transform:", map[string]bool{"gate": false}),
+	)
+}
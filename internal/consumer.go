@@ -22,194 +22,608 @@ package internal
 import (
 	"context"
 	"errors"
+	"time"
 
 	"go.opentelemetry.io/collector/consumer/xconsumer"
 	"go.opentelemetry.io/collector/pdata/pprofile"
 	"go.opentelemetry.io/collector/processor/xprocessor"
 
 	"go.opentelemetry.io/collector/component"
-	"go.opentelemetry.io/collector/component/componenttest"
 	"go.opentelemetry.io/collector/consumer"
 	"go.opentelemetry.io/collector/pdata/plog"
 	"go.opentelemetry.io/collector/pdata/pmetric"
 	"go.opentelemetry.io/collector/pdata/ptrace"
 	"go.opentelemetry.io/collector/processor"
 	"go.uber.org/zap"
-	"go.uber.org/zap/zapcore"
 )
 
+const componentIDLogField = "otelcol.component.id"
+
+// Consumer runs a component with a given configuration. Implementations must be
+// safe for concurrent use, all the per-run state is held by the given execution.
 type Consumer[C any] interface {
-	Observable
 	// ComponentID returns the component.ID of the component.
 	ComponentID() component.ID
 	// ConsumeLogs processes the input logs and returns the transformed logs or an error.
-	ConsumeLogs(config *C, input plog.Logs) (plog.Logs, error)
+	ConsumeLogs(exec *execution, config parsedConfig[C], input plog.Logs) (plog.Logs, error)
 	// ConsumeMetrics processes the input metrics and returns the transformed metrics or an error.
-	ConsumeMetrics(config *C, input pmetric.Metrics) (pmetric.Metrics, error)
+	ConsumeMetrics(exec *execution, config parsedConfig[C], input pmetric.Metrics) (pmetric.Metrics, error)
 	// ConsumeTraces processes the input traces and returns the transformed traces or an error.
-	ConsumeTraces(config *C, input ptrace.Traces) (ptrace.Traces, error)
+	ConsumeTraces(exec *execution, config parsedConfig[C], input ptrace.Traces) (ptrace.Traces, error)
 	// ConsumeProfiles processes the input profiles and returns the transformed profiles or an error.
-	ConsumeProfiles(config *C, input pprofile.Profiles) (pprofile.Profiles, error)
+	ConsumeProfiles(exec *execution, config parsedConfig[C], input pprofile.Profiles) (pprofile.Profiles, error)
 	// CreateDefaultConfig returns the default configuration for the given component.
 	CreateDefaultConfig() *C
-	// TelemetrySettings returns the telemetry settings used by the component.
-	TelemetrySettings() component.TelemetrySettings
 }
 
 type processorConsumer[C any] struct {
-	id                component.ID
-	factory           processor.Factory
-	settings          processor.Settings
-	telemetrySettings component.TelemetrySettings
-	observedLogs      *ObservedLogs
+	id        component.ID
+	factory   processor.Factory
+	buildInfo component.BuildInfo
+	// fixedTimeFactory creates the processors for the executions with a fixed
+	// time, see WithFixedTime. Nil if the processor doesn't support it.
+	fixedTimeFactory processor.Factory
+	// processors holds the started processors kept across executions, see
+	// withReusableProcessors. Nil if they're not kept.
+	processors *lruCache[processorCacheKey, *cachedProcessor]
+}
+
+type processorConsumerOption[C any] func(*processorConsumer[C])
+
+// withFixedTimeFactory sets the factory used when the execution has a fixed
+// time. It must create the same processor as the default factory, but with its
+// OTTL Now() function replaced by the one honoring WithFixedTime.
+func withFixedTimeFactory[C any](factory processor.Factory) processorConsumerOption[C] {
+	return func(p *processorConsumer[C]) {
+		p.fixedTimeFactory = factory
+	}
+}
+
+// withReusableProcessors keeps the started processors across the executions
+// running the same configuration. It must only be set for the processors that
+// don't do anything when shutting down, such as flushing data or failing, as
+// the executions reusing them don't shut them down.
+func withReusableProcessors[C any]() processorConsumerOption[C] {
+	return func(p *processorConsumer[C]) {
+		p.processors = newLRUCache[processorCacheKey, *cachedProcessor](processorCacheSize)
+	}
 }
 
 func newProcessorConsumer[C any](
 	factory processor.Factory,
+	options ...processorConsumerOption[C],
 ) *processorConsumer[C] {
-	observedLogger, observedLogs := NewLogObserver(zap.DebugLevel, zap.NewDevelopmentEncoderConfig())
-	logger, _ := zap.NewDevelopmentConfig().Build(zap.WrapCore(func(z zapcore.Core) zapcore.Core {
-		return observedLogger
-	}))
-
-	telemetrySettings := componenttest.NewNopTelemetrySettings()
-	telemetrySettings.Logger = logger
-
 	componentID := component.MustNewIDWithName(factory.Type().String(), "ottl_playground")
 	buildInfo := component.NewDefaultBuildInfo()
 	buildInfo.Description = "OTTL Playground"
 	buildInfo.Version = CollectorContribProcessorsVersion
 	buildInfo.Command = "wasm"
 
-	settings := processor.Settings{
-		ID:                componentID,
+	consumer := &processorConsumer[C]{
+		id:        componentID,
+		factory:   factory,
+		buildInfo: buildInfo,
+	}
+	for _, opt := range options {
+		opt(consumer)
+	}
+	return consumer
+}
+
+// factoryFor returns the factory creating the processors for the given execution.
+func (p processorConsumer[C]) factoryFor(exec *execution) processor.Factory {
+	if p.fixedTimeFactory != nil && exec.hasFixedTime() {
+		return p.fixedTimeFactory
+	}
+	return p.factory
+}
+
+// settings returns the processor.Settings for running the configuration with
+// the given key. Like in the collector, the component logger is annotated with
+// the component ID, which is the configuration key when there are multiple.
+func (p processorConsumer[C]) settings(telemetrySettings component.TelemetrySettings, configKey string) processor.Settings {
+	id := p.id
+	if configKey != "" {
+		var keyID component.ID
+		if err := keyID.UnmarshalText([]byte(configKey)); err == nil {
+			id = keyID
+		}
+	}
+
+	telemetrySettings.Logger = telemetrySettings.Logger.With(zap.String(componentIDLogField, id.String()))
+
+	return processor.Settings{
+		ID:                id,
 		TelemetrySettings: telemetrySettings,
-		BuildInfo:         buildInfo,
+		BuildInfo:         p.buildInfo,
 	}
+}
 
-	return &processorConsumer[C]{
-		id:                componentID,
-		factory:           factory,
-		telemetrySettings: telemetrySettings,
-		settings:          settings,
-		observedLogs:      observedLogs,
+// shutdown stops the processor, like the collector does once it's done with it.
+// Processors may still emit data while shutting down, and errors are reported
+// in the Result instead of failing the execution.
+func (p processorConsumer[C]) shutdown(exec *execution, settings processor.Settings, proc component.Component) {
+	if err := proc.Shutdown(context.Background()); err != nil {
+		settings.Logger.Warn("[playground] Failed to shutdown component", zap.Error(err))
+		exec.addShutdownError(settings.ID, err)
 	}
 }
 
-func (p processorConsumer[C]) ConsumeLogs(config *C, input plog.Logs) (plog.Logs, error) {
-	transformedLogs := plog.NewLogs()
-	logsConsumer, _ := consumer.NewLogs(func(_ context.Context, ld plog.Logs) error {
-		transformedLogs = ld
-		return nil
-	})
+// createProcessorFunc creates a processor with the given settings, passing the
+// data it emits to next.
+type createProcessorFunc func(settings processor.Settings, next func(any)) (component.Component, error)
+
+// acquire returns the started processor running the configuration, passing the
+// data it emits to emit, and the function releasing it once the execution is
+// done with it. When the processors are reusable, and the configuration was
+// parsed by an executor, and so has a hash, the processor is kept started and
+// reused by the next executions running the same configuration, unless they use
+// extensions, which are owned by each execution's host. Otherwise, it's shut
+// down once released.
+func (p processorConsumer[C]) acquire(exec *execution, signal string, config parsedConfig[C], emit func(any), create createProcessorFunc) (component.Component, func(), error) {
+	if p.processors == nil || config.hash == "" || len(exec.host.extensions) > 0 {
+		return p.start(exec, config, emit, create)
+	}
 
-	logsProcessor, err := p.factory.CreateLogs(context.Background(), p.settings, config, logsConsumer)
+	key := processorCacheKey{
+		signal:     signal,
+		configHash: config.hash,
+		configKey:  config.Key,
+		fixedTime:  exec.hasFixedTime(),
+	}
+	if cached, ok := p.processors.get(key); ok {
+		if cached.acquire(exec, emit) {
+			exec.recordCacheLookup(true)
+			return cached.processor, func() { cached.release(exec) }, nil
+		}
+		// The cached processor is used by a concurrent execution.
+		exec.recordCacheLookup(false)
+		return p.start(exec, config, emit, create)
+	}
+
+	exec.recordCacheLookup(false)
+	cached := newCachedProcessor()
+	cached.acquire(exec, emit)
+	settings := p.settings(cached.telemetrySettings, config.Key)
+	proc, err := createAndStart(exec, settings, newPlaygroundHost(), cached.emit, create)
+	cached.processor = proc
 	if err != nil {
-		return plog.Logs{}, err
+		cached.release(exec)
+		if shutdownErr := cached.close(); shutdownErr != nil {
+			exec.TelemetrySettings().Logger.Warn("[playground] Failed to shutdown component", zap.Error(shutdownErr))
+			exec.addShutdownError(settings.ID, shutdownErr)
+		}
+		return nil, nil, err
+	}
+	// The evicted processors belong to other configurations, their shutdown
+	// errors are only logged.
+	for _, evicted := range p.processors.add(key, cached) {
+		if shutdownErr := evicted.close(); shutdownErr != nil {
+			exec.TelemetrySettings().Logger.Warn("[playground] Failed to shutdown evicted component", zap.Error(shutdownErr))
+		}
 	}
+	return proc, func() { cached.release(exec) }, nil
+}
 
-	err = logsProcessor.Start(context.Background(), componenttest.NewNopHost())
+// start creates and starts a processor used by the given execution only, which
+// shuts it down when releasing it.
+func (p processorConsumer[C]) start(exec *execution, config parsedConfig[C], emit func(any), create createProcessorFunc) (component.Component, func(), error) {
+	settings := p.settings(exec.TelemetrySettings(), config.Key)
+	proc, err := createAndStart(exec, settings, exec.Host(), emit, create)
 	if err != nil {
-		return plog.Logs{}, err
+		if proc != nil {
+			p.shutdown(exec, settings, proc)
+		}
+		return nil, nil, err
 	}
+	return proc, func() { p.shutdown(exec, settings, proc) }, nil
+}
 
-	err = logsProcessor.ConsumeLogs(context.Background(), input)
+// createAndStart creates and starts a processor, recording the time spent in
+// each phase on the execution. If it fails to start, the processor is returned
+// along with the error, so it can be shut down.
+func createAndStart(exec *execution, settings processor.Settings, host component.Host, emit func(any), create createProcessorFunc) (component.Component, error) {
+	began := time.Now()
+	proc, err := create(settings, emit)
+	exec.timePhase(phaseCreate, began)
 	if err != nil {
-		return plog.Logs{}, err
+		return nil, err
 	}
 
-	return transformedLogs, nil
+	began = time.Now()
+	err = proc.Start(context.Background(), host)
+	exec.timePhase(phaseStart, began)
+	return proc, err
 }
 
-func (p processorConsumer[C]) ConsumeMetrics(config *C, input pmetric.Metrics) (pmetric.Metrics, error) {
-	transformedMetrics := pmetric.NewMetrics()
-	metricsConsumer, _ := consumer.NewMetrics(func(_ context.Context, ld pmetric.Metrics) error {
-		transformedMetrics = ld
-		return nil
-	})
+// resourceBatches tracks the batches emitted while consuming the resources of
+// an execution one at a time.
+type resourceBatches struct {
+	exec  *execution
+	split bool
+}
 
-	metricsProcessor, err := p.factory.CreateMetrics(context.Background(), p.settings, config, metricsConsumer)
-	if err != nil {
-		return pmetric.Metrics{}, err
+func newResourceBatches(exec *execution) *resourceBatches {
+	return &resourceBatches{exec: exec}
+}
+
+// consume calls the given function consuming a resource, recording whether the
+// component emitted it in several batches.
+func (b *resourceBatches) consume(consume func() error) error {
+	recorded := len(b.exec.batches)
+	err := consume()
+	b.split = b.split || len(b.exec.batches)-recorded > 1
+	return err
+}
+
+// merge reports whether the batches emitted so far must be reported as a
+// single one, in which case they are dropped for the caller to record the
+// merged batch. The input is only split to be cancellable, so unless the
+// component split the resources further, the data it emitted is reported as if
+// they were consumed together.
+func (b *resourceBatches) merge() bool {
+	if b.split || len(b.exec.batches) < 2 {
+		return false
+	}
+	b.exec.takeBatches()
+	return true
+}
+
+func (p processorConsumer[C]) ConsumeLogs(exec *execution, config parsedConfig[C], input plog.Logs) (plog.Logs, error) {
+	// Only the batches emitted by the last component run are reported.
+	exec.takeBatches()
+	transformedLogs := plog.NewLogs()
+	emit := func(data any) {
+		ld := data.(plog.Logs)
+		batch := plog.NewLogs()
+		ld.CopyTo(batch)
+		exec.recordBatch(batch)
+		for _, resource := range ld.ResourceLogs().All() {
+			resource.CopyTo(transformedLogs.ResourceLogs().AppendEmpty())
+		}
 	}
 
-	err = metricsProcessor.Start(context.Background(), componenttest.NewNopHost())
+	proc, release, err := p.acquire(exec, "logs", config, emit, p.createLogs(exec, config))
 	if err != nil {
-		return pmetric.Metrics{}, err
+		return plog.Logs{}, err
+	}
+	logsProcessor := proc.(processor.Logs)
+
+	if !exec.cancellable() {
+		began := time.Now()
+		err = logsProcessor.ConsumeLogs(exec.Context(), input)
+		exec.timePhase(phaseConsume, began)
+		release()
+		return transformedLogs, err
 	}
 
-	err = metricsProcessor.ConsumeMetrics(context.Background(), input)
+	// Resources are consumed one at a time, so the execution can be cancelled in
+	// between. On cancellation, the resources not processed yet are returned as is.
+	resources := input.ResourceLogs()
+	batches := newResourceBatches(exec)
+	i, cancelled := 0, false
+	for ; i < resources.Len() && err == nil; i++ {
+		if err = exec.checkCancelled(); err != nil {
+			cancelled = true
+			break
+		}
+
+		batch := plog.NewLogs()
+		resources.At(i).CopyTo(batch.ResourceLogs().AppendEmpty())
+		began := time.Now()
+		err = batches.consume(func() error {
+			return logsProcessor.ConsumeLogs(exec.Context(), batch)
+		})
+		exec.timePhase(phaseConsume, began)
+	}
+	if batches.merge() {
+		merged := plog.NewLogs()
+		transformedLogs.CopyTo(merged)
+		exec.recordBatch(merged)
+	}
+	if cancelled {
+		for ; i < resources.Len(); i++ {
+			resources.At(i).CopyTo(transformedLogs.ResourceLogs().AppendEmpty())
+		}
+	}
+
+	release()
+	return transformedLogs, err
+}
+
+func (p processorConsumer[C]) ConsumeMetrics(exec *execution, config parsedConfig[C], input pmetric.Metrics) (pmetric.Metrics, error) {
+	// Only the batches emitted by the last component run are reported.
+	exec.takeBatches()
+	transformedMetrics := pmetric.NewMetrics()
+	emit := func(data any) {
+		ld := data.(pmetric.Metrics)
+		batch := pmetric.NewMetrics()
+		ld.CopyTo(batch)
+		exec.recordBatch(batch)
+		for _, resource := range ld.ResourceMetrics().All() {
+			resource.CopyTo(transformedMetrics.ResourceMetrics().AppendEmpty())
+		}
+	}
+
+	proc, release, err := p.acquire(exec, "metrics", config, emit, p.createMetrics(exec, config))
 	if err != nil {
 		return pmetric.Metrics{}, err
 	}
+	metricsProcessor := proc.(processor.Metrics)
+
+	if !exec.cancellable() {
+		began := time.Now()
+		err = metricsProcessor.ConsumeMetrics(exec.Context(), input)
+		exec.timePhase(phaseConsume, began)
+		release()
+		return transformedMetrics, err
+	}
+
+	// Resources are consumed one at a time, so the execution can be cancelled in
+	// between. On cancellation, the resources not processed yet are returned as is.
+	resources := input.ResourceMetrics()
+	batches := newResourceBatches(exec)
+	i, cancelled := 0, false
+	for ; i < resources.Len() && err == nil; i++ {
+		if err = exec.checkCancelled(); err != nil {
+			cancelled = true
+			break
+		}
+
+		batch := pmetric.NewMetrics()
+		resources.At(i).CopyTo(batch.ResourceMetrics().AppendEmpty())
+		began := time.Now()
+		err = batches.consume(func() error {
+			return metricsProcessor.ConsumeMetrics(exec.Context(), batch)
+		})
+		exec.timePhase(phaseConsume, began)
+	}
+	if batches.merge() {
+		merged := pmetric.NewMetrics()
+		transformedMetrics.CopyTo(merged)
+		exec.recordBatch(merged)
+	}
+	if cancelled {
+		for ; i < resources.Len(); i++ {
+			resources.At(i).CopyTo(transformedMetrics.ResourceMetrics().AppendEmpty())
+		}
+	}
 
-	return transformedMetrics, nil
+	release()
+	return transformedMetrics, err
 }
 
-func (p processorConsumer[C]) ConsumeTraces(config *C, input ptrace.Traces) (ptrace.Traces, error) {
+func (p processorConsumer[C]) ConsumeTraces(exec *execution, config parsedConfig[C], input ptrace.Traces) (ptrace.Traces, error) {
+	// Only the batches emitted by the last component run are reported.
+	exec.takeBatches()
 	transformedTraces := ptrace.NewTraces()
-	tracesConsumer, _ := consumer.NewTraces(func(_ context.Context, ld ptrace.Traces) error {
-		transformedTraces = ld
-		return nil
-	})
-
-	tracesProcessor, err := p.factory.CreateTraces(context.Background(), p.settings, config, tracesConsumer)
-	if err != nil {
-		return ptrace.Traces{}, err
+	emit := func(data any) {
+		ld := data.(ptrace.Traces)
+		batch := ptrace.NewTraces()
+		ld.CopyTo(batch)
+		exec.recordBatch(batch)
+		for _, resource := range ld.ResourceSpans().All() {
+			resource.CopyTo(transformedTraces.ResourceSpans().AppendEmpty())
+		}
 	}
 
-	err = tracesProcessor.Start(context.Background(), componenttest.NewNopHost())
+	proc, release, err := p.acquire(exec, "traces", config, emit, p.createTraces(exec, config))
 	if err != nil {
 		return ptrace.Traces{}, err
 	}
+	tracesProcessor := proc.(processor.Traces)
+
+	if !exec.cancellable() {
+		began := time.Now()
+		err = tracesProcessor.ConsumeTraces(exec.Context(), input)
+		exec.timePhase(phaseConsume, began)
+		release()
+		return transformedTraces, err
+	}
 
-	err = tracesProcessor.ConsumeTraces(context.Background(), input)
-	if err != nil {
-		return ptrace.Traces{}, err
+	// Resources are consumed one at a time, so the execution can be cancelled in
+	// between. On cancellation, the resources not processed yet are returned as is.
+	resources := input.ResourceSpans()
+	batches := newResourceBatches(exec)
+	i, cancelled := 0, false
+	for ; i < resources.Len() && err == nil; i++ {
+		if err = exec.checkCancelled(); err != nil {
+			cancelled = true
+			break
+		}
+
+		batch := ptrace.NewTraces()
+		resources.At(i).CopyTo(batch.ResourceSpans().AppendEmpty())
+		began := time.Now()
+		err = batches.consume(func() error {
+			return tracesProcessor.ConsumeTraces(exec.Context(), batch)
+		})
+		exec.timePhase(phaseConsume, began)
+	}
+	if batches.merge() {
+		merged := ptrace.NewTraces()
+		transformedTraces.CopyTo(merged)
+		exec.recordBatch(merged)
+	}
+	if cancelled {
+		for ; i < resources.Len(); i++ {
+			resources.At(i).CopyTo(transformedTraces.ResourceSpans().AppendEmpty())
+		}
 	}
 
-	return transformedTraces, nil
+	release()
+	return transformedTraces, err
 }
 
-func (p processorConsumer[C]) ConsumeProfiles(config *C, input pprofile.Profiles) (pprofile.Profiles, error) {
-	factory, ok := p.factory.(xprocessor.Factory)
+func (p processorConsumer[C]) ConsumeProfiles(exec *execution, config parsedConfig[C], input pprofile.Profiles) (pprofile.Profiles, error) {
+	factory, ok := p.factoryFor(exec).(xprocessor.Factory)
 	if !ok {
 		return pprofile.Profiles{}, errors.New("profiles are not supported by this OTel Collector version or component")
 	}
 
+	// Each batch has its own dictionary, so they can't be merged like the other
+	// signals, and the last one is returned.
+	// Only the batches emitted by the last component run are reported.
+	exec.takeBatches()
 	transformedProfiles := pprofile.NewProfiles()
-	profilesConsumer, _ := xconsumer.NewProfiles(func(_ context.Context, ld pprofile.Profiles) error {
+	emit := func(data any) {
+		ld := data.(pprofile.Profiles)
+		batch := pprofile.NewProfiles()
+		ld.CopyTo(batch)
+		exec.recordBatch(batch)
 		transformedProfiles = ld
-		return nil
-	})
+	}
 
-	profilesProcessor, err := factory.CreateProfiles(context.Background(), p.settings, config, profilesConsumer)
+	proc, release, err := p.acquire(exec, "profiles", config, emit, p.createProfiles(factory, config))
 	if err != nil {
 		return pprofile.Profiles{}, err
 	}
+	defer release()
+	profilesProcessor := proc.(xprocessor.Profiles)
 
-	err = profilesProcessor.Start(context.Background(), componenttest.NewNopHost())
-	if err != nil {
-		return pprofile.Profiles{}, err
+	// Resources share the profiles dictionary, so they can't be consumed one at
+	// a time like the other signals.
+	if err = exec.checkCancelled(); err != nil {
+		return input, err
+	}
+
+	began := time.Now()
+	err = profilesProcessor.ConsumeProfiles(exec.Context(), input)
+	exec.timePhase(phaseConsume, began)
+	return transformedProfiles, err
+}
+
+// createLogs returns the function creating the logs processor running the
+// configuration.
+func (p processorConsumer[C]) createLogs(exec *execution, config parsedConfig[C]) createProcessorFunc {
+	return func(settings processor.Settings, next func(any)) (component.Component, error) {
+		logsConsumer, _ := consumer.NewLogs(func(_ context.Context, ld plog.Logs) error {
+			next(ld)
+			return nil
+		})
+		return p.factoryFor(exec).CreateLogs(context.Background(), settings, config.Value, logsConsumer)
+	}
+}
+
+// createMetrics returns the function creating the metrics processor running
+// the configuration.
+func (p processorConsumer[C]) createMetrics(exec *execution, config parsedConfig[C]) createProcessorFunc {
+	return func(settings processor.Settings, next func(any)) (component.Component, error) {
+		metricsConsumer, _ := consumer.NewMetrics(func(_ context.Context, ld pmetric.Metrics) error {
+			next(ld)
+			return nil
+		})
+		return p.factoryFor(exec).CreateMetrics(context.Background(), settings, config.Value, metricsConsumer)
+	}
+}
+
+// createTraces returns the function creating the traces processor running the
+// configuration.
+func (p processorConsumer[C]) createTraces(exec *execution, config parsedConfig[C]) createProcessorFunc {
+	return func(settings processor.Settings, next func(any)) (component.Component, error) {
+		tracesConsumer, _ := consumer.NewTraces(func(_ context.Context, ld ptrace.Traces) error {
+			next(ld)
+			return nil
+		})
+		return p.factoryFor(exec).CreateTraces(context.Background(), settings, config.Value, tracesConsumer)
+	}
+}
+
+// createProfiles returns the function creating the profiles processor running
+// the configuration.
+func (p processorConsumer[C]) createProfiles(factory xprocessor.Factory, config parsedConfig[C]) createProcessorFunc {
+	return func(settings processor.Settings, next func(any)) (component.Component, error) {
+		profilesConsumer, _ := xconsumer.NewProfiles(func(_ context.Context, ld pprofile.Profiles) error {
+			next(ld)
+			return nil
+		})
+		return factory.CreateProfiles(context.Background(), settings, config.Value, profilesConsumer)
 	}
+}
 
-	err = profilesProcessor.ConsumeProfiles(context.Background(), input)
+// startLogs starts a logs processor running the configuration, used by the
+// execution only. It returns the function passing logs to the processor, which
+// returns the logs it emitted, and the function shutting it down. Unlike
+// ConsumeLogs, the emitted batches aren't recorded, nor the time spent.
+func (p processorConsumer[C]) startLogs(exec *execution, config parsedConfig[C]) (func(plog.Logs) (plog.Logs, error), func(), error) {
+	var emitted plog.Logs
+	proc, release, err := p.start(exec, config, func(data any) {
+		data.(plog.Logs).ResourceLogs().MoveAndAppendTo(emitted.ResourceLogs())
+	}, p.createLogs(exec, config))
 	if err != nil {
-		return pprofile.Profiles{}, err
+		return nil, nil, err
 	}
+	logsProcessor := proc.(processor.Logs)
+	return func(ld plog.Logs) (plog.Logs, error) {
+		emitted = plog.NewLogs()
+		err := logsProcessor.ConsumeLogs(exec.Context(), ld)
+		return emitted, err
+	}, release, nil
+}
 
-	return transformedProfiles, nil
+// startMetrics is like startLogs, for metrics.
+func (p processorConsumer[C]) startMetrics(exec *execution, config parsedConfig[C]) (func(pmetric.Metrics) (pmetric.Metrics, error), func(), error) {
+	var emitted pmetric.Metrics
+	proc, release, err := p.start(exec, config, func(data any) {
+		data.(pmetric.Metrics).ResourceMetrics().MoveAndAppendTo(emitted.ResourceMetrics())
+	}, p.createMetrics(exec, config))
+	if err != nil {
+		return nil, nil, err
+	}
+	metricsProcessor := proc.(processor.Metrics)
+	return func(md pmetric.Metrics) (pmetric.Metrics, error) {
+		emitted = pmetric.NewMetrics()
+		err := metricsProcessor.ConsumeMetrics(exec.Context(), md)
+		return emitted, err
+	}, release, nil
 }
 
-func (p processorConsumer[C]) ObservedLogs() *ObservedLogs {
-	return p.observedLogs
+// startTraces is like startLogs, for traces.
+func (p processorConsumer[C]) startTraces(exec *execution, config parsedConfig[C]) (func(ptrace.Traces) (ptrace.Traces, error), func(), error) {
+	var emitted ptrace.Traces
+	proc, release, err := p.start(exec, config, func(data any) {
+		data.(ptrace.Traces).ResourceSpans().MoveAndAppendTo(emitted.ResourceSpans())
+	}, p.createTraces(exec, config))
+	if err != nil {
+		return nil, nil, err
+	}
+	tracesProcessor := proc.(processor.Traces)
+	return func(td ptrace.Traces) (ptrace.Traces, error) {
+		emitted = ptrace.NewTraces()
+		err := tracesProcessor.ConsumeTraces(exec.Context(), td)
+		return emitted, err
+	}, release, nil
 }
 
-func (p processorConsumer[C]) TelemetrySettings() component.TelemetrySettings {
-	return p.telemetrySettings
+// startProfiles is like startLogs, for profiles. Each batch has its own
+// dictionary, so the last one emitted is returned.
+func (p processorConsumer[C]) startProfiles(exec *execution, config parsedConfig[C]) (func(pprofile.Profiles) (pprofile.Profiles, error), func(), error) {
+	factory, ok := p.factoryFor(exec).(xprocessor.Factory)
+	if !ok {
+		return nil, nil, errors.New("profiles are not supported by this OTel Collector version or component")
+	}
+	var emitted pprofile.Profiles
+	proc, release, err := p.start(exec, config, func(data any) {
+		emitted = data.(pprofile.Profiles)
+	}, p.createProfiles(factory, config))
+	if err != nil {
+		return nil, nil, err
+	}
+	profilesProcessor := proc.(xprocessor.Profiles)
+	return func(pd pprofile.Profiles) (pprofile.Profiles, error) {
+		emitted = pprofile.NewProfiles()
+		err := profilesProcessor.ConsumeProfiles(exec.Context(), pd)
+		return emitted, err
+	}, release, nil
 }
 
 func (p processorConsumer[C]) CreateDefaultConfig() *C {
+	// Some processors, like the filterprocessor, read the OTTL functions from
+	// their configuration instead of the factory, so it must hold the functions
+	// honoring WithFixedTime. Without a fixed time, they behave as the default ones.
+	if p.fixedTimeFactory != nil {
+		return p.fixedTimeFactory.CreateDefaultConfig().(*C)
+	}
 	return p.factory.CreateDefaultConfig().(*C)
 }
 
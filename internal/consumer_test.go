@@ -20,14 +20,22 @@
 package internal
 
 import (
+	"context"
+	"errors"
 	"testing"
 
 	"github.com/open-telemetry/opentelemetry-collector-contrib/processor/transformprocessor"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/collector/client"
+	"go.opentelemetry.io/collector/component"
+	"go.opentelemetry.io/collector/consumer"
 	"go.opentelemetry.io/collector/pdata/plog"
 	"go.opentelemetry.io/collector/pdata/pmetric"
 	"go.opentelemetry.io/collector/pdata/ptrace"
+	"go.opentelemetry.io/collector/processor"
+	"go.opentelemetry.io/collector/processor/processorhelper"
+	"go.uber.org/goleak"
 )
 
 func Test_newProcessorConsumer(t *testing.T) {
@@ -36,8 +44,6 @@ func Test_newProcessorConsumer(t *testing.T) {
 
 	require.NotNil(t, consumer)
 	assert.Equal(t, "transform/ottl_playground", consumer.ComponentID().String())
-	assert.NotNil(t, consumer.TelemetrySettings())
-	assert.NotNil(t, consumer.observedLogs)
 	assert.Equal(t, factory, consumer.factory)
 }
 
@@ -67,7 +73,7 @@ func Test_processorConsumer_ConsumeLogs(t *testing.T) {
 	// Create a basic config
 	config := &transformprocessor.Config{}
 
-	outputLogs, err := consumer.ConsumeLogs(config, inputLogs)
+	outputLogs, err := consumer.ConsumeLogs(newExecution(), parsedConfig[transformprocessor.Config]{Value: config}, inputLogs)
 	require.NoError(t, err)
 	require.NotNil(t, outputLogs)
 	assert.Equal(t, inputLogs.LogRecordCount(), outputLogs.LogRecordCount())
@@ -87,7 +93,7 @@ func Test_processorConsumer_ConsumeMetrics(t *testing.T) {
 	// Create a basic config
 	config := &transformprocessor.Config{}
 
-	outputMetrics, err := consumer.ConsumeMetrics(config, inputMetrics)
+	outputMetrics, err := consumer.ConsumeMetrics(newExecution(), parsedConfig[transformprocessor.Config]{Value: config}, inputMetrics)
 	require.NoError(t, err)
 	require.NotNil(t, outputMetrics)
 	assert.Equal(t, inputMetrics.MetricCount(), outputMetrics.MetricCount())
@@ -109,31 +115,153 @@ func Test_processorConsumer_ConsumeTraces(t *testing.T) {
 	// Create a basic config
 	config := &transformprocessor.Config{}
 
-	outputTraces, err := consumer.ConsumeTraces(config, inputTraces)
+	outputTraces, err := consumer.ConsumeTraces(newExecution(), parsedConfig[transformprocessor.Config]{Value: config}, inputTraces)
 	require.NoError(t, err)
 	require.NotNil(t, outputTraces)
 	assert.Equal(t, inputTraces.SpanCount(), outputTraces.SpanCount())
 }
 
-func Test_processorConsumer_ObservedLogs(t *testing.T) {
-	factory := transformprocessor.NewFactory()
-	consumer := newProcessorConsumer[transformprocessor.Config](factory)
+func Test_processorConsumer_settings(t *testing.T) {
+	consumer := newProcessorConsumer[transformprocessor.Config](transformprocessor.NewFactory())
+	exec := newExecution()
+
+	settings := consumer.settings(exec.TelemetrySettings(), "")
+	assert.Equal(t, "transform/ottl_playground", settings.ID.String())
 
-	observedLogs := consumer.ObservedLogs()
-	require.NotNil(t, observedLogs)
+	settings = consumer.settings(exec.TelemetrySettings(), "transform/custom")
+	assert.Equal(t, "transform/custom", settings.ID.String())
 
-	// Test that it's the same instance
-	assert.Equal(t, consumer.observedLogs, observedLogs)
+	settings.Logger.Info("component log")
+	entries := exec.ObservedLogs().TakeAllEntries()
+	require.Len(t, entries, 1)
+	assert.Equal(t, "transform/custom", entries[0].Fields[componentIDLogField])
 }
 
-func Test_processorConsumer_TelemetrySettings(t *testing.T) {
-	factory := transformprocessor.NewFactory()
-	consumer := newProcessorConsumer[transformprocessor.Config](factory)
+type testProcessorConfig struct{}
+
+func Test_processorConsumer_ConsumeLogsClientInfo(t *testing.T) {
+	var info client.Info
+	factory := processor.NewFactory(
+		component.MustNewType("client_info"),
+		func() component.Config { return &testProcessorConfig{} },
+		processor.WithLogs(func(ctx context.Context, set processor.Settings, cfg component.Config, next consumer.Logs) (processor.Logs, error) {
+			return processorhelper.NewLogs(ctx, set, cfg, next, func(ctx context.Context, ld plog.Logs) (plog.Logs, error) {
+				info = client.FromContext(ctx)
+				return ld, nil
+			})
+		}, component.StabilityLevelDevelopment),
+	)
+	consumer := newProcessorConsumer[testProcessorConfig](factory)
+	exec := newExecution(
+		WithClientMetadata(map[string][]string{"x-tenant": {"tenant-a"}}),
+		WithAuthAttributes(map[string]any{"subject": "user"}),
+	)
+
+	input := plog.NewLogs()
+	input.ResourceLogs().AppendEmpty()
+
+	_, err := consumer.ConsumeLogs(exec, parsedConfig[testProcessorConfig]{Value: &testProcessorConfig{}}, input)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"tenant-a"}, info.Metadata.Get("x-tenant"))
+	require.NotNil(t, info.Auth)
+	assert.Equal(t, "user", info.Auth.GetAttribute("subject"))
+}
+
+func Test_processorConsumer_ConsumeLogsCancelled(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	factory := processor.NewFactory(
+		component.MustNewType("cancelling"),
+		func() component.Config { return &testProcessorConfig{} },
+		processor.WithLogs(func(ctx context.Context, set processor.Settings, cfg component.Config, next consumer.Logs) (processor.Logs, error) {
+			return processorhelper.NewLogs(ctx, set, cfg, next, func(_ context.Context, ld plog.Logs) (plog.Logs, error) {
+				ld.ResourceLogs().At(0).Resource().Attributes().PutBool("processed", true)
+				cancel()
+				return ld, nil
+			})
+		}, component.StabilityLevelDevelopment),
+	)
+	consumer := newProcessorConsumer[testProcessorConfig](factory)
+	exec := newExecution(WithContext(ctx))
+	defer exec.close()
+
+	input := plog.NewLogs()
+	input.ResourceLogs().AppendEmpty().Resource().Attributes().PutStr("name", "first")
+	input.ResourceLogs().AppendEmpty().Resource().Attributes().PutStr("name", "second")
+
+	output, err := consumer.ConsumeLogs(exec, parsedConfig[testProcessorConfig]{Value: &testProcessorConfig{}}, input)
+	require.ErrorIs(t, err, context.Canceled)
+	require.Equal(t, 2, output.ResourceLogs().Len())
+
+	_, processed := output.ResourceLogs().At(0).Resource().Attributes().Get("processed")
+	assert.True(t, processed)
+	name, _ := output.ResourceLogs().At(1).Resource().Attributes().Get("name")
+	assert.Equal(t, "second", name.Str())
+	_, processed = output.ResourceLogs().At(1).Resource().Attributes().Get("processed")
+	assert.False(t, processed)
+}
+
+func Test_processorConsumer_ConsumeLogsLifecycle(t *testing.T) {
+	defer goleak.VerifyNone(t)
+
+	var calls []string
+	var host component.Host
+	factory := processor.NewFactory(
+		component.MustNewType("lifecycle"),
+		func() component.Config { return &testProcessorConfig{} },
+		processor.WithLogs(func(ctx context.Context, set processor.Settings, cfg component.Config, next consumer.Logs) (processor.Logs, error) {
+			stop := make(chan struct{})
+			stopped := make(chan struct{})
+			return processorhelper.NewLogs(ctx, set, cfg, next,
+				func(_ context.Context, ld plog.Logs) (plog.Logs, error) {
+					calls = append(calls, "consume")
+					return ld, nil
+				},
+				processorhelper.WithStart(func(_ context.Context, h component.Host) error {
+					calls = append(calls, "start")
+					host = h
+					go func() {
+						defer close(stopped)
+						<-stop
+					}()
+					return nil
+				}),
+				processorhelper.WithShutdown(func(ctx context.Context) error {
+					calls = append(calls, "shutdown")
+					close(stop)
+					<-stopped
+
+					// Flushes pending data on shutdown
+					flushed := plog.NewLogs()
+					flushed.ResourceLogs().AppendEmpty().Resource().Attributes().PutBool("flushed", true)
+					if err := next.ConsumeLogs(ctx, flushed); err != nil {
+						return err
+					}
+					return errors.New("shutdown failure")
+				}),
+			)
+		}, component.StabilityLevelDevelopment),
+	)
+	consumer := newProcessorConsumer[testProcessorConfig](factory)
+	exec := newExecution()
+	defer exec.close()
+
+	input := plog.NewLogs()
+	input.ResourceLogs().AppendEmpty()
+
+	output, err := consumer.ConsumeLogs(exec, parsedConfig[testProcessorConfig]{Value: &testProcessorConfig{}}, input)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"start", "consume", "shutdown"}, calls)
+	assert.Equal(t, exec.Host(), host)
 
-	telemetrySettings := consumer.TelemetrySettings()
-	require.NotNil(t, telemetrySettings)
-	require.NotNil(t, telemetrySettings.Logger)
+	require.Equal(t, 2, output.ResourceLogs().Len())
+	_, flushed := output.ResourceLogs().At(1).Resource().Attributes().Get("flushed")
+	assert.True(t, flushed)
 
-	// Test that it's the same instance
-	assert.Equal(t, consumer.telemetrySettings, telemetrySettings)
+	shutdownErrors := exec.takeShutdownErrors()
+	require.Len(t, shutdownErrors, 1)
+	assert.Equal(t, "lifecycle/ottl_playground", shutdownErrors[0].ComponentID)
+	assert.Equal(t, "shutdown failure", shutdownErrors[0].Error)
+	assert.Empty(t, exec.takeShutdownErrors())
 }
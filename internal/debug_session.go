@@ -0,0 +1,89 @@
+/*
+ * Copyright Elasticsearch B.V. and/or licensed to Elasticsearch B.V. under one
+ * or more contributor license agreements. See the NOTICE file distributed with
+ * this work for additional information regarding copyright
+ * ownership. Elasticsearch B.V. licenses this file to you under
+ * the Apache License, Version 2.0 (the "License"); you may
+ * not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *	http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing,
+ * software distributed under the License is distributed on an
+ * "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+ * KIND, either express or implied.  See the License for the
+ * specific language governing permissions and limitations
+ * under the License.
+ */
+
+package internal
+
+import "errors"
+
+// Breakpoint pauses a debug session before the step of a statement, or of a
+// group condition. It either sets the Line of the statement in the
+// configuration, or identifies it by its ConfigKey, Group and Index, as
+// reported by the steps' DebugStep.
+type Breakpoint struct {
+	Line      int64  `json:"line,omitempty"`
+	ConfigKey string `json:"configKey,omitempty"`
+	Group     int    `json:"group,omitempty"`
+	Index     int    `json:"index,omitempty"`
+	Condition bool   `json:"condition,omitempty"`
+	// Selector is an OTTL condition selecting the records of the step's
+	// context. If set, the session only pauses if it matches any of the
+	// records the step applies to.
+	Selector string `json:"selector,omitempty"`
+}
+
+func (b Breakpoint) validate() error {
+	switch {
+	case b.Line < 0:
+		return errors.New("the line must be positive")
+	case b.Line > 0 && b.ConfigKey != "":
+		return errors.New("either a line or a config key must be set, not both")
+	case b.Line == 0 && b.ConfigKey == "":
+		return errors.New("either a line or a config key must be set")
+	case b.Group < 0 || b.Index < 0:
+		return errors.New("the group and index must not be negative")
+	}
+	return nil
+}
+
+// matches reports whether the breakpoint is set on the given step, whose
+// statement is at the given line.
+func (b Breakpoint) matches(step DebugStep, line int64) bool {
+	if b.Line > 0 {
+		return b.Line == line
+	}
+	return b.ConfigKey == step.ConfigKey && b.Group == step.Group && b.Index == step.Index && b.Condition == step.Condition
+}
+
+// DebugPause is the state of a debug session paused before a step.
+type DebugPause struct {
+	// Step is the step the session runs next.
+	Step DebugStep `json:"step"`
+	// Line is the line of the step's statement, or condition.
+	Line int64 `json:"line"`
+	// Breakpoint is the index of the breakpoint the session paused at, or -1
+	// if it paused after running a single step and no breakpoint is set on the
+	// next one.
+	Breakpoint int `json:"breakpoint"`
+	// Payload is the payload the step runs on.
+	Payload string `json:"payload"`
+	// Caches holds the cache of the records of the step's context, as left by
+	// the previous statements of its group.
+	Caches []RecordCache `json:"caches,omitempty"`
+	// Selected holds the positions of the records matched by the selector of
+	// the breakpoint, from the resource down.
+	Selected [][]int `json:"selected,omitempty"`
+}
+
+// RecordCache is the OTTL cache of a record.
+type RecordCache struct {
+	// Index is the position of the record, from the resource down.
+	Index []int `json:"index"`
+	// Cache is the content of the record's cache.
+	Cache map[string]any `json:"cache"`
+}
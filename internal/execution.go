@@ -0,0 +1,425 @@
+/*
+ * Copyright Elasticsearch B.V. and/or licensed to Elasticsearch B.V. under one
+ * or more contributor license agreements. See the NOTICE file distributed with
+ * this work for additional information regarding copyright
+ * ownership. Elasticsearch B.V. licenses this file to you under
+ * the Apache License, Version 2.0 (the "License"); you may
+ * not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *	http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing,
+ * software distributed under the License is distributed on an
+ * "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+ * KIND, either express or implied.  See the License for the
+ * specific language governing permissions and limitations
+ * under the License.
+ */
+
+package internal
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"time"
+
+	"go.opentelemetry.io/collector/client"
+	"go.opentelemetry.io/collector/component"
+	"go.opentelemetry.io/collector/component/componenttest"
+	"go.opentelemetry.io/collector/pdata/pmetric"
+	"go.opentelemetry.io/collector/pdata/ptrace"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+)
+
+// ExecutionOption configures a single execution.
+type ExecutionOption func(*executionSettings)
+
+type executionSettings struct {
+	logLevel       zapcore.Level
+	featureGates   map[string]bool
+	clientMetadata map[string][]string
+	authAttributes map[string]any
+	ctx            context.Context
+	timeout        time.Duration
+	fixedTime      time.Time
+	benchmark      benchmarkSettings
+}
+
+func newExecutionSettings(options ...ExecutionOption) executionSettings {
+	settings := executionSettings{
+		logLevel: zap.DebugLevel,
+	}
+	for _, opt := range options {
+		opt(&settings)
+	}
+	return settings
+}
+
+// WithLogLevel sets the minimum level of the logs observed by the execution.
+// Entries below that level are discarded and don't show up in the Result.
+func WithLogLevel(level zapcore.Level) ExecutionOption {
+	return func(settings *executionSettings) {
+		settings.logLevel = level
+	}
+}
+
+// WithClientMetadata sets the client metadata, such as the request headers,
+// that components can read from the context using [client.FromContext].
+func WithClientMetadata(metadata map[string][]string) ExecutionOption {
+	return func(settings *executionSettings) {
+		settings.clientMetadata = metadata
+	}
+}
+
+// WithAuthAttributes sets the attributes of the client's [client.AuthData],
+// as if they were set by an authenticator extension.
+func WithAuthAttributes(attributes map[string]any) ExecutionOption {
+	return func(settings *executionSettings) {
+		settings.authAttributes = attributes
+	}
+}
+
+// WithContext sets the parent context of the execution. Cancelling it stops
+// the execution at the next point it can be interrupted, such as between
+// resources or debug steps, and the Result is flagged as cancelled.
+func WithContext(ctx context.Context) ExecutionOption {
+	return func(settings *executionSettings) {
+		settings.ctx = ctx
+	}
+}
+
+// WithTimeout cancels the execution once the given duration has elapsed.
+// Like WithContext, it returns the partial Result flagged as cancelled.
+func WithTimeout(timeout time.Duration) ExecutionOption {
+	return func(settings *executionSettings) {
+		settings.timeout = timeout
+	}
+}
+
+// WithFixedTime pins the time returned by the OTTL Now() function, so the
+// statements using it produce the same output on every run. See
+// Metadata.FixedTimeContexts for the OTTL contexts it applies to.
+func WithFixedTime(t time.Time) ExecutionOption {
+	return func(settings *executionSettings) {
+		settings.fixedTime = t
+	}
+}
+
+// WithBenchmark runs the configuration again against the payload after the
+// execution, the given number of times, or until the given duration elapsed,
+// whichever comes first. A zero value disables the corresponding limit. Both
+// limits are bounded, to 100000 runs and 30 seconds. The statistics of these
+// runs are reported in the Result's Benchmark, see Benchmark. Profilers use these limits for each statement they measure, and
+// debuggers ignore this option.
+func WithBenchmark(runs int, duration time.Duration) ExecutionOption {
+	return func(settings *executionSettings) {
+		settings.benchmark = benchmarkSettings{runs: runs, duration: duration}
+	}
+}
+
+// execution holds the state owned by a single executor run. Executors are
+// long-lived and shared, so everything that must not leak between runs, such
+// as the log sink and the telemetry settings, lives here instead.
+type execution struct {
+	settings          executionSettings
+	ctx               context.Context
+	cancel            context.CancelFunc
+	telemetrySettings component.TelemetrySettings
+	telemetry         *internalTelemetry
+	logCore           zapcore.Core
+	observedLogs      *ObservedLogs
+	// pendingMetrics and pendingSpans hold the internal telemetry recorded
+	// during this execution by cached components, which have their own.
+	pendingMetrics pmetric.Metrics
+	pendingSpans   ptrace.Traces
+	host           *playgroundHost
+	shutdownErrors []ComponentError
+	batches        []any
+	cacheStatus    CacheStatus
+	phases         [phaseCount]time.Duration
+}
+
+// executionPhase is a phase of an execution, whose duration is reported in the
+// Result's Timings.
+type executionPhase int
+
+const (
+	phaseParse executionPhase = iota
+	phaseValidate
+	phaseCreate
+	phaseStart
+	phaseConsume
+	phaseMarshal
+	phaseCount
+)
+
+var _ Observable = (*execution)(nil)
+
+func newExecution(options ...ExecutionOption) *execution {
+	settings := newExecutionSettings(options...)
+
+	logCore, observedLogs := NewLogObserver(settings.logLevel, zap.NewDevelopmentEncoderConfig())
+	telemetry := newInternalTelemetry()
+
+	telemetrySettings := componenttest.NewNopTelemetrySettings()
+	telemetrySettings.Logger = newLogger(logCore)
+	telemetrySettings.MeterProvider = telemetry.meterProvider
+	telemetrySettings.TracerProvider = telemetry.tracerProvider
+
+	ctx, cancel := newExecutionContext(settings)
+	return &execution{
+		settings:          settings,
+		ctx:               ctx,
+		cancel:            cancel,
+		telemetrySettings: telemetrySettings,
+		telemetry:         telemetry,
+		logCore:           logCore,
+		observedLogs:      observedLogs,
+		pendingMetrics:    pmetric.NewMetrics(),
+		pendingSpans:      ptrace.NewTraces(),
+		host:              newPlaygroundHost(),
+	}
+}
+
+// newLogger returns the logger given to the components, writing to core.
+func newLogger(core zapcore.Core) *zap.Logger {
+	logger, _ := zap.NewDevelopmentConfig().Build(zap.WrapCore(func(zapcore.Core) zapcore.Core {
+		return core
+	}))
+	return logger
+}
+
+// newExecutionContext returns the context passed to the components, carrying
+// the client information, the fixed time and the deadline set by the execution
+// options, if any.
+func newExecutionContext(settings executionSettings) (context.Context, context.CancelFunc) {
+	ctx := settings.ctx
+	if ctx == nil {
+		ctx = context.Background()
+	}
+
+	if settings.clientMetadata != nil || settings.authAttributes != nil {
+		info := client.Info{Metadata: client.NewMetadata(settings.clientMetadata)}
+		if settings.authAttributes != nil {
+			info.Auth = authData(settings.authAttributes)
+		}
+		ctx = client.NewContext(ctx, info)
+	}
+
+	if !settings.fixedTime.IsZero() {
+		ctx = context.WithValue(ctx, fixedTimeKey{}, settings.fixedTime)
+	}
+
+	if settings.timeout > 0 {
+		return context.WithTimeoutCause(ctx, settings.timeout, timeoutCause(settings.timeout))
+	}
+	return context.WithCancel(ctx)
+}
+
+// authData is a client.AuthData holding a fixed set of attributes.
+type authData map[string]any
+
+func (a authData) GetAttribute(name string) any {
+	return a[name]
+}
+
+func (a authData) GetAttributeNames() []string {
+	names := make([]string, 0, len(a))
+	for name := range a {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Context returns the context components must be called with during this
+// execution.
+func (e *execution) Context() context.Context {
+	return e.ctx
+}
+
+// cancellationCause returns why the execution was cancelled, or nil if it
+// wasn't.
+func (e *execution) cancellationCause() error {
+	if e.ctx.Err() != nil {
+		return context.Cause(e.ctx)
+	}
+	// On js/wasm, the timers can't fire while the execution blocks the only
+	// thread, so the context isn't cancelled when the deadline passes.
+	deadline, ok := e.ctx.Deadline()
+	if !ok || time.Now().Before(deadline) {
+		return nil
+	}
+	if parentDeadline, ok := e.parentContext().Deadline(); ok && !parentDeadline.After(deadline) {
+		return context.DeadlineExceeded
+	}
+	return timeoutCause(e.settings.timeout)
+}
+
+// parentContext returns the context the execution was created with, see
+// WithContext.
+func (e *execution) parentContext() context.Context {
+	if e.settings.ctx == nil {
+		return context.Background()
+	}
+	return e.settings.ctx
+}
+
+// timeoutCause returns the error an execution timing out is cancelled with.
+func timeoutCause(timeout time.Duration) error {
+	return fmt.Errorf("execution timed out after %s", timeout)
+}
+
+// checkCancelled returns an error if the execution was cancelled. Components
+// don't watch the context, so it must be checked between the units of work.
+func (e *execution) checkCancelled() error {
+	if cause := e.cancellationCause(); cause != nil {
+		return fmt.Errorf("execution cancelled: %w", cause)
+	}
+	return nil
+}
+
+// renewContext replaces the context of the execution, restarting its timeout.
+// Debug sessions renew it on every call, as they may stay paused longer.
+func (e *execution) renewContext() {
+	e.cancel()
+	e.ctx, e.cancel = newExecutionContext(e.settings)
+}
+
+// close releases the resources held by the execution. The telemetry recorded
+// afterward is discarded.
+func (e *execution) close() {
+	if err := e.shutdownExtensions(); err != nil {
+		e.TelemetrySettings().Logger.Warn("[playground] Failed to shutdown extensions", zap.Error(err))
+	}
+	e.cancel()
+	e.telemetry.shutdown()
+}
+
+// cancellable reports whether the execution may be cancelled before it
+// completes, that is, whether it has a timeout or a cancellable parent context.
+func (e *execution) cancellable() bool {
+	return e.settings.timeout > 0 || (e.settings.ctx != nil && e.settings.ctx.Done() != nil)
+}
+
+// hasFixedTime reports whether the execution has a fixed time, see WithFixedTime.
+func (e *execution) hasFixedTime() bool {
+	return !e.settings.fixedTime.IsZero()
+}
+
+// benchmarking reports whether the execution runs a benchmark, see WithBenchmark.
+func (e *execution) benchmarking() bool {
+	return e.settings.benchmark.runs > 0 || e.settings.benchmark.duration > 0
+}
+
+// discardTelemetry drops the logs, internal telemetry and batches recorded
+// so far, which are never reported.
+func (e *execution) discardTelemetry() {
+	e.observedLogs.TakeAll()
+	_, _ = e.collectMetrics()
+	e.collectSpans()
+	e.takeBatches()
+}
+
+// recordBatch records a batch of data emitted by the running component.
+func (e *execution) recordBatch(batch any) {
+	e.batches = append(e.batches, batch)
+}
+
+// takeBatches returns the batches recorded since the last call.
+func (e *execution) takeBatches() []any {
+	batches := e.batches
+	e.batches = nil
+	return batches
+}
+
+// Host returns the component.Host components must be started with during this
+// execution.
+func (e *execution) Host() component.Host {
+	return e.host
+}
+
+// addShutdownError records an error returned by a component's Shutdown.
+func (e *execution) addShutdownError(id component.ID, err error) {
+	e.shutdownErrors = append(e.shutdownErrors, ComponentError{ComponentID: id.String(), Error: err.Error()})
+}
+
+// takeShutdownErrors returns the shutdown errors recorded since the last call.
+func (e *execution) takeShutdownErrors() []ComponentError {
+	errs := e.shutdownErrors
+	e.shutdownErrors = nil
+	return errs
+}
+
+// ObservedLogs returns the logs observed during this execution.
+func (e *execution) ObservedLogs() *ObservedLogs {
+	return e.observedLogs
+}
+
+// TelemetrySettings returns the telemetry settings components must use during
+// this execution.
+func (e *execution) TelemetrySettings() component.TelemetrySettings {
+	return e.telemetrySettings
+}
+
+// collectMetrics returns the metrics the components recorded about themselves
+// since the last collection.
+func (e *execution) collectMetrics() (pmetric.Metrics, error) {
+	metrics, err := e.telemetry.collectMetrics()
+	if err != nil {
+		return pmetric.Metrics{}, err
+	}
+	moveInternalMetrics(e.pendingMetrics, metrics)
+	e.pendingMetrics = pmetric.NewMetrics()
+	return metrics, nil
+}
+
+// collectSpans returns the spans the components ended since the last collection.
+func (e *execution) collectSpans() ptrace.Traces {
+	spans := e.telemetry.collectSpans()
+	moveInternalSpans(e.pendingSpans, spans)
+	e.pendingSpans = ptrace.NewTraces()
+	return spans
+}
+
+// addInternalTelemetry adds telemetry recorded by components outside of the
+// execution's own providers, to be collected along with it.
+func (e *execution) addInternalTelemetry(metrics pmetric.Metrics, spans ptrace.Traces) {
+	moveInternalMetrics(metrics, e.pendingMetrics)
+	moveInternalSpans(spans, e.pendingSpans)
+}
+
+// recordCacheLookup records whether a cached item, such as the parsed
+// configuration or a started component, could be reused. The execution is a
+// cache hit only if every lookup was.
+func (e *execution) recordCacheLookup(hit bool) {
+	if hit && e.cacheStatus == "" {
+		e.cacheStatus = CacheStatusHit
+	} else if !hit {
+		e.cacheStatus = CacheStatusMiss
+	}
+}
+
+// timePhase records the time elapsed since start as spent in the given phase.
+func (e *execution) timePhase(phase executionPhase, start time.Time) {
+	e.phases[phase] += time.Since(start)
+}
+
+// takeTimings returns the timings recorded since the last call.
+func (e *execution) takeTimings() *Timings {
+	timings := &Timings{
+		Parse:    e.phases[phaseParse].Microseconds(),
+		Validate: e.phases[phaseValidate].Microseconds(),
+		Create:   e.phases[phaseCreate].Microseconds(),
+		Start:    e.phases[phaseStart].Microseconds(),
+		Consume:  e.phases[phaseConsume].Microseconds(),
+		Marshal:  e.phases[phaseMarshal].Microseconds(),
+	}
+	timings.Compile = timings.Parse + timings.Validate + timings.Create + timings.Start
+	timings.Execution = timings.Consume
+	e.phases = [phaseCount]time.Duration{}
+	return timings
+}
@@ -0,0 +1,183 @@
+/*
+ * Copyright Elasticsearch B.V. and/or licensed to Elasticsearch B.V. under one
+ * or more contributor license agreements. See the NOTICE file distributed with
+ * this work for additional information regarding copyright
+ * ownership. Elasticsearch B.V. licenses this file to you under
+ * the Apache License, Version 2.0 (the "License"); you may
+ * not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *	http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing,
+ * software distributed under the License is distributed on an
+ * "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+ * KIND, either express or implied.  See the License for the
+ * specific language governing permissions and limitations
+ * under the License.
+ */
+
+package internal
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/collector/client"
+	"go.uber.org/zap/zapcore"
+)
+
+func Test_newExecution(t *testing.T) {
+	exec := newExecution()
+
+	require.NotNil(t, exec)
+	require.NotNil(t, exec.ObservedLogs())
+	require.NotNil(t, exec.TelemetrySettings().Logger)
+}
+
+func Test_execution_ObservedLogs(t *testing.T) {
+	exec := newExecution()
+	exec.TelemetrySettings().Logger.Sugar().Debug("this is a log")
+
+	logEntries := exec.ObservedLogs().TakeAll()
+	require.Len(t, logEntries, 1)
+	assert.Contains(t, logEntries[0].ConsoleEncodedEntry(), "this is a log")
+}
+
+func Test_execution_Isolation(t *testing.T) {
+	first := newExecution()
+	second := newExecution()
+
+	first.TelemetrySettings().Logger.Sugar().Debug("first execution log")
+	second.TelemetrySettings().Logger.Sugar().Debug("second execution log")
+
+	firstLogs := first.ObservedLogs().TakeAllString()
+	secondLogs := second.ObservedLogs().TakeAllString()
+
+	assert.Contains(t, firstLogs, "first execution log")
+	assert.NotContains(t, firstLogs, "second execution log")
+	assert.Contains(t, secondLogs, "second execution log")
+	assert.NotContains(t, secondLogs, "first execution log")
+}
+
+func Test_newExecution_WithLogLevel(t *testing.T) {
+	exec := newExecution(WithLogLevel(zapcore.WarnLevel))
+
+	logger := exec.TelemetrySettings().Logger
+	logger.Debug("debug log")
+	logger.Info("info log")
+	logger.Warn("warn log")
+
+	entries := exec.ObservedLogs().TakeAllEntries()
+	require.Len(t, entries, 1)
+	assert.Equal(t, "warn", entries[0].Level)
+	assert.Equal(t, "warn log", entries[0].Message)
+}
+
+func Test_newExecution_Context(t *testing.T) {
+	exec := newExecution()
+	info := client.FromContext(exec.Context())
+	assert.Nil(t, info.Auth)
+	assert.Empty(t, info.Metadata.Get("x-tenant"))
+
+	exec = newExecution(
+		WithClientMetadata(map[string][]string{"X-Tenant": {"tenant-a", "tenant-b"}}),
+		WithAuthAttributes(map[string]any{"subject": "user", "membership": []string{"dev"}}),
+	)
+	info = client.FromContext(exec.Context())
+	assert.Equal(t, []string{"tenant-a", "tenant-b"}, info.Metadata.Get("x-tenant"))
+	require.NotNil(t, info.Auth)
+	assert.Equal(t, "user", info.Auth.GetAttribute("subject"))
+	assert.Nil(t, info.Auth.GetAttribute("unknown"))
+	assert.Equal(t, []string{"membership", "subject"}, info.Auth.GetAttributeNames())
+}
+
+func Test_newExecution_WithContext(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	exec := newExecution(WithContext(ctx))
+	defer exec.close()
+	require.NoError(t, exec.checkCancelled())
+
+	cancel()
+	assert.ErrorIs(t, exec.cancellationCause(), context.Canceled)
+	assert.ErrorContains(t, exec.checkCancelled(), "execution cancelled")
+}
+
+func Test_newExecution_WithTimeout(t *testing.T) {
+	exec := newExecution(WithTimeout(time.Millisecond))
+	defer exec.close()
+
+	<-exec.Context().Done()
+	assert.EqualError(t, exec.cancellationCause(), "execution timed out after 1ms")
+	assert.ErrorContains(t, exec.checkCancelled(), "execution cancelled: execution timed out after 1ms")
+}
+
+func Test_newExecution_PassedDeadline(t *testing.T) {
+	// On js/wasm, the context isn't cancelled when its deadline passes
+	exec := newExecution(WithContext(stoppedTimerContext{Context: context.Background(), deadline: time.Now().Add(-time.Second)}))
+	defer exec.close()
+
+	require.NoError(t, exec.Context().Err())
+	assert.ErrorIs(t, exec.cancellationCause(), context.DeadlineExceeded)
+	assert.ErrorContains(t, exec.checkCancelled(), "execution cancelled")
+}
+
+// stoppedTimerContext is a context.Context having a deadline, but never
+// cancelled, as when its timer can't fire.
+type stoppedTimerContext struct {
+	context.Context
+	deadline time.Time
+}
+
+func (c stoppedTimerContext) Deadline() (time.Time, bool) {
+	return c.deadline, true
+}
+
+func (c stoppedTimerContext) Done() <-chan struct{} {
+	return make(chan struct{})
+}
+
+func Test_newExecution_WithFixedTime(t *testing.T) {
+	exec := newExecution()
+	defer exec.close()
+	assert.False(t, exec.hasFixedTime())
+	_, ok := fixedTimeFromContext(exec.Context())
+	assert.False(t, ok)
+
+	fixedTime := time.Date(2025, 1, 2, 3, 4, 5, 0, time.UTC)
+	exec = newExecution(WithFixedTime(fixedTime))
+	defer exec.close()
+	assert.True(t, exec.hasFixedTime())
+	value, ok := fixedTimeFromContext(exec.Context())
+	require.True(t, ok)
+	assert.Equal(t, fixedTime, value)
+}
+
+func Test_execution_takeTimings(t *testing.T) {
+	exec := newExecution()
+	defer exec.close()
+
+	now := time.Now()
+	exec.timePhase(phaseParse, now.Add(-1*time.Millisecond))
+	exec.timePhase(phaseValidate, now.Add(-2*time.Millisecond))
+	exec.timePhase(phaseCreate, now.Add(-3*time.Millisecond))
+	exec.timePhase(phaseStart, now.Add(-4*time.Millisecond))
+	exec.timePhase(phaseConsume, now.Add(-5*time.Millisecond))
+	exec.timePhase(phaseMarshal, now.Add(-6*time.Millisecond))
+
+	timings := exec.takeTimings()
+	assert.GreaterOrEqual(t, timings.Parse, int64(1000))
+	assert.GreaterOrEqual(t, timings.Validate, int64(2000))
+	assert.GreaterOrEqual(t, timings.Create, int64(3000))
+	assert.GreaterOrEqual(t, timings.Start, int64(4000))
+	assert.GreaterOrEqual(t, timings.Consume, int64(5000))
+	assert.GreaterOrEqual(t, timings.Marshal, int64(6000))
+	assert.Equal(t, timings.Parse+timings.Validate+timings.Create+timings.Start, timings.Compile)
+	assert.Equal(t, timings.Consume, timings.Execution)
+
+	// Taken timings are only reported once
+	assert.Equal(t, &Timings{}, exec.takeTimings())
+}
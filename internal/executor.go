@@ -37,6 +37,9 @@ const (
 	ResultViewAnnotatedDiff ResultView = "annotated_delta"
 	ResultViewJSON          ResultView = "json"
 	ResultViewLogs          ResultView = "logs"
+	ResultViewMetrics       ResultView = "internal_metrics"
+	ResultViewSpans         ResultView = "internal_spans"
+	ResultViewBatches       ResultView = "batches"
 )
 
 type ResultViewConfig struct {
@@ -49,6 +52,9 @@ func newDefaultResultViewConfig() map[ResultView]*ResultViewConfig {
 		ResultViewAnnotatedDiff: {Enabled: true},
 		ResultViewJSON:          {Enabled: true},
 		ResultViewLogs:          {Enabled: true},
+		ResultViewMetrics:       {Enabled: true},
+		ResultViewSpans:         {Enabled: true},
+		ResultViewBatches:       {Enabled: true},
 	}
 }
 
@@ -88,6 +94,16 @@ type Metadata struct {
 	ResultViewConfig map[ResultView]*ResultViewConfig `json:"resultViewConfig"`
 	Examples         Examples                         `json:"examples"`
 	Debuggable       bool                             `json:"debuggable"`
+	Profilable       bool                             `json:"profilable"`
+	// DebugSessions reports whether the executor's debugger supports debug
+	// sessions, see SessionDebugger.
+	DebugSessions bool `json:"debugSessions"`
+	// ClientInfo reports whether the executor passes the client metadata and
+	// auth attributes set by the execution options to the component.
+	ClientInfo bool `json:"clientInfo"`
+	// FixedTimeContexts lists the OTTL contexts in which the Now() function
+	// returns the time set by WithFixedTime.
+	FixedTimeContexts []string `json:"fixedTimeContexts,omitempty"`
 }
 
 // metadataOption is a function that modifies the Metadata configuration.
@@ -124,6 +140,24 @@ func disableResultViews(views ...ResultView) metadataOption {
 	}
 }
 
+// withClientInfo marks the executor as passing the execution's client
+// information to the component.
+func withClientInfo() metadataOption {
+	return func(metadata *Metadata) error {
+		metadata.ClientInfo = true
+		return nil
+	}
+}
+
+// withFixedTimeContexts sets the OTTL contexts in which the executor pins the
+// Now() function to the time set by WithFixedTime.
+func withFixedTimeContexts(contexts ...string) metadataOption {
+	return func(metadata *Metadata) error {
+		metadata.FixedTimeContexts = contexts
+		return nil
+	}
+}
+
 // withConfigExamples adds examples to the executor metadata.
 func withConfigExamples(examples ...ConfigExample) metadataOption {
 	return func(metadata *Metadata) error {
@@ -162,17 +196,20 @@ func newMetadata(ct ComponentType, id, name, path, docsURL string, options ...me
 }
 
 // Executor evaluates OTTL statements using specific configurations and inputs.
+// Every call runs in its own execution, so executors can be shared and used
+// concurrently. When the execution fails, the returned Result, if not nil, holds
+// the logs observed until the failure. Cancelled executions, see WithContext and
+// WithTimeout, don't fail but return the partial Result flagged as Cancelled.
 type Executor interface {
-	Observable
 	// ExecuteLogs evaluates log statements using the given configuration and JSON payload.
 	// The returned value must be a valid plog.Logs JSON representing the input transformation.
-	ExecuteLogs(config, input string) (*Result, error)
+	ExecuteLogs(config, input string, options ...ExecutionOption) (*Result, error)
 	// ExecuteTraces is like ExecuteLogs, but for traces.
-	ExecuteTraces(config, input string) (*Result, error)
+	ExecuteTraces(config, input string, options ...ExecutionOption) (*Result, error)
 	// ExecuteMetrics is like ExecuteLogs, but for metrics.
-	ExecuteMetrics(config, input string) (*Result, error)
+	ExecuteMetrics(config, input string, options ...ExecutionOption) (*Result, error)
 	// ExecuteProfiles is like ExecuteLogs, but for profiles.
-	ExecuteProfiles(config, input string) (*Result, error)
+	ExecuteProfiles(config, input string, options ...ExecutionOption) (*Result, error)
 	// Metadata returns information about the executor
 	Metadata() *Metadata
 }
@@ -184,16 +221,66 @@ type DebuggableExecutor interface {
 
 // Debugger provides debugging capabilities for OTTL statements.
 type Debugger interface {
-	Observable
 	// DebugLogs evaluates log statements using the given configuration and JSON
 	// payload with debugging enabled.
-	DebugLogs(config, input string) (*Result, error)
+	DebugLogs(config, input string, options ...ExecutionOption) (*Result, error)
 	// DebugTraces is like DebugLogs, but for traces.
-	DebugTraces(config, input string) (*Result, error)
+	DebugTraces(config, input string, options ...ExecutionOption) (*Result, error)
 	// DebugMetrics is like DebugLogs, but for metrics.
-	DebugMetrics(config, input string) (*Result, error)
+	DebugMetrics(config, input string, options ...ExecutionOption) (*Result, error)
 	// DebugProfiles is like DebugLogs, but for profiles.
-	DebugProfiles(config, input string) (*Result, error)
+	DebugProfiles(config, input string, options ...ExecutionOption) (*Result, error)
+}
+
+// SessionDebugger is a Debugger that can also run the steps one at a time,
+// pausing at breakpoints. See DebugSession.
+type SessionDebugger interface {
+	Debugger
+	// StartLogs starts a debug session of the log statements of the given
+	// configuration using the given JSON payload, running its steps up to the
+	// first breakpoint. The session must be closed once done with.
+	StartLogs(config, input string, breakpoints []Breakpoint, options ...ExecutionOption) (DebugSession, *Result, error)
+	// StartTraces is like StartLogs, but for traces.
+	StartTraces(config, input string, breakpoints []Breakpoint, options ...ExecutionOption) (DebugSession, *Result, error)
+	// StartMetrics is like StartLogs, but for metrics.
+	StartMetrics(config, input string, breakpoints []Breakpoint, options ...ExecutionOption) (DebugSession, *Result, error)
+	// StartProfiles is like StartLogs, but for profiles.
+	StartProfiles(config, input string, breakpoints []Breakpoint, options ...ExecutionOption) (DebugSession, *Result, error)
+}
+
+// DebugSession runs the steps of a debugger on demand. The Results it returns
+// hold the steps run by the call, as returned by the Debugger, and the state
+// of the session paused before its next step, unless it ran all of them. The
+// execution options, such as the timeout, apply to each call.
+type DebugSession interface {
+	// Continue runs the next step, and the following ones up to the next
+	// breakpoint.
+	Continue() (*Result, error)
+	// Step runs the next step only.
+	Step() (*Result, error)
+	// Close releases the resources held by the session.
+	Close()
+}
+
+// ProfilableExecutor is an Executor that supports profiling.
+type ProfilableExecutor interface {
+	Profiler() (Profiler, error)
+}
+
+// Profiler measures the cost of each OTTL statement and condition of a
+// configuration, running each of them many times against the payload. See
+// WithBenchmark to set the number of runs. The returned Result holds the
+// output of the configuration, and the costs ranked from the highest.
+type Profiler interface {
+	// ProfileLogs profiles the log statements of the given configuration using
+	// the given JSON payload.
+	ProfileLogs(config, input string, options ...ExecutionOption) (*Result, error)
+	// ProfileTraces is like ProfileLogs, but for traces.
+	ProfileTraces(config, input string, options ...ExecutionOption) (*Result, error)
+	// ProfileMetrics is like ProfileLogs, but for metrics.
+	ProfileMetrics(config, input string, options ...ExecutionOption) (*Result, error)
+	// ProfileProfiles is like ProfileLogs, but for profiles.
+	ProfileProfiles(config, input string, options ...ExecutionOption) (*Result, error)
 }
 
 // Observable represents an entity that can provide observed logs.
@@ -202,14 +289,19 @@ type Observable interface {
 	ObservedLogs() *ObservedLogs
 }
 
+// configCacheSize is the number of parsed configurations kept by each executor.
+const configCacheSize = 16
+
 type defaultExecutor[C any] struct {
 	consumer         Consumer[C]
+	configs          *lruCache[string, []parsedConfig[C]]
 	metadata         *Metadata
 	logMarshaler     plog.Marshaler
 	metricMarshaler  pmetric.Marshaler
 	traceMarshaler   ptrace.Marshaler
 	profileMarshaler pprofile.Marshaler
 	debugger         Debugger
+	profiler         Profiler
 }
 
 type executorOption[C any] func(*defaultExecutor[C])
@@ -217,10 +309,18 @@ type executorOption[C any] func(*defaultExecutor[C])
 func withDebugger[C any](debugger Debugger) executorOption[C] {
 	return func(e *defaultExecutor[C]) {
 		e.metadata.Debuggable = true
+		_, e.metadata.DebugSessions = debugger.(SessionDebugger)
 		e.debugger = debugger
 	}
 }
 
+func withProfiler[C any](profiler Profiler) executorOption[C] {
+	return func(e *defaultExecutor[C]) {
+		e.metadata.Profilable = true
+		e.profiler = profiler
+	}
+}
+
 func NewJSONExecutor[C any](
 	consumer Consumer[C],
 	metadata *Metadata,
@@ -228,6 +328,7 @@ func NewJSONExecutor[C any](
 ) Executor {
 	exec := &defaultExecutor[C]{
 		consumer:         consumer,
+		configs:          newLRUCache[string, []parsedConfig[C]](configCacheSize),
 		metadata:         metadata,
 		logMarshaler:     &plog.JSONMarshaler{},
 		metricMarshaler:  &pmetric.JSONMarshaler{},
@@ -240,116 +341,257 @@ func NewJSONExecutor[C any](
 	return exec
 }
 
-func (e *defaultExecutor[C]) ExecuteLogs(config, input string) (*Result, error) {
+func (e *defaultExecutor[C]) ExecuteLogs(config, input string, options ...ExecutionOption) (*Result, error) {
+	restoreFeatureGates, err := applyFeatureGates(options...)
+	if err != nil {
+		return nil, err
+	}
+	defer restoreFeatureGates()
+
 	logsUnmarshaler := &plog.JSONUnmarshaler{}
 	inputLogs, err := logsUnmarshaler.UnmarshalLogs([]byte(input))
 	if err != nil {
 		return nil, err
 	}
 
-	cfgs, err := parseConfig[C](e.consumer.ComponentID(), config, e.consumer.CreateDefaultConfig)
+	exec := newExecution(options...)
+	defer exec.close()
+
+	cfgs, err := e.parseConfig(exec, config)
 	if err != nil {
 		return nil, err
 	}
 
-	return newExecutionResult(e, e.logMarshaler.MarshalLogs, func() (plog.Logs, error) {
-		transformedLogs := inputLogs
+	if err = exec.startExtensions(config); err != nil {
+		return nil, err
+	}
+
+	var benchmarkInput plog.Logs
+	if exec.benchmarking() {
+		benchmarkInput = copyLogs(inputLogs)
+	}
+
+	consumeLogs := func(logs plog.Logs) (plog.Logs, error) {
 		for _, cfg := range cfgs {
 			if len(cfgs) > 1 {
-				e.consumer.TelemetrySettings().Logger.Sugar().Debugf("[playground] Running configuration: %s", cfg.Key)
+				exec.TelemetrySettings().Logger.Sugar().Debugf("[playground] Running configuration: %s", cfg.Key)
 			}
-			transformedLogs, err = e.consumer.ConsumeLogs(cfg.Value, transformedLogs)
+			transformedLogs, err := e.consumer.ConsumeLogs(exec, cfg, logs)
 			if err != nil {
-				return plog.Logs{}, err
+				return transformedLogs, err
 			}
+			logs = transformedLogs
 		}
-		return transformedLogs, nil
+		return logs, nil
+	}
+
+	res, err := newExecutionResult(exec, e.logMarshaler.MarshalLogs, func() (plog.Logs, error) {
+		return consumeLogs(inputLogs)
 	})
+	if err == nil && !res.Cancelled && exec.benchmarking() {
+		err = runBenchmark(exec, res, benchmarkInput, copyLogs, plog.Logs.LogRecordCount, consumeLogs)
+	}
+	e.addStatementDetails(res, exec, config, cfgs)
+	return res, err
 }
 
-func (e *defaultExecutor[C]) ExecuteTraces(config, input string) (*Result, error) {
+func (e *defaultExecutor[C]) ExecuteTraces(config, input string, options ...ExecutionOption) (*Result, error) {
+	restoreFeatureGates, err := applyFeatureGates(options...)
+	if err != nil {
+		return nil, err
+	}
+	defer restoreFeatureGates()
+
 	tracesUnmarshaler := &ptrace.JSONUnmarshaler{}
 	inputTraces, err := tracesUnmarshaler.UnmarshalTraces([]byte(input))
 	if err != nil {
 		return nil, err
 	}
 
-	cfgs, err := parseConfig[C](e.consumer.ComponentID(), config, e.consumer.CreateDefaultConfig)
+	exec := newExecution(options...)
+	defer exec.close()
+
+	cfgs, err := e.parseConfig(exec, config)
 	if err != nil {
 		return nil, err
 	}
 
-	return newExecutionResult(e, e.traceMarshaler.MarshalTraces, func() (ptrace.Traces, error) {
-		transformedTraces := inputTraces
+	if err = exec.startExtensions(config); err != nil {
+		return nil, err
+	}
+
+	var benchmarkInput ptrace.Traces
+	if exec.benchmarking() {
+		benchmarkInput = copyTraces(inputTraces)
+	}
+
+	consumeTraces := func(traces ptrace.Traces) (ptrace.Traces, error) {
 		for _, cfg := range cfgs {
 			if len(cfgs) > 1 {
-				e.consumer.TelemetrySettings().Logger.Sugar().Debugf("[playground] Running configuration: %s", cfg.Key)
+				exec.TelemetrySettings().Logger.Sugar().Debugf("[playground] Running configuration: %s", cfg.Key)
 			}
-			transformedTraces, err = e.consumer.ConsumeTraces(cfg.Value, transformedTraces)
+			transformedTraces, err := e.consumer.ConsumeTraces(exec, cfg, traces)
 			if err != nil {
-				return ptrace.Traces{}, err
+				return transformedTraces, err
 			}
+			traces = transformedTraces
 		}
-		return transformedTraces, nil
+		return traces, nil
+	}
+
+	res, err := newExecutionResult(exec, e.traceMarshaler.MarshalTraces, func() (ptrace.Traces, error) {
+		return consumeTraces(inputTraces)
 	})
+	if err == nil && !res.Cancelled && exec.benchmarking() {
+		err = runBenchmark(exec, res, benchmarkInput, copyTraces, ptrace.Traces.SpanCount, consumeTraces)
+	}
+	e.addStatementDetails(res, exec, config, cfgs)
+	return res, err
 }
 
-func (e *defaultExecutor[C]) ExecuteMetrics(config, input string) (*Result, error) {
+func (e *defaultExecutor[C]) ExecuteMetrics(config, input string, options ...ExecutionOption) (*Result, error) {
+	restoreFeatureGates, err := applyFeatureGates(options...)
+	if err != nil {
+		return nil, err
+	}
+	defer restoreFeatureGates()
+
 	metricsUnmarshaler := &pmetric.JSONUnmarshaler{}
 	inputMetrics, err := metricsUnmarshaler.UnmarshalMetrics([]byte(input))
 	if err != nil {
 		return nil, err
 	}
 
-	cfgs, err := parseConfig[C](e.consumer.ComponentID(), config, e.consumer.CreateDefaultConfig)
+	exec := newExecution(options...)
+	defer exec.close()
+
+	cfgs, err := e.parseConfig(exec, config)
 	if err != nil {
 		return nil, err
 	}
 
-	return newExecutionResult(e, e.metricMarshaler.MarshalMetrics, func() (pmetric.Metrics, error) {
-		transformedMetrics := inputMetrics
+	if err = exec.startExtensions(config); err != nil {
+		return nil, err
+	}
+
+	var benchmarkInput pmetric.Metrics
+	if exec.benchmarking() {
+		benchmarkInput = copyMetrics(inputMetrics)
+	}
+
+	consumeMetrics := func(metrics pmetric.Metrics) (pmetric.Metrics, error) {
 		for _, cfg := range cfgs {
 			if len(cfgs) > 1 {
-				e.consumer.TelemetrySettings().Logger.Sugar().Debugf("[playground] Running configuration: %s", cfg.Key)
+				exec.TelemetrySettings().Logger.Sugar().Debugf("[playground] Running configuration: %s", cfg.Key)
 			}
-			transformedMetrics, err = e.consumer.ConsumeMetrics(cfg.Value, transformedMetrics)
+			transformedMetrics, err := e.consumer.ConsumeMetrics(exec, cfg, metrics)
 			if err != nil {
-				return pmetric.Metrics{}, err
+				return transformedMetrics, err
 			}
+			metrics = transformedMetrics
 		}
-		return transformedMetrics, nil
+		return metrics, nil
+	}
+
+	res, err := newExecutionResult(exec, e.metricMarshaler.MarshalMetrics, func() (pmetric.Metrics, error) {
+		return consumeMetrics(inputMetrics)
 	})
+	if err == nil && !res.Cancelled && exec.benchmarking() {
+		err = runBenchmark(exec, res, benchmarkInput, copyMetrics, pmetric.Metrics.DataPointCount, consumeMetrics)
+	}
+	e.addStatementDetails(res, exec, config, cfgs)
+	return res, err
 }
 
-func (e *defaultExecutor[C]) ExecuteProfiles(config, input string) (*Result, error) {
+func (e *defaultExecutor[C]) ExecuteProfiles(config, input string, options ...ExecutionOption) (*Result, error) {
+	restoreFeatureGates, err := applyFeatureGates(options...)
+	if err != nil {
+		return nil, err
+	}
+	defer restoreFeatureGates()
+
 	profilesUnmarshaler := &pprofile.JSONUnmarshaler{}
 	inputProfiles, err := profilesUnmarshaler.UnmarshalProfiles([]byte(input))
 	if err != nil {
 		return nil, err
 	}
 
-	cfgs, err := parseConfig[C](e.consumer.ComponentID(), config, e.consumer.CreateDefaultConfig)
+	exec := newExecution(options...)
+	defer exec.close()
+
+	cfgs, err := e.parseConfig(exec, config)
 	if err != nil {
 		return nil, err
 	}
 
-	return newExecutionResult(e, e.profileMarshaler.MarshalProfiles, func() (pprofile.Profiles, error) {
-		transformedProfiles := inputProfiles
+	if err = exec.startExtensions(config); err != nil {
+		return nil, err
+	}
+
+	var benchmarkInput pprofile.Profiles
+	if exec.benchmarking() {
+		benchmarkInput = copyProfiles(inputProfiles)
+	}
+
+	consumeProfiles := func(profiles pprofile.Profiles) (pprofile.Profiles, error) {
 		for _, cfg := range cfgs {
 			if len(cfgs) > 1 {
-				e.consumer.TelemetrySettings().Logger.Sugar().Debugf("[playground] Running configuration: %s", cfg.Key)
+				exec.TelemetrySettings().Logger.Sugar().Debugf("[playground] Running configuration: %s", cfg.Key)
 			}
-			transformedProfiles, err = e.consumer.ConsumeProfiles(cfg.Value, transformedProfiles)
+			transformedProfiles, err := e.consumer.ConsumeProfiles(exec, cfg, profiles)
 			if err != nil {
-				return pprofile.Profiles{}, err
+				return transformedProfiles, err
 			}
+			profiles = transformedProfiles
 		}
-		return transformedProfiles, nil
+		return profiles, nil
+	}
+
+	res, err := newExecutionResult(exec, e.profileMarshaler.MarshalProfiles, func() (pprofile.Profiles, error) {
+		return consumeProfiles(inputProfiles)
 	})
+	if err == nil && !res.Cancelled && exec.benchmarking() {
+		err = runBenchmark(exec, res, benchmarkInput, copyProfiles, pprofile.Profiles.SampleCount, consumeProfiles)
+	}
+	e.addStatementDetails(res, exec, config, cfgs)
+	return res, err
+}
+
+// parseConfig parses the configuration, reusing the result of a previous
+// execution with the same configuration and feature gates.
+func (e *defaultExecutor[C]) parseConfig(exec *execution, config string) ([]parsedConfig[C], error) {
+	hash := configHash(e.metadata.ID, config, exec.settings.featureGates)
+	if cfgs, ok := e.configs.get(hash); ok {
+		exec.recordCacheLookup(true)
+		return cfgs, nil
+	}
+
+	exec.recordCacheLookup(false)
+	cfgs, err := parseExecutionConfig(exec, e.consumer.ComponentID(), config, e.consumer.CreateDefaultConfig)
+	if err != nil {
+		return nil, err
+	}
+	for i := range cfgs {
+		cfgs[i].hash = hash
+	}
+	e.configs.add(hash, cfgs)
+	return cfgs, nil
 }
 
-func (e *defaultExecutor[C]) ObservedLogs() *ObservedLogs {
-	return e.consumer.ObservedLogs()
+// addStatementDetails reports whether the statements are deterministic, and
+// attaches the warnings they logged during the execution to the result, along
+// with their location in the configuration.
+func (e *defaultExecutor[C]) addStatementDetails(res *Result, exec *execution, config string, cfgs []parsedConfig[C]) {
+	if res == nil {
+		return
+	}
+	locator, err := newStatementLocator(config, cfgs)
+	if err != nil {
+		return
+	}
+	deterministic := locator.deterministic(exec.hasFixedTime(), e.metadata.FixedTimeContexts)
+	res.Deterministic = &deterministic
+	res.Warnings = locator.warnings(e.consumer.ComponentID(), res.LogEntries)
 }
 
 func (e *defaultExecutor[C]) Metadata() *Metadata {
@@ -362,3 +604,10 @@ func (e *defaultExecutor[C]) Debugger() (Debugger, error) {
 	}
 	return e.debugger, nil
 }
+
+func (e *defaultExecutor[C]) Profiler() (Profiler, error) {
+	if !e.metadata.Profilable || e.profiler == nil {
+		return nil, fmt.Errorf("executor %q does not support profiling", e.metadata.Name)
+	}
+	return e.profiler, nil
+}
@@ -20,15 +20,23 @@
 package internal
 
 import (
+	"context"
+	"errors"
 	"os"
 	"path/filepath"
+	"strings"
+	"sync"
 	"testing"
+	"time"
 
 	"github.com/open-telemetry/opentelemetry-collector-contrib/processor/transformprocessor"
 	"github.com/stretchr/testify/assert"
+	"go.opentelemetry.io/collector/component"
+	"go.opentelemetry.io/collector/consumer"
 	"go.opentelemetry.io/collector/pdata/plog"
 	"go.opentelemetry.io/collector/pdata/pmetric"
 	"go.opentelemetry.io/collector/pdata/ptrace"
+	"go.opentelemetry.io/collector/processor"
 
 	"github.com/stretchr/testify/require"
 )
@@ -127,3 +135,320 @@ func Test_Executor_ExecuteMetricsMultiple(t *testing.T) {
 	assert.True(t, val.Bool())
 	assert.Contains(t, output.Logs, "configuration: transform/multiple")
 }
+
+func Test_Executor_ConcurrentExecutionsLogs(t *testing.T) {
+	executor := NewJSONExecutor[transformprocessor.Config](
+		newProcessorConsumer[transformprocessor.Config](transformprocessor.NewFactory()),
+		&Metadata{},
+	)
+	config := readTestData(t, transformprocessorConfigMultiple)
+	payload := readTestData(t, "logs.json")
+
+	const executions = 10
+	results := make([]*Result, executions)
+	errs := make([]error, executions)
+
+	var wg sync.WaitGroup
+	for i := range executions {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			results[i], errs[i] = executor.ExecuteLogs(config, payload)
+		}()
+	}
+	wg.Wait()
+
+	for i := range executions {
+		require.NoError(t, errs[i])
+		assert.Equal(t, 1, strings.Count(results[i].Logs, "configuration: transform/multiple"))
+	}
+}
+
+func Test_Executor_ExecuteLogsStatementWarnings(t *testing.T) {
+	executor := NewJSONExecutor[transformprocessor.Config](
+		newProcessorConsumer[transformprocessor.Config](transformprocessor.NewFactory()),
+		&Metadata{},
+	)
+
+	config := `transform/a:
+  error_mode: ignore
+  log_statements:
+    - set(log.attributes["a"], "a")
+transform/b:
+  error_mode: ignore
+  log_statements:
+    - context: log
+      statements:
+        - set(log.attributes["b"], "b")
+        - merge_maps(log.attributes, ParseJSON(log.body), "upsert")`
+	payload := readTestData(t, "logs.json")
+
+	output, err := executor.ExecuteLogs(config, payload)
+	require.NoError(t, err)
+	require.NotEmpty(t, output.Warnings)
+
+	for _, warning := range output.Warnings {
+		assert.Equal(t, "transform/b", warning.ConfigKey)
+		assert.Equal(t, "log_statements.0.statements", warning.Path)
+		assert.Equal(t, 1, warning.Index)
+		assert.Equal(t, int64(11), warning.Line)
+		assert.Equal(t, "failed to execute statement", warning.Message)
+		assert.NotEmpty(t, warning.Error)
+	}
+}
+
+func Test_Executor_ExecuteLogsStatementWarningsContextLessPaths(t *testing.T) {
+	executor := NewTransformProcessorExecutor()
+	config := `transform:
+  error_mode: ignore
+  log_statements:
+    - context: log
+      statements:
+        - merge_maps(attributes, ParseJSON(body), "upsert")`
+	payload := readTestData(t, "logs.json")
+
+	// The processor logs the statements with the context prefixed to their paths
+	output, err := executor.ExecuteLogs(config, payload)
+	require.NoError(t, err)
+	require.NotEmpty(t, output.Warnings)
+	for _, warning := range output.Warnings {
+		assert.Equal(t, "log_statements.0.statements", warning.Path)
+		assert.Equal(t, int64(6), warning.Line)
+	}
+}
+
+func Test_Executor_ExecuteLogsCancelled(t *testing.T) {
+	executor := NewTransformProcessorExecutor()
+	config := readTestData(t, transformprocessorConfig)
+	payload := readTestData(t, "logs.json")
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	output, err := executor.ExecuteLogs(config, payload, WithContext(ctx))
+	require.NoError(t, err)
+	assert.True(t, output.Cancelled)
+	assert.Equal(t, context.Canceled.Error(), output.CancellationReason)
+	assert.Contains(t, output.Logs, "Execution cancelled")
+
+	// Nothing was processed, so the payload is returned as is
+	unmarshaler := &plog.JSONUnmarshaler{}
+	expectedLogs, err := unmarshaler.UnmarshalLogs([]byte(payload))
+	require.NoError(t, err)
+	outputLogs, err := unmarshaler.UnmarshalLogs([]byte(output.Value))
+	require.NoError(t, err)
+	assert.Equal(t, expectedLogs, outputLogs)
+}
+
+func Test_Executor_ExecuteLogsNotCancelled(t *testing.T) {
+	executor := NewTransformProcessorExecutor()
+	config := readTestData(t, transformprocessorConfig)
+	payload := readTestData(t, "logs.json")
+
+	output, err := executor.ExecuteLogs(config, payload, WithTimeout(time.Minute))
+	require.NoError(t, err)
+	assert.False(t, output.Cancelled)
+	assert.Empty(t, output.CancellationReason)
+}
+
+func Test_Executor_ExecuteLogsBatches(t *testing.T) {
+	factory := processor.NewFactory(
+		component.MustNewType("batching"),
+		func() component.Config { return &testProcessorConfig{} },
+		processor.WithLogs(func(_ context.Context, _ processor.Settings, _ component.Config, next consumer.Logs) (processor.Logs, error) {
+			// Emits every log record in its own batch
+			return newTestLogsProcessor(func(ctx context.Context, ld plog.Logs) error {
+				for _, rl := range ld.ResourceLogs().All() {
+					for _, sl := range rl.ScopeLogs().All() {
+						for _, lr := range sl.LogRecords().All() {
+							batch := plog.NewLogs()
+							lr.CopyTo(batch.ResourceLogs().AppendEmpty().ScopeLogs().AppendEmpty().LogRecords().AppendEmpty())
+							if err := next.ConsumeLogs(ctx, batch); err != nil {
+								return err
+							}
+						}
+					}
+				}
+				return nil
+			}), nil
+		}, component.StabilityLevelDevelopment),
+	)
+	executor := NewJSONExecutor[testProcessorConfig](newProcessorConsumer[testProcessorConfig](factory), &Metadata{})
+	payload := `{"resourceLogs":[{"scopeLogs":[{"logRecords":[{"body":{"stringValue":"first"}},{"body":{"stringValue":"second"}}]}]}]}`
+
+	output, err := executor.ExecuteLogs("batching:", payload)
+	require.NoError(t, err)
+	require.Len(t, output.Batches, 2)
+
+	// The batches emitted by the processor are kept when the execution is
+	// cancellable
+	cancellable, err := executor.ExecuteLogs("batching:", payload, WithTimeout(time.Minute))
+	require.NoError(t, err)
+	assert.Equal(t, output.Batches, cancellable.Batches)
+
+	unmarshaler := &plog.JSONUnmarshaler{}
+	for i, body := range []string{"first", "second"} {
+		batch, err := unmarshaler.UnmarshalLogs([]byte(output.Batches[i]))
+		require.NoError(t, err)
+		require.Equal(t, 1, batch.LogRecordCount())
+		assert.Equal(t, body, batch.ResourceLogs().At(0).ScopeLogs().At(0).LogRecords().At(0).Body().Str())
+	}
+
+	merged, err := unmarshaler.UnmarshalLogs([]byte(output.Value))
+	require.NoError(t, err)
+	assert.Equal(t, 2, merged.LogRecordCount())
+	assert.Equal(t, 2, merged.ResourceLogs().Len())
+}
+
+func Test_Executor_ExecuteLogsCancellableBatches(t *testing.T) {
+	executor := NewTransformProcessorExecutor()
+	config := readTestData(t, transformprocessorConfig)
+	payload := `{"resourceLogs":[{"scopeLogs":[{"logRecords":[{"body":{"stringValue":"first"}}]}]},{"scopeLogs":[{"logRecords":[{"body":{"stringValue":"second"}}]}]}]}`
+
+	// The resources are consumed one at a time, but emitted in a single batch
+	output, err := executor.ExecuteLogs(config, payload, WithTimeout(time.Minute))
+	require.NoError(t, err)
+	assert.Empty(t, output.Batches)
+
+	merged, err := (&plog.JSONUnmarshaler{}).UnmarshalLogs([]byte(output.Value))
+	require.NoError(t, err)
+	assert.Equal(t, 2, merged.ResourceLogs().Len())
+}
+
+func Test_Executor_ExecuteLogsSingleBatch(t *testing.T) {
+	executor := NewTransformProcessorExecutor()
+	config := readTestData(t, transformprocessorConfig)
+	payload := readTestData(t, "logs.json")
+
+	output, err := executor.ExecuteLogs(config, payload)
+	require.NoError(t, err)
+	assert.NotEmpty(t, output.Value)
+	assert.Empty(t, output.Batches)
+}
+
+// testLogsProcessor is a processor.Logs calling the given function with the
+// consumed logs.
+type testLogsProcessor struct {
+	component.StartFunc
+	component.ShutdownFunc
+	consume func(context.Context, plog.Logs) error
+}
+
+func newTestLogsProcessor(consume func(context.Context, plog.Logs) error) *testLogsProcessor {
+	return &testLogsProcessor{consume: consume}
+}
+
+func (p *testLogsProcessor) Capabilities() consumer.Capabilities {
+	return consumer.Capabilities{}
+}
+
+func (p *testLogsProcessor) ConsumeLogs(ctx context.Context, ld plog.Logs) error {
+	return p.consume(ctx, ld)
+}
+
+// newTestFlushingLogsFactory creates a processor factory passing the consumed
+// logs through and flushing a log record with the "flushed" body when shut
+// down, failing with the given error afterward.
+func newTestFlushingLogsFactory(shutdowns *int, shutdownErr error) processor.Factory {
+	return processor.NewFactory(
+		component.MustNewType("flushing"),
+		func() component.Config { return &testProcessorConfig{} },
+		processor.WithLogs(func(_ context.Context, _ processor.Settings, _ component.Config, next consumer.Logs) (processor.Logs, error) {
+			p := newTestLogsProcessor(next.ConsumeLogs)
+			p.ShutdownFunc = func(ctx context.Context) error {
+				*shutdowns++
+				flushed := plog.NewLogs()
+				flushed.ResourceLogs().AppendEmpty().ScopeLogs().AppendEmpty().LogRecords().AppendEmpty().Body().SetStr("flushed")
+				if err := next.ConsumeLogs(ctx, flushed); err != nil {
+					return err
+				}
+				return shutdownErr
+			}
+			return p, nil
+		}, component.StabilityLevelDevelopment),
+	)
+}
+
+func Test_Executor_ExecuteLogsShutdown(t *testing.T) {
+	var shutdowns int
+	factory := newTestFlushingLogsFactory(&shutdowns, nil)
+	executor := NewJSONExecutor[testProcessorConfig](newProcessorConsumer[testProcessorConfig](factory), &Metadata{})
+	payload := `{"resourceLogs":[{"scopeLogs":[{"logRecords":[{"body":{"stringValue":"first"}}]}]}]}`
+
+	// The processor isn't reusable, so it's shut down by every execution
+	// running the same configuration
+	for i := 1; i <= 2; i++ {
+		result, err := executor.ExecuteLogs("flushing:", payload)
+		require.NoError(t, err)
+		assert.Equal(t, i, shutdowns)
+		assert.Contains(t, result.Value, `"stringValue":"flushed"`)
+	}
+}
+
+func Test_Executor_ExecuteLogsShutdownErrors(t *testing.T) {
+	var shutdowns int
+	factory := newTestFlushingLogsFactory(&shutdowns, errors.New("shutdown failure"))
+	executor := NewJSONExecutor[testProcessorConfig](newProcessorConsumer[testProcessorConfig](factory), &Metadata{})
+	payload := `{"resourceLogs":[{"scopeLogs":[{"logRecords":[{"body":{"stringValue":"first"}}]}]}]}`
+
+	for range 2 {
+		result, err := executor.ExecuteLogs("flushing:", payload)
+		require.NoError(t, err)
+		assert.Equal(t, []ComponentError{{ComponentID: "flushing", Error: "shutdown failure"}}, result.ShutdownErrors)
+	}
+}
+
+func Test_Executor_ExecuteLogsFlushedBatches(t *testing.T) {
+	var shutdowns int
+	factory := newTestFlushingLogsFactory(&shutdowns, nil)
+	executor := NewJSONExecutor[testProcessorConfig](newProcessorConsumer[testProcessorConfig](factory), &Metadata{})
+	payload := `{"resourceLogs":[{"scopeLogs":[{"logRecords":[{"body":{"stringValue":"first"}}]}]}]}`
+
+	for range 2 {
+		result, err := executor.ExecuteLogs("flushing:", payload)
+		require.NoError(t, err)
+		require.Len(t, result.Batches, 2)
+		assert.Contains(t, result.Batches[0], `"stringValue":"first"`)
+		assert.Contains(t, result.Batches[1], `"stringValue":"flushed"`)
+
+		merged, err := (&plog.JSONUnmarshaler{}).UnmarshalLogs([]byte(result.Value))
+		require.NoError(t, err)
+		assert.Equal(t, 2, merged.LogRecordCount())
+	}
+}
+
+func Test_Executor_ExecuteLogsCache(t *testing.T) {
+	executor := NewTransformProcessorExecutor()
+	config := "transform:\n  log_statements:\n    - set(log.attributes[\"a\"], log.body)"
+	first := `{"resourceLogs":[{"scopeLogs":[{"logRecords":[{"body":{"stringValue":"first"}}]}]}]}`
+	second := `{"resourceLogs":[{"scopeLogs":[{"logRecords":[{"body":{"stringValue":"second"}}]}]}]}`
+
+	result, err := executor.ExecuteLogs(config, first)
+	require.NoError(t, err)
+	assert.Equal(t, CacheStatusMiss, result.Cache)
+	require.NotNil(t, result.Timings)
+	assert.Positive(t, result.Timings.Compile)
+	assert.Contains(t, result.Value, `"key":"a","value":{"stringValue":"first"}`)
+
+	// Only the payload changed, the processor is reused
+	result, err = executor.ExecuteLogs(config, second)
+	require.NoError(t, err)
+	assert.Equal(t, CacheStatusHit, result.Cache)
+	assert.Contains(t, result.Value, `"key":"a","value":{"stringValue":"second"}`)
+	require.NotNil(t, result.Timings)
+	assert.Zero(t, result.Timings.Parse+result.Timings.Create, "nothing is compiled again")
+	assert.NotEmpty(t, result.LogEntries, "the cached processor logs to the current execution")
+	assert.NotEmpty(t, result.InternalMetrics, "the cached processor's internal metrics are reported")
+
+	// The feature gates the configuration is compiled with are part of the key
+	result, err = executor.ExecuteLogs(config, second, WithFeatureGates(map[string]bool{testFeatureGate.ID(): true}))
+	require.NoError(t, err)
+	assert.Equal(t, CacheStatusMiss, result.Cache)
+
+	// The debugger doesn't use the cache
+	debugger, err := executor.(DebuggableExecutor).Debugger()
+	require.NoError(t, err)
+	result, err = debugger.DebugLogs(config, second)
+	require.NoError(t, err)
+	assert.Empty(t, result.Cache)
+}
@@ -0,0 +1,159 @@
+/*
+ * Copyright Elasticsearch B.V. and/or licensed to Elasticsearch B.V. under one
+ * or more contributor license agreements. See the NOTICE file distributed with
+ * this work for additional information regarding copyright
+ * ownership. Elasticsearch B.V. licenses this file to you under
+ * the Apache License, Version 2.0 (the "License"); you may
+ * not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *	http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing,
+ * software distributed under the License is distributed on an
+ * "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+ * KIND, either express or implied.  See the License for the
+ * specific language governing permissions and limitations
+ * under the License.
+ */
+
+package internal
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+
+	"go.opentelemetry.io/collector/component"
+	"go.opentelemetry.io/collector/confmap"
+	"go.opentelemetry.io/collector/extension"
+	"go.uber.org/zap"
+)
+
+// extensionsConfigKey is the configuration section declaring the extensions
+// available to the components, like in the collector configuration.
+const extensionsConfigKey = "extensions"
+
+var extensionFactories = func() map[component.Type]extension.Factory {
+	factories := map[component.Type]extension.Factory{}
+	for _, factory := range []extension.Factory{
+		newMemoryStorageExtensionFactory(),
+		newStaticTokenAuthExtensionFactory(),
+	} {
+		factories[factory.Type()] = factory
+	}
+	return factories
+}()
+
+type extensionConfig struct {
+	id     component.ID
+	config component.Config
+}
+
+// parseExtensionsConfig returns the configuration of the extensions declared
+// in the extensions section of the given YAML configuration, sorted by ID.
+func parseExtensionsConfig(yamlConfig string) ([]extensionConfig, error) {
+	deserializedYaml, err := confmap.NewRetrievedFromYAML([]byte(yamlConfig))
+	if err != nil {
+		return nil, err
+	}
+
+	deserializedConf, err := deserializedYaml.AsConf()
+	if err != nil {
+		return nil, err
+	}
+
+	if !deserializedConf.IsSet(extensionsConfigKey) {
+		return nil, nil
+	}
+
+	extensionsConf, err := deserializedConf.Sub(extensionsConfigKey)
+	if err != nil {
+		return nil, err
+	}
+
+	var configs []extensionConfig
+	for key := range extensionsConf.ToStringMap() {
+		var id component.ID
+		if err = id.UnmarshalText([]byte(key)); err != nil {
+			return nil, fmt.Errorf("invalid extension ID %q: %w", key, err)
+		}
+
+		factory, ok := extensionFactories[id.Type()]
+		if !ok {
+			return nil, fmt.Errorf("unknown extension type %q, supported types: %s", id.Type(), strings.Join(supportedExtensionTypes(), ", "))
+		}
+
+		sub, err := extensionsConf.Sub(key)
+		if err != nil {
+			return nil, err
+		}
+
+		config := factory.CreateDefaultConfig()
+		if err = unmarshalValidConfig(sub, config); err != nil {
+			return nil, fmt.Errorf("invalid %q extension configuration: %w", key, err)
+		}
+
+		configs = append(configs, extensionConfig{id, config})
+	}
+
+	slices.SortFunc(configs, func(a, b extensionConfig) int {
+		return strings.Compare(a.id.String(), b.id.String())
+	})
+	return configs, nil
+}
+
+func supportedExtensionTypes() []string {
+	types := make([]string, 0, len(extensionFactories))
+	for t := range extensionFactories {
+		types = append(types, t.String())
+	}
+	slices.Sort(types)
+	return types
+}
+
+// startExtensions creates and starts the extensions declared in the given YAML
+// configuration, making them available to the components through the
+// execution's host. They are shut down when the execution is closed.
+func (e *execution) startExtensions(yamlConfig string) error {
+	configs, err := parseExtensionsConfig(yamlConfig)
+	if err != nil {
+		return err
+	}
+
+	for _, cfg := range configs {
+		telemetrySettings := e.TelemetrySettings()
+		telemetrySettings.Logger = telemetrySettings.Logger.With(zap.String(componentIDLogField, cfg.id.String()))
+
+		ext, err := extensionFactories[cfg.id.Type()].Create(context.Background(), extension.Settings{
+			ID:                cfg.id,
+			TelemetrySettings: telemetrySettings,
+			BuildInfo:         component.NewDefaultBuildInfo(),
+		}, cfg.config)
+		if err != nil {
+			return fmt.Errorf("failed to create %q extension: %w", cfg.id, err)
+		}
+		e.host.extensions[cfg.id] = ext
+	}
+
+	for _, cfg := range configs {
+		if err = e.host.extensions[cfg.id].Start(context.Background(), e.host); err != nil {
+			return fmt.Errorf("failed to start %q extension: %w", cfg.id, err)
+		}
+	}
+	return nil
+}
+
+// shutdownExtensions shuts down the extensions started by the execution.
+func (e *execution) shutdownExtensions() error {
+	var errs []error
+	for id, ext := range e.host.extensions {
+		if err := ext.Shutdown(context.Background()); err != nil {
+			errs = append(errs, fmt.Errorf("failed to shutdown %q extension: %w", id, err))
+		}
+	}
+	clear(e.host.extensions)
+	return errors.Join(errs...)
+}
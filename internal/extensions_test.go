@@ -0,0 +1,244 @@
+/*
+ * Copyright Elasticsearch B.V. and/or licensed to Elasticsearch B.V. under one
+ * or more contributor license agreements. See the NOTICE file distributed with
+ * this work for additional information regarding copyright
+ * ownership. Elasticsearch B.V. licenses this file to you under
+ * the Apache License, Version 2.0 (the "License"); you may
+ * not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *	http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing,
+ * software distributed under the License is distributed on an
+ * "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+ * KIND, either express or implied.  See the License for the
+ * specific language governing permissions and limitations
+ * under the License.
+ */
+
+package internal
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/collector/client"
+	"go.opentelemetry.io/collector/component"
+	"go.opentelemetry.io/collector/consumer"
+	"go.opentelemetry.io/collector/extension"
+	"go.opentelemetry.io/collector/extension/extensionauth"
+	"go.opentelemetry.io/collector/extension/xextension/storage"
+	"go.opentelemetry.io/collector/pdata/plog"
+	"go.opentelemetry.io/collector/processor"
+)
+
+func Test_parseExtensionsConfig(t *testing.T) {
+	config := `extensions:
+  static_token_auth/b:
+    token: secret
+    attributes:
+      tenant: a
+  memory_storage:
+transform:
+  log_statements:
+    - set(log.attributes["a"], 1)`
+
+	configs, err := parseExtensionsConfig(config)
+	require.NoError(t, err)
+	require.Len(t, configs, 2)
+
+	assert.Equal(t, component.MustNewID(memoryStorageExtensionType), configs[0].id)
+	assert.Equal(t, &memoryStorageConfig{}, configs[0].config)
+
+	assert.Equal(t, component.MustNewIDWithName(staticTokenAuthExtensionType, "b"), configs[1].id)
+	assert.Equal(t, &staticTokenAuthConfig{
+		Token:      "secret",
+		Header:     "authorization",
+		Scheme:     "Bearer",
+		Attributes: map[string]string{"tenant": "a"},
+	}, configs[1].config)
+}
+
+func Test_parseExtensionsConfig_NoExtensions(t *testing.T) {
+	configs, err := parseExtensionsConfig("transform:\n  error_mode: ignore")
+	require.NoError(t, err)
+	assert.Empty(t, configs)
+}
+
+func Test_parseExtensionsConfig_Errors(t *testing.T) {
+	tests := []struct {
+		name   string
+		config string
+		err    string
+	}{
+		{
+			name:   "unknown type",
+			config: "extensions:\n  file_storage:",
+			err:    `unknown extension type "file_storage", supported types: memory_storage, static_token_auth`,
+		},
+		{
+			name:   "invalid ID",
+			config: "extensions:\n  memory_storage/:",
+			err:    `invalid extension ID "memory_storage/"`,
+		},
+		{
+			name:   "invalid configuration",
+			config: "extensions:\n  static_token_auth:\n    header: x-token",
+			err:    `invalid "static_token_auth" extension configuration: token must not be empty`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := parseExtensionsConfig(tt.config)
+			require.ErrorContains(t, err, tt.err)
+		})
+	}
+}
+
+func Test_execution_startExtensions(t *testing.T) {
+	exec := newExecution()
+	require.NoError(t, exec.startExtensions("extensions:\n  memory_storage:\n  static_token_auth:\n    token: secret"))
+
+	extensions := exec.Host().GetExtensions()
+	require.Len(t, extensions, 2)
+	assert.Implements(t, (*storage.Extension)(nil), extensions[component.MustNewID(memoryStorageExtensionType)])
+	assert.Implements(t, (*extensionauth.Server)(nil), extensions[component.MustNewID(staticTokenAuthExtensionType)])
+
+	exec.close()
+	assert.Empty(t, exec.Host().GetExtensions())
+}
+
+func Test_memoryStorageExtension(t *testing.T) {
+	ext, err := newMemoryStorageExtensionFactory().Create(context.Background(), extension.Settings{ID: component.MustNewID(memoryStorageExtensionType)}, &memoryStorageConfig{})
+	require.NoError(t, err)
+	storageExtension := ext.(storage.Extension)
+	ctx := context.Background()
+	id := component.MustNewID("transform")
+
+	client, err := storageExtension.GetClient(ctx, component.KindProcessor, id, "")
+	require.NoError(t, err)
+
+	value, err := client.Get(ctx, "key")
+	require.NoError(t, err)
+	assert.Nil(t, value)
+
+	require.NoError(t, client.Set(ctx, "key", []byte("value")))
+	value, err = client.Get(ctx, "key")
+	require.NoError(t, err)
+	assert.Equal(t, []byte("value"), value)
+
+	sameClient, err := storageExtension.GetClient(ctx, component.KindProcessor, id, "")
+	require.NoError(t, err)
+	value, err = sameClient.Get(ctx, "key")
+	require.NoError(t, err)
+	assert.Equal(t, []byte("value"), value)
+
+	otherClient, err := storageExtension.GetClient(ctx, component.KindProcessor, id, "other")
+	require.NoError(t, err)
+	value, err = otherClient.Get(ctx, "key")
+	require.NoError(t, err)
+	assert.Nil(t, value)
+
+	getOperation := storage.GetOperation("key")
+	require.NoError(t, client.Batch(ctx, storage.DeleteOperation("key"), getOperation))
+	assert.Nil(t, getOperation.Value)
+	require.NoError(t, client.Close(ctx))
+}
+
+func Test_staticTokenAuthExtension_Authenticate(t *testing.T) {
+	ext := &staticTokenAuthExtension{config: &staticTokenAuthConfig{
+		Token:      "secret",
+		Header:     "authorization",
+		Scheme:     "Bearer",
+		Attributes: map[string]string{"tenant": "a"},
+	}}
+
+	ctx, err := ext.Authenticate(context.Background(), map[string][]string{"Authorization": {"Bearer secret"}})
+	require.NoError(t, err)
+	info := client.FromContext(ctx)
+	require.NotNil(t, info.Auth)
+	assert.Equal(t, "a", info.Auth.GetAttribute("tenant"))
+
+	_, err = ext.Authenticate(context.Background(), map[string][]string{"authorization": {"Bearer secret"}})
+	require.NoError(t, err)
+
+	_, err = ext.Authenticate(context.Background(), map[string][]string{"authorization": {"Bearer other"}})
+	require.ErrorIs(t, err, errUnauthenticated)
+
+	_, err = ext.Authenticate(context.Background(), map[string][]string{})
+	require.ErrorIs(t, err, errUnauthenticated)
+}
+
+func Test_staticTokenAuthExtension_RoundTripper(t *testing.T) {
+	ext := &staticTokenAuthExtension{config: &staticTokenAuthConfig{Token: "secret", Header: "x-token"}}
+
+	var header string
+	roundTripper, err := ext.RoundTripper(roundTripperFunc(func(req *http.Request) (*http.Response, error) {
+		header = req.Header.Get("x-token")
+		return nil, errors.New("not sent")
+	}))
+	require.NoError(t, err)
+
+	req, err := http.NewRequest(http.MethodGet, "http://localhost", http.NoBody)
+	require.NoError(t, err)
+	_, _ = roundTripper.RoundTrip(req)
+	assert.Equal(t, "secret", header)
+	assert.Empty(t, req.Header.Get("x-token"))
+}
+
+func Test_Executor_ExecuteLogsWithStorageExtension(t *testing.T) {
+	storageID := component.MustNewID(memoryStorageExtensionType)
+	factory := processor.NewFactory(
+		component.MustNewType("stateful"),
+		func() component.Config { return &testProcessorConfig{} },
+		processor.WithLogs(func(_ context.Context, set processor.Settings, _ component.Config, next consumer.Logs) (processor.Logs, error) {
+			proc := &testLogsProcessor{}
+			var client storage.Client
+			proc.StartFunc = func(ctx context.Context, host component.Host) error {
+				ext, ok := host.GetExtensions()[storageID].(storage.Extension)
+				if !ok {
+					return errors.New("storage extension not found")
+				}
+				var err error
+				client, err = ext.GetClient(ctx, component.KindProcessor, set.ID, "")
+				return err
+			}
+			// Counts the consumed batches in the storage
+			proc.consume = func(ctx context.Context, ld plog.Logs) error {
+				count, err := client.Get(ctx, "count")
+				if err != nil {
+					return err
+				}
+				count = append(count, '+')
+				if err = client.Set(ctx, "count", count); err != nil {
+					return err
+				}
+				ld.ResourceLogs().At(0).Resource().Attributes().PutStr("count", string(count))
+				return next.ConsumeLogs(ctx, ld)
+			}
+			return proc, nil
+		}, component.StabilityLevelDevelopment),
+	)
+	executor := NewJSONExecutor[testProcessorConfig](newProcessorConsumer[testProcessorConfig](factory), &Metadata{})
+	payload := `{"resourceLogs":[{"scopeLogs":[{"logRecords":[{"body":{"stringValue":"log"}}]}]}]}`
+
+	config := "extensions:\n  memory_storage:\nstateful/a:\nstateful/b:"
+	output, err := executor.ExecuteLogs(config, payload)
+	require.NoError(t, err)
+
+	// Each configuration gets its own storage client
+	outputLogs, err := (&plog.JSONUnmarshaler{}).UnmarshalLogs([]byte(output.Value))
+	require.NoError(t, err)
+	count, ok := outputLogs.ResourceLogs().At(0).Resource().Attributes().Get("count")
+	require.True(t, ok)
+	assert.Equal(t, "+", count.Str())
+
+	_, err = executor.ExecuteLogs("stateful:", payload)
+	require.ErrorContains(t, err, "storage extension not found")
+}
@@ -0,0 +1,129 @@
+/*
+ * Copyright Elasticsearch B.V. and/or licensed to Elasticsearch B.V. under one
+ * or more contributor license agreements. See the NOTICE file distributed with
+ * this work for additional information regarding copyright
+ * ownership. Elasticsearch B.V. licenses this file to you under
+ * the Apache License, Version 2.0 (the "License"); you may
+ * not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *	http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing,
+ * software distributed under the License is distributed on an
+ * "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+ * KIND, either express or implied.  See the License for the
+ * specific language governing permissions and limitations
+ * under the License.
+ */
+
+package internal
+
+import (
+	"fmt"
+	"sort"
+	"sync"
+
+	"go.opentelemetry.io/collector/featuregate"
+)
+
+// featureGatesLock guards the global feature gates registry. Executions
+// toggling gates hold it exclusively, so no other execution observes their
+// gates, while the others share it.
+var featureGatesLock sync.RWMutex
+
+// FeatureGate describes a feature gate registered by the collector components
+// available in the playground.
+type FeatureGate struct {
+	ID           string `json:"id"`
+	Stage        string `json:"stage"`
+	Description  string `json:"description,omitempty"`
+	Enabled      bool   `json:"enabled"`
+	ReferenceURL string `json:"referenceURL,omitempty"`
+	FromVersion  string `json:"fromVersion,omitempty"`
+	ToVersion    string `json:"toVersion,omitempty"`
+}
+
+// FeatureGates returns the feature gates registered in the global registry,
+// sorted by ID, along with their default state.
+func FeatureGates() []FeatureGate {
+	featureGatesLock.RLock()
+	defer featureGatesLock.RUnlock()
+
+	var gates []FeatureGate
+	featuregate.GlobalRegistry().VisitAll(func(gate *featuregate.Gate) {
+		gates = append(gates, FeatureGate{
+			ID:           gate.ID(),
+			Stage:        gate.Stage().String(),
+			Description:  gate.Description(),
+			Enabled:      gate.IsEnabled(),
+			ReferenceURL: gate.ReferenceURL(),
+			FromVersion:  gate.FromVersion(),
+			ToVersion:    gate.ToVersion(),
+		})
+	})
+	sort.Slice(gates, func(i, j int) bool {
+		return gates[i].ID < gates[j].ID
+	})
+	return gates
+}
+
+// WithFeatureGates enables or disables the given feature gates, keyed by ID,
+// for the duration of the execution only.
+func WithFeatureGates(gates map[string]bool) ExecutionOption {
+	return func(settings *executionSettings) {
+		if settings.featureGates == nil {
+			settings.featureGates = make(map[string]bool, len(gates))
+		}
+		for id, enabled := range gates {
+			settings.featureGates[id] = enabled
+		}
+	}
+}
+
+// applyFeatureGates sets the feature gates requested by the execution options
+// on the global registry. The returned function restores their previous state
+// and must be called once the execution is done.
+func applyFeatureGates(options ...ExecutionOption) (func(), error) {
+	settings := newExecutionSettings(options...)
+	if len(settings.featureGates) == 0 {
+		featureGatesLock.RLock()
+		return featureGatesLock.RUnlock, nil
+	}
+
+	featureGatesLock.Lock()
+	registry := featuregate.GlobalRegistry()
+	current := map[string]bool{}
+	registry.VisitAll(func(gate *featuregate.Gate) {
+		current[gate.ID()] = gate.IsEnabled()
+	})
+
+	previous := make(map[string]bool, len(settings.featureGates))
+	restore := func() {
+		for id, enabled := range previous {
+			_ = registry.Set(id, enabled)
+		}
+		featureGatesLock.Unlock()
+	}
+
+	ids := make([]string, 0, len(settings.featureGates))
+	for id := range settings.featureGates {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+
+	for _, id := range ids {
+		enabled, ok := current[id]
+		if !ok {
+			restore()
+			return nil, fmt.Errorf("unknown feature gate %q", id)
+		}
+		if err := registry.Set(id, settings.featureGates[id]); err != nil {
+			restore()
+			return nil, err
+		}
+		previous[id] = enabled
+	}
+
+	return restore, nil
+}
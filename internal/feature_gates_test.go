@@ -0,0 +1,118 @@
+/*
+ * Copyright Elasticsearch B.V. and/or licensed to Elasticsearch B.V. under one
+ * or more contributor license agreements. See the NOTICE file distributed with
+ * this work for additional information regarding copyright
+ * ownership. Elasticsearch B.V. licenses this file to you under
+ * the Apache License, Version 2.0 (the "License"); you may
+ * not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *	http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing,
+ * software distributed under the License is distributed on an
+ * "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+ * KIND, either express or implied.  See the License for the
+ * specific language governing permissions and limitations
+ * under the License.
+ */
+
+package internal
+
+import (
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/collector/featuregate"
+)
+
+var testFeatureGate = featuregate.GlobalRegistry().MustRegister(
+	"playground.test.gate",
+	featuregate.StageAlpha,
+	featuregate.WithRegisterDescription("Feature gate used by the playground tests"),
+)
+
+func Test_FeatureGates(t *testing.T) {
+	gates := FeatureGates()
+	require.NotEmpty(t, gates)
+
+	var found *FeatureGate
+	for i, gate := range gates {
+		if i > 0 {
+			assert.Less(t, gates[i-1].ID, gate.ID)
+		}
+		if gate.ID == testFeatureGate.ID() {
+			found = &gates[i]
+		}
+	}
+	require.NotNil(t, found)
+	assert.Equal(t, "Alpha", found.Stage)
+	assert.Equal(t, "Feature gate used by the playground tests", found.Description)
+	assert.False(t, found.Enabled)
+}
+
+func Test_applyFeatureGates(t *testing.T) {
+	restore, err := applyFeatureGates(WithFeatureGates(map[string]bool{testFeatureGate.ID(): true}))
+	require.NoError(t, err)
+	assert.True(t, testFeatureGate.IsEnabled())
+
+	restore()
+	assert.False(t, testFeatureGate.IsEnabled())
+}
+
+func Test_applyFeatureGates_NoGates(t *testing.T) {
+	restore, err := applyFeatureGates()
+	require.NoError(t, err)
+	restore()
+	assert.False(t, testFeatureGate.IsEnabled())
+}
+
+func Test_applyFeatureGates_UnknownGate(t *testing.T) {
+	_, err := applyFeatureGates(WithFeatureGates(map[string]bool{
+		testFeatureGate.ID(): true,
+		"unknown.gate":       true,
+	}))
+	require.ErrorContains(t, err, `unknown feature gate "unknown.gate"`)
+	assert.False(t, testFeatureGate.IsEnabled())
+
+	// The lock is released on failures
+	restore, err := applyFeatureGates()
+	require.NoError(t, err)
+	restore()
+}
+
+func Test_applyFeatureGates_Isolation(t *testing.T) {
+	restore, err := applyFeatureGates(WithFeatureGates(map[string]bool{testFeatureGate.ID(): true}))
+	require.NoError(t, err)
+
+	var wg sync.WaitGroup
+	var enabledInOtherExecution bool
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		otherRestore, otherErr := applyFeatureGates()
+		if assert.NoError(t, otherErr) {
+			enabledInOtherExecution = testFeatureGate.IsEnabled()
+			otherRestore()
+		}
+	}()
+
+	restore()
+	wg.Wait()
+	assert.False(t, enabledInOtherExecution)
+}
+
+func Test_Executor_ExecuteLogsWithFeatureGates(t *testing.T) {
+	executor := NewTransformProcessorExecutor()
+	config := readTestData(t, transformprocessorConfig)
+	payload := readTestData(t, "logs.json")
+
+	_, err := executor.ExecuteLogs(config, payload, WithFeatureGates(map[string]bool{testFeatureGate.ID(): true}))
+	require.NoError(t, err)
+	assert.False(t, testFeatureGate.IsEnabled())
+
+	_, err = executor.ExecuteLogs(config, payload, WithFeatureGates(map[string]bool{"unknown.gate": true}))
+	require.ErrorContains(t, err, "unknown feature gate")
+}
@@ -0,0 +1,327 @@
+/*
+ * Copyright Elasticsearch B.V. and/or licensed to Elasticsearch B.V. under one
+ * or more contributor license agreements. See the NOTICE file distributed with
+ * this work for additional information regarding copyright
+ * ownership. Elasticsearch B.V. licenses this file to you under
+ * the Apache License, Version 2.0 (the "License"); you may
+ * not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *	http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing,
+ * software distributed under the License is distributed on an
+ * "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+ * KIND, either express or implied.  See the License for the
+ * specific language governing permissions and limitations
+ * under the License.
+ */
+
+package internal
+
+import (
+	"context"
+	"encoding/json"
+	"slices"
+
+	"github.com/open-telemetry/opentelemetry-collector-contrib/pkg/ottl"
+	"github.com/open-telemetry/opentelemetry-collector-contrib/processor/filterprocessor"
+	"go.opentelemetry.io/collector/component"
+)
+
+// FilterConditionResult is the outcome of a filter processor condition that
+// matched an item of the payload, or failed to evaluate on it.
+type FilterConditionResult struct {
+	StatementLocation
+	Condition string `json:"condition"`
+	Matched   bool   `json:"matched"`
+	Error     string `json:"error,omitempty"`
+}
+
+// FilteredItem reports the filter processor conditions matching an item of the
+// payload, such as a resource, a span or a data point, and whether the
+// processor drops it.
+type FilteredItem struct {
+	// ConfigKey is the key of the configuration holding the conditions, or empty
+	// if the configuration isn't keyed by component ID.
+	ConfigKey string `json:"configKey,omitempty"`
+	// Context is the OTTL context of the item, such as "resource", "scope",
+	// "log" or "spanevent".
+	Context string `json:"context"`
+	// Index holds the positions of the item's parents and of the item in the
+	// payload, from the resource down, such as [resource, scope, log record].
+	Index []int `json:"index"`
+	// Conditions are the conditions that matched the item, or failed to
+	// evaluate on it.
+	Conditions []FilterConditionResult `json:"conditions,omitempty"`
+	// Dropped tells whether the item is dropped, either because a condition
+	// matched it, because its parent is dropped, or because all its items are.
+	Dropped bool `json:"dropped"`
+}
+
+type filterProcessorDebugger struct {
+	consumer *processorConsumer[filterprocessor.Config]
+}
+
+func (f filterProcessorDebugger) DebugLogs(config, input string, options ...ExecutionOption) (*Result, error) {
+	return debugConditions(f.consumer, config, input, options, newLogsFilterSignal(f.consumer))
+}
+
+func (f filterProcessorDebugger) DebugTraces(config, input string, options ...ExecutionOption) (*Result, error) {
+	return debugConditions(f.consumer, config, input, options, newTracesFilterSignal(f.consumer))
+}
+
+func (f filterProcessorDebugger) DebugMetrics(config, input string, options ...ExecutionOption) (*Result, error) {
+	return debugConditions(f.consumer, config, input, options, newMetricsFilterSignal(f.consumer))
+}
+
+func (f filterProcessorDebugger) DebugProfiles(config, input string, options ...ExecutionOption) (*Result, error) {
+	return debugConditions(f.consumer, config, input, options, newProfilesFilterSignal(f.consumer))
+}
+
+// debugConditions reports the conditions matching every item of the payload,
+// and runs the conditions lists of the configuration one at a time, each of
+// them on the output of the previous one, returning the result of every step.
+// A configuration without conditions for the signal, such as one using the
+// legacy include and exclude properties, runs in a single step.
+func debugConditions[T any](
+	consumer *processorConsumer[filterprocessor.Config],
+	config, input string,
+	options []ExecutionOption,
+	signal filterSignal[T],
+) (*Result, error) {
+	restoreFeatureGates, err := applyFeatureGates(options...)
+	if err != nil {
+		return nil, err
+	}
+	defer restoreFeatureGates()
+
+	exec := newExecution(options...)
+	defer exec.close()
+
+	configs, err := parseExecutionConfig(exec, consumer.id, config, consumer.CreateDefaultConfig)
+	if err != nil {
+		return nil, err
+	}
+
+	locator, err := newStatementLocator(config, configs)
+	if err != nil {
+		return nil, err
+	}
+
+	if err = exec.startExtensions(config); err != nil {
+		return nil, err
+	}
+
+	payload, err := signal.unmarshal([]byte(input))
+	if err != nil {
+		return nil, err
+	}
+
+	res := &Result{}
+	res.Debug = true
+	deterministic := locator.deterministic(exec.hasFixedTime(), filterProcessorFixedTimeContexts)
+	res.Deterministic = &deterministic
+	// The steps' timings are added to the configuration parsing ones.
+	res.Timings = exec.takeTimings()
+	var results []*Result
+steps:
+	for _, cfg := range configs {
+		// The items are evaluated on the configuration's input, as the processor
+		// evaluates all the conditions lists before removing anything.
+		items, err := inspectFiltered(exec, locator, cfg, signal, payload)
+		if err != nil {
+			return nil, err
+		}
+		res.Filtered = append(res.Filtered, items...)
+
+		groups := slices.DeleteFunc(slices.Clone(signal.groups), func(group filterGroup) bool {
+			return len(*group.conditions(cfg.Value)) == 0
+		})
+		stepConfigs := []parsedConfig[filterprocessor.Config]{cfg}
+		if len(groups) > 0 {
+			stepConfigs = stepConfigs[:0]
+			for _, group := range groups {
+				stepConfigs = append(stepConfigs, filterStep(cfg, signal, group))
+			}
+		}
+
+		for i, stepConfig := range stepConfigs {
+			result, err := newExecutionResult(exec, signal.marshal, func() (T, error) {
+				payload, err = signal.consume(exec, stepConfig, payload)
+				return payload, err
+			})
+			if err != nil {
+				return result, err
+			}
+			if len(groups) > 0 {
+				if location, ok := locator.locateIndex(cfg.Key, signal.configKey+"."+groups[i].name, 0); ok {
+					result.Line = location.Line
+				}
+			}
+			result.Warnings = locator.warnings(consumer.id, result.LogEntries)
+			results = append(results, result)
+			res.Timings.add(result.Timings)
+			if result.Cancelled {
+				res.Cancelled = true
+				res.CancellationReason = result.CancellationReason
+				break steps
+			}
+		}
+	}
+
+	marshal, err := json.Marshal(results)
+	if err != nil {
+		return nil, err
+	}
+
+	res.Value = string(marshal)
+	return res, nil
+}
+
+// inspectFiltered reports the items of the payload the processor running cfg
+// drops, told by diffing its input and output, along with the conditions that
+// matched them or failed to evaluate on them. Configurations without conditions
+// for the signal aren't inspected.
+func inspectFiltered[T any](exec *execution, locator *statementLocator, cfg parsedConfig[filterprocessor.Config], signal filterSignal[T], payload T) ([]FilteredItem, error) {
+	if !slices.ContainsFunc(signal.groups, func(group filterGroup) bool {
+		return len(*group.conditions(cfg.Value)) > 0
+	}) {
+		return nil, nil
+	}
+
+	consume, release, err := signal.start(exec, cfg)
+	if err != nil {
+		return nil, err
+	}
+	output, err := consume(signal.copyPayload(payload))
+	release()
+	// The run only tells the dropped items, its telemetry isn't reported.
+	exec.discardTelemetry()
+	exec.takeTimings()
+	if err != nil {
+		return nil, err
+	}
+
+	inputItems, err := signal.items(payload)
+	if err != nil {
+		return nil, err
+	}
+	outputItems, err := signal.items(output)
+	if err != nil {
+		return nil, err
+	}
+	dropped := map[string]bool{}
+	droppedItems(inputItems, outputItems, dropped)
+	return signal.inspect(exec, locator, cfg, payload, dropped)
+}
+
+// filterStep returns the configuration of cfg evaluating the given conditions
+// list only.
+func filterStep[T any](cfg parsedConfig[filterprocessor.Config], signal filterSignal[T], group filterGroup) parsedConfig[filterprocessor.Config] {
+	// The configuration is copied rather than cloned, as it holds the functions
+	// the processor parses the conditions with.
+	cp := *cfg.Value
+	for _, other := range signal.groups {
+		if other.name != group.name {
+			*other.conditions(&cp) = nil
+		}
+	}
+	return parsedConfig[filterprocessor.Config]{Key: cfg.Key, Value: &cp}
+}
+
+// filterConditions holds the conditions of a filter processor conditions list,
+// parsed to be evaluated one at a time.
+type filterConditions[K any] struct {
+	texts      []string
+	locations  []StatementLocation
+	conditions []*ottl.Condition[K]
+}
+
+// parseFilterConditions parses the conditions of the list at the given path
+// with the processor's default functions, plus the Now() honoring WithFixedTime.
+func parseFilterConditions[K any](
+	exec *execution,
+	locator *statementLocator,
+	configKey, path string,
+	texts []string,
+	newParser func(map[string]ottl.Factory[K], component.TelemetrySettings, ...ottl.Option[K]) (ottl.Parser[K], error),
+	functions []ottl.Factory[K],
+) (filterConditions[K], error) {
+	if len(texts) == 0 {
+		return filterConditions[K]{}, nil
+	}
+
+	parser, err := newParser(ottl.CreateFactoryMap(withFixedTimeNow(ottl.CreateFactoryMap(functions...))...), exec.TelemetrySettings())
+	if err != nil {
+		return filterConditions[K]{}, err
+	}
+	conditions, err := parser.ParseConditions(texts)
+	if err != nil {
+		return filterConditions[K]{}, err
+	}
+
+	locations := make([]StatementLocation, len(texts))
+	for i := range texts {
+		locations[i], _ = locator.locateIndex(configKey, path, i)
+	}
+	return filterConditions[K]{texts: texts, locations: locations, conditions: conditions}, nil
+}
+
+func (c filterConditions[K]) empty() bool {
+	return len(c.conditions) == 0
+}
+
+// evaluate evaluates every condition on the item's context, unless skip is set,
+// and returns the ones that matched it or failed to evaluate.
+func (c filterConditions[K]) evaluate(ctx context.Context, skip bool, newContext func() K) []FilterConditionResult {
+	if skip || c.empty() {
+		return nil
+	}
+
+	tCtx := newContext()
+	if closer, ok := any(tCtx).(interface{ Close() }); ok {
+		defer closer.Close()
+	}
+
+	var results []FilterConditionResult
+	for i, condition := range c.conditions {
+		matched, err := condition.Eval(ctx, tCtx)
+		if !matched && err == nil {
+			continue
+		}
+		result := FilterConditionResult{StatementLocation: c.locations[i], Condition: c.texts[i], Matched: matched}
+		if err != nil {
+			result.Error = err.Error()
+		}
+		results = append(results, result)
+	}
+	return results
+}
+
+// filteredItems builds the report of a payload's items, from the resources down.
+type filteredItems struct {
+	configKey string
+	// dropped holds the indexes of the items missing from the processor output.
+	dropped map[string]bool
+	items   []FilteredItem
+}
+
+// add appends an item, and returns whether one of the conditions matched it.
+func (f *filteredItems) add(context string, conditions []FilterConditionResult, index ...int) bool {
+	f.items = append(f.items, FilteredItem{
+		ConfigKey:  f.configKey,
+		Context:    context,
+		Index:      index,
+		Conditions: conditions,
+		Dropped:    f.dropped[itemIndexKey(index)],
+	})
+	return slices.ContainsFunc(conditions, func(c FilterConditionResult) bool {
+		return c.Matched
+	})
+}
+
+func NewFilterProcessorDebugger() Debugger {
+	consumer := newFilterProcessorConsumer()
+	return &filterProcessorDebugger{consumer}
+}
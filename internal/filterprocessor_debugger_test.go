@@ -0,0 +1,251 @@
+/*
+ * Copyright Elasticsearch B.V. and/or licensed to Elasticsearch B.V. under one
+ * or more contributor license agreements. See the NOTICE file distributed with
+ * this work for additional information regarding copyright
+ * ownership. Elasticsearch B.V. licenses this file to you under
+ * the Apache License, Version 2.0 (the "License"); you may
+ * not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *	http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing,
+ * software distributed under the License is distributed on an
+ * "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+ * KIND, either express or implied.  See the License for the
+ * specific language governing permissions and limitations
+ * under the License.
+ */
+
+package internal
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_NewFilterProcessorDebugger(t *testing.T) {
+	debugger := NewFilterProcessorDebugger()
+	require.NotNil(t, debugger)
+
+	_, ok := debugger.(*filterProcessorDebugger)
+	require.True(t, ok)
+}
+
+func Test_NewFilterProcessorExecutor_Debuggable(t *testing.T) {
+	executor := NewFilterProcessorExecutor()
+	assert.True(t, executor.Metadata().Debuggable)
+
+	debugger, err := executor.(DebuggableExecutor).Debugger()
+	require.NoError(t, err)
+	assert.IsType(t, &filterProcessorDebugger{}, debugger)
+}
+
+func Test_filterProcessorDebugger_DebugLogs(t *testing.T) {
+	debugger := NewFilterProcessorDebugger()
+	config := `filter:
+  logs:
+    resource:
+      - resource.attributes["drop"] == true
+    log_record:
+      - severity_number == 0
+      - IsMatch(body, "debug")`
+	payload := `{"resourceLogs":[` +
+		`{"resource":{"attributes":[{"key":"drop","value":{"boolValue":true}}]},"scopeLogs":[{"logRecords":[{"body":{"stringValue":"debug"}}]}]},` +
+		`{"scopeLogs":[{"logRecords":[{"severityNumber":9,"body":{"stringValue":"info"}},{"severityNumber":9,"body":{"stringValue":"debug"}}]},` +
+		`{"logRecords":[{"body":{"stringValue":"unspecified"}}]}]}]}`
+
+	result, err := debugger.DebugLogs(config, payload)
+	require.NoError(t, err)
+	assert.True(t, result.Debug)
+
+	resourceCondition := FilterConditionResult{
+		StatementLocation: StatementLocation{ConfigKey: "filter", Path: "logs.resource", Index: 0, Line: 4},
+		Condition:         `resource.attributes["drop"] == true`,
+		Matched:           true,
+	}
+	severityCondition := FilterConditionResult{
+		StatementLocation: StatementLocation{ConfigKey: "filter", Path: "logs.log_record", Index: 0, Line: 6},
+		Condition:         "severity_number == 0",
+		Matched:           true,
+	}
+	bodyCondition := FilterConditionResult{
+		StatementLocation: StatementLocation{ConfigKey: "filter", Path: "logs.log_record", Index: 1, Line: 7},
+		Condition:         `IsMatch(body, "debug")`,
+		Matched:           true,
+	}
+	assert.Equal(t, []FilteredItem{
+		{ConfigKey: "filter", Context: "resource", Index: []int{0}, Conditions: []FilterConditionResult{resourceCondition}, Dropped: true},
+		// The items of a dropped resource aren't evaluated
+		{ConfigKey: "filter", Context: "scope", Index: []int{0, 0}, Dropped: true},
+		{ConfigKey: "filter", Context: "log", Index: []int{0, 0, 0}, Dropped: true},
+		{ConfigKey: "filter", Context: "resource", Index: []int{1}, Dropped: false},
+		{ConfigKey: "filter", Context: "scope", Index: []int{1, 0}, Dropped: false},
+		{ConfigKey: "filter", Context: "log", Index: []int{1, 0, 0}, Dropped: false},
+		{ConfigKey: "filter", Context: "log", Index: []int{1, 0, 1}, Conditions: []FilterConditionResult{bodyCondition}, Dropped: true},
+		// A scope left without log records is dropped
+		{ConfigKey: "filter", Context: "scope", Index: []int{1, 1}, Dropped: true},
+		{ConfigKey: "filter", Context: "log", Index: []int{1, 1, 0}, Conditions: []FilterConditionResult{severityCondition}, Dropped: true},
+	}, result.Filtered)
+
+	var debugResults []*Result
+	require.NoError(t, json.Unmarshal([]byte(result.Value), &debugResults))
+	require.Len(t, debugResults, 2)
+	// Each conditions list runs on the output of the previous one
+	assert.Equal(t, int64(4), debugResults[0].Line)
+	assert.NotContains(t, debugResults[0].Value, `"drop"`)
+	assert.Contains(t, debugResults[0].Value, `"unspecified"`)
+	assert.Equal(t, int64(6), debugResults[1].Line)
+	assert.Contains(t, debugResults[1].Value, `"info"`)
+	assert.NotContains(t, debugResults[1].Value, `"debug"`)
+	assert.NotContains(t, debugResults[1].Value, `"unspecified"`)
+}
+
+func Test_filterProcessorDebugger_DebugTraces(t *testing.T) {
+	debugger := NewFilterProcessorDebugger()
+	config := `traces:
+  span:
+    - name == "drop"
+  spanevent:
+    - name == "drop"`
+	payload := `{"resourceSpans":[{"scopeSpans":[{"spans":[` +
+		`{"name":"drop","events":[{"name":"drop"}]},` +
+		`{"name":"keep","events":[{"name":"keep"},{"name":"drop"}]}]}]}]}`
+
+	result, err := debugger.DebugTraces(config, payload)
+	require.NoError(t, err)
+
+	spanEventCondition := FilterConditionResult{
+		StatementLocation: StatementLocation{Path: "traces.spanevent", Index: 0, Line: 5},
+		Condition:         `name == "drop"`,
+		Matched:           true,
+	}
+	assert.Equal(t, []FilteredItem{
+		{Context: "resource", Index: []int{0}},
+		{Context: "scope", Index: []int{0, 0}},
+		{Context: "span", Index: []int{0, 0, 0}, Conditions: []FilterConditionResult{{
+			StatementLocation: StatementLocation{Path: "traces.span", Index: 0, Line: 3},
+			Condition:         `name == "drop"`,
+			Matched:           true,
+		}}, Dropped: true},
+		{Context: "spanevent", Index: []int{0, 0, 0, 0}, Dropped: true},
+		{Context: "span", Index: []int{0, 0, 1}},
+		{Context: "spanevent", Index: []int{0, 0, 1, 0}},
+		{Context: "spanevent", Index: []int{0, 0, 1, 1}, Conditions: []FilterConditionResult{spanEventCondition}, Dropped: true},
+	}, result.Filtered)
+
+	var debugResults []*Result
+	require.NoError(t, json.Unmarshal([]byte(result.Value), &debugResults))
+	require.Len(t, debugResults, 2)
+	assert.Equal(t, []int64{3, 5}, []int64{debugResults[0].Line, debugResults[1].Line})
+	assert.Contains(t, debugResults[0].Value, `"events":[{"name":"keep"},{"name":"drop"}]`)
+	assert.Contains(t, debugResults[1].Value, `"events":[{"name":"keep"}]`)
+}
+
+func Test_filterProcessorDebugger_DebugMetrics(t *testing.T) {
+	debugger := NewFilterProcessorDebugger()
+	config := `metrics:
+  datapoint:
+    - value_int == 1`
+	payload := `{"resourceMetrics":[{"scopeMetrics":[{"metrics":[` +
+		`{"name":"emptied","gauge":{"dataPoints":[{"asInt":"1"}]}},` +
+		`{"name":"kept","sum":{"dataPoints":[{"asInt":"2"},{"asInt":"1"}]}}]}]}]}`
+
+	result, err := debugger.DebugMetrics(config, payload)
+	require.NoError(t, err)
+
+	condition := []FilterConditionResult{{
+		StatementLocation: StatementLocation{Path: "metrics.datapoint", Index: 0, Line: 3},
+		Condition:         "value_int == 1",
+		Matched:           true,
+	}}
+	assert.Equal(t, []FilteredItem{
+		{Context: "resource", Index: []int{0}},
+		{Context: "scope", Index: []int{0, 0}},
+		// A metric left without data points is dropped
+		{Context: "metric", Index: []int{0, 0, 0}, Dropped: true},
+		{Context: "datapoint", Index: []int{0, 0, 0, 0}, Conditions: condition, Dropped: true},
+		{Context: "metric", Index: []int{0, 0, 1}},
+		{Context: "datapoint", Index: []int{0, 0, 1, 0}},
+		{Context: "datapoint", Index: []int{0, 0, 1, 1}, Conditions: condition, Dropped: true},
+	}, result.Filtered)
+
+	var debugResults []*Result
+	require.NoError(t, json.Unmarshal([]byte(result.Value), &debugResults))
+	require.Len(t, debugResults, 1)
+	assert.NotContains(t, debugResults[0].Value, `"emptied"`)
+	assert.Contains(t, debugResults[0].Value, `"kept"`)
+}
+
+func Test_filterProcessorDebugger_DebugProfiles(t *testing.T) {
+	debugger := NewFilterProcessorDebugger()
+	config := `profiles:
+  resource:
+    - resource.attributes["service.name"] == "unknown"`
+	payload := readTestData(t, "profiles.json")
+
+	result, err := debugger.DebugProfiles(config, payload)
+	require.NoError(t, err)
+	require.NotEmpty(t, result.Filtered)
+	assert.Equal(t, "resource", result.Filtered[0].Context)
+	for _, item := range result.Filtered {
+		assert.False(t, item.Dropped)
+	}
+
+	var debugResults []*Result
+	require.NoError(t, json.Unmarshal([]byte(result.Value), &debugResults))
+	require.Len(t, debugResults, 1)
+	assert.Equal(t, int64(3), debugResults[0].Line)
+}
+
+func Test_filterProcessorDebugger_DebugLogs_ConditionError(t *testing.T) {
+	debugger := NewFilterProcessorDebugger()
+	config := `error_mode: ignore
+logs:
+  log_record:
+    - ParseJSON(body)["level"] == "debug"`
+	payload := `{"resourceLogs":[{"scopeLogs":[{"logRecords":[{"body":{"stringValue":"not json"}}]}]}]}`
+
+	result, err := debugger.DebugLogs(config, payload)
+	require.NoError(t, err)
+	require.Len(t, result.Filtered, 3)
+
+	record := result.Filtered[2]
+	assert.False(t, record.Dropped)
+	require.Len(t, record.Conditions, 1)
+	assert.False(t, record.Conditions[0].Matched)
+	assert.NotEmpty(t, record.Conditions[0].Error)
+}
+
+func Test_filterProcessorDebugger_DebugLogs_LegacyConfig(t *testing.T) {
+	debugger := NewFilterProcessorDebugger()
+	config := `logs:
+  exclude:
+    match_type: strict
+    bodies:
+      - drop`
+	payload := `{"resourceLogs":[{"scopeLogs":[{"logRecords":[{"body":{"stringValue":"drop"}},{"body":{"stringValue":"keep"}}]}]}]}`
+
+	result, err := debugger.DebugLogs(config, payload)
+	require.NoError(t, err)
+	assert.Empty(t, result.Filtered)
+
+	// Configurations without conditions run in a single step
+	var debugResults []*Result
+	require.NoError(t, json.Unmarshal([]byte(result.Value), &debugResults))
+	require.Len(t, debugResults, 1)
+	assert.Equal(t, int64(0), debugResults[0].Line)
+	assert.NotContains(t, debugResults[0].Value, `"drop"`)
+	assert.Contains(t, debugResults[0].Value, `"keep"`)
+}
+
+func Test_filterProcessorDebugger_DebugLogs_InvalidPayload(t *testing.T) {
+	debugger := NewFilterProcessorDebugger()
+	config := readTestData(t, filterprocessorConfig)
+
+	_, err := debugger.DebugLogs(config, "invalid json")
+	assert.Error(t, err)
+}
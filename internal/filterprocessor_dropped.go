@@ -0,0 +1,254 @@
+/*
+ * Copyright Elasticsearch B.V. and/or licensed to Elasticsearch B.V. under one
+ * or more contributor license agreements. See the NOTICE file distributed with
+ * this work for additional information regarding copyright
+ * ownership. Elasticsearch B.V. licenses this file to you under
+ * the Apache License, Version 2.0 (the "License"); you may
+ * not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *	http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing,
+ * software distributed under the License is distributed on an
+ * "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+ * KIND, either express or implied.  See the License for the
+ * specific language governing permissions and limitations
+ * under the License.
+ */
+
+package internal
+
+import (
+	"errors"
+	"fmt"
+	"iter"
+
+	"go.opentelemetry.io/collector/pdata/pcommon"
+	"go.opentelemetry.io/collector/pdata/plog"
+	"go.opentelemetry.io/collector/pdata/pmetric"
+	"go.opentelemetry.io/collector/pdata/pprofile"
+	"go.opentelemetry.io/collector/pdata/ptrace"
+)
+
+// payloadItem is an item of a payload, such as a resource or a log record, and
+// the items it holds. Its key holds its own fields, so identical items have
+// the same key.
+type payloadItem struct {
+	index []int
+	key   string
+	items []payloadItem
+}
+
+// droppedItems records in dropped the indexes of the input items missing from
+// the output. The filter processor only removes items, so its output holds the
+// items it kept in their input order.
+func droppedItems(input, output []payloadItem, dropped map[string]bool) {
+	j := 0
+	for _, item := range input {
+		if j < len(output) && item.holds(output[j]) {
+			droppedItems(item.items, output[j].items, dropped)
+			j++
+			continue
+		}
+		item.drop(dropped)
+	}
+}
+
+// holds reports whether other is the item, with some of its items removed.
+func (i payloadItem) holds(other payloadItem) bool {
+	if i.key != other.key {
+		return false
+	}
+	j := 0
+	for _, item := range i.items {
+		if j < len(other.items) && item.holds(other.items[j]) {
+			j++
+		}
+	}
+	return j == len(other.items)
+}
+
+func (i payloadItem) drop(dropped map[string]bool) {
+	dropped[itemIndexKey(i.index)] = true
+	for _, item := range i.items {
+		item.drop(dropped)
+	}
+}
+
+func itemIndexKey(index []int) string {
+	return fmt.Sprint(index)
+}
+
+// itemKeys builds the keys of the payload items, from their protobuf encoding,
+// recording the first encoding error.
+type itemKeys struct {
+	err error
+}
+
+func (k *itemKeys) key(data []byte, err error) string {
+	k.err = errors.Join(k.err, err)
+	return string(data)
+}
+
+func (k *itemKeys) logs(ld plog.Logs) string {
+	return k.key((&plog.ProtoMarshaler{}).MarshalLogs(ld))
+}
+
+func (k *itemKeys) traces(td ptrace.Traces) string {
+	return k.key((&ptrace.ProtoMarshaler{}).MarshalTraces(td))
+}
+
+func (k *itemKeys) metrics(md pmetric.Metrics) string {
+	return k.key((&pmetric.ProtoMarshaler{}).MarshalMetrics(md))
+}
+
+func (k *itemKeys) profiles(pd pprofile.Profiles) string {
+	return k.key((&pprofile.ProtoMarshaler{}).MarshalProfiles(pd))
+}
+
+func (k *itemKeys) resource(resource pcommon.Resource, schemaURL string) string {
+	ld := plog.NewLogs()
+	rl := ld.ResourceLogs().AppendEmpty()
+	resource.CopyTo(rl.Resource())
+	rl.SetSchemaUrl(schemaURL)
+	return k.logs(ld)
+}
+
+func (k *itemKeys) scope(scope pcommon.InstrumentationScope, schemaURL string) string {
+	ld := plog.NewLogs()
+	sl := ld.ResourceLogs().AppendEmpty().ScopeLogs().AppendEmpty()
+	scope.CopyTo(sl.Scope())
+	sl.SetSchemaUrl(schemaURL)
+	return k.logs(ld)
+}
+
+func logsItems(ld plog.Logs) ([]payloadItem, error) {
+	var keys itemKeys
+	var resources []payloadItem
+	for r, rl := range ld.ResourceLogs().All() {
+		resource := payloadItem{index: []int{r}, key: keys.resource(rl.Resource(), rl.SchemaUrl())}
+		for s, sl := range rl.ScopeLogs().All() {
+			scope := payloadItem{index: []int{r, s}, key: keys.scope(sl.Scope(), sl.SchemaUrl())}
+			for l, lr := range sl.LogRecords().All() {
+				record := plog.NewLogs()
+				lr.CopyTo(record.ResourceLogs().AppendEmpty().ScopeLogs().AppendEmpty().LogRecords().AppendEmpty())
+				scope.items = append(scope.items, payloadItem{index: []int{r, s, l}, key: keys.logs(record)})
+			}
+			resource.items = append(resource.items, scope)
+		}
+		resources = append(resources, resource)
+	}
+	return resources, keys.err
+}
+
+func tracesItems(td ptrace.Traces) ([]payloadItem, error) {
+	var keys itemKeys
+	var resources []payloadItem
+	for r, rs := range td.ResourceSpans().All() {
+		resource := payloadItem{index: []int{r}, key: keys.resource(rs.Resource(), rs.SchemaUrl())}
+		for s, ss := range rs.ScopeSpans().All() {
+			scope := payloadItem{index: []int{r, s}, key: keys.scope(ss.Scope(), ss.SchemaUrl())}
+			for sp, span := range ss.Spans().All() {
+				// The span's key holds its fields but its events, which are keyed on
+				// their own.
+				spanTraces := ptrace.NewTraces()
+				spanCopy := spanTraces.ResourceSpans().AppendEmpty().ScopeSpans().AppendEmpty().Spans().AppendEmpty()
+				span.CopyTo(spanCopy)
+				spanCopy.Events().RemoveIf(func(ptrace.SpanEvent) bool { return true })
+				spanItem := payloadItem{index: []int{r, s, sp}, key: keys.traces(spanTraces)}
+				for e, event := range span.Events().All() {
+					eventTraces := ptrace.NewTraces()
+					event.CopyTo(eventTraces.ResourceSpans().AppendEmpty().ScopeSpans().AppendEmpty().Spans().AppendEmpty().Events().AppendEmpty())
+					spanItem.items = append(spanItem.items, payloadItem{index: []int{r, s, sp, e}, key: keys.traces(eventTraces)})
+				}
+				scope.items = append(scope.items, spanItem)
+			}
+			resource.items = append(resource.items, scope)
+		}
+		resources = append(resources, resource)
+	}
+	return resources, keys.err
+}
+
+func metricsItems(md pmetric.Metrics) ([]payloadItem, error) {
+	var keys itemKeys
+	var resources []payloadItem
+	for r, rm := range md.ResourceMetrics().All() {
+		resource := payloadItem{index: []int{r}, key: keys.resource(rm.Resource(), rm.SchemaUrl())}
+		for s, sm := range rm.ScopeMetrics().All() {
+			scope := payloadItem{index: []int{r, s}, key: keys.scope(sm.Scope(), sm.SchemaUrl())}
+			for m, metric := range sm.Metrics().All() {
+				scope.items = append(scope.items, metricItem(&keys, metric, []int{r, s, m}))
+			}
+			resource.items = append(resource.items, scope)
+		}
+		resources = append(resources, resource)
+	}
+	return resources, keys.err
+}
+
+// metricItem returns the item of the metric, keyed by its fields but its data
+// points, which are keyed on their own.
+func metricItem(keys *itemKeys, metric pmetric.Metric, index []int) payloadItem {
+	md := pmetric.NewMetrics()
+	metricCopy := md.ResourceMetrics().AppendEmpty().ScopeMetrics().AppendEmpty().Metrics().AppendEmpty()
+	metric.CopyTo(metricCopy)
+
+	var dataPoints []payloadItem
+	switch metric.Type() {
+	case pmetric.MetricTypeSum:
+		dataPoints = dataPointItems(keys, md, metric.Sum().DataPoints(), metricCopy.Sum().DataPoints(), index)
+	case pmetric.MetricTypeGauge:
+		dataPoints = dataPointItems(keys, md, metric.Gauge().DataPoints(), metricCopy.Gauge().DataPoints(), index)
+	case pmetric.MetricTypeHistogram:
+		dataPoints = dataPointItems(keys, md, metric.Histogram().DataPoints(), metricCopy.Histogram().DataPoints(), index)
+	case pmetric.MetricTypeExponentialHistogram:
+		dataPoints = dataPointItems(keys, md, metric.ExponentialHistogram().DataPoints(), metricCopy.ExponentialHistogram().DataPoints(), index)
+	case pmetric.MetricTypeSummary:
+		dataPoints = dataPointItems(keys, md, metric.Summary().DataPoints(), metricCopy.Summary().DataPoints(), index)
+	}
+	return payloadItem{index: index, key: keys.metrics(md), items: dataPoints}
+}
+
+// dataPointSlice is implemented by the slices of every type of data points.
+type dataPointSlice[D any] interface {
+	All() iter.Seq2[int, D]
+	AppendEmpty() D
+	RemoveIf(func(D) bool)
+}
+
+// dataPointItems returns the items of the data points, each keyed by the metric
+// holding it only, and leaves the metric in md without data points.
+func dataPointItems[D interface{ CopyTo(D) }](keys *itemKeys, md pmetric.Metrics, dataPoints, metricDataPoints dataPointSlice[D], index []int) []payloadItem {
+	removeAll := func(D) bool { return true }
+	var items []payloadItem
+	for d, dataPoint := range dataPoints.All() {
+		metricDataPoints.RemoveIf(removeAll)
+		dataPoint.CopyTo(metricDataPoints.AppendEmpty())
+		items = append(items, payloadItem{index: append(index[:len(index):len(index)], d), key: keys.metrics(md)})
+	}
+	metricDataPoints.RemoveIf(removeAll)
+	return items
+}
+
+func profilesItems(pd pprofile.Profiles) ([]payloadItem, error) {
+	var keys itemKeys
+	var resources []payloadItem
+	for r, rp := range pd.ResourceProfiles().All() {
+		resource := payloadItem{index: []int{r}, key: keys.resource(rp.Resource(), rp.SchemaUrl())}
+		for s, sp := range rp.ScopeProfiles().All() {
+			scope := payloadItem{index: []int{r, s}, key: keys.scope(sp.Scope(), sp.SchemaUrl())}
+			for p, profile := range sp.Profiles().All() {
+				// The profiles share the payload's dictionary, which the processor
+				// doesn't change, so they're keyed by their own fields only.
+				profiles := pprofile.NewProfiles()
+				profile.CopyTo(profiles.ResourceProfiles().AppendEmpty().ScopeProfiles().AppendEmpty().Profiles().AppendEmpty())
+				scope.items = append(scope.items, payloadItem{index: []int{r, s, p}, key: keys.profiles(profiles)})
+			}
+			resource.items = append(resource.items, scope)
+		}
+		resources = append(resources, resource)
+	}
+	return resources, keys.err
+}
@@ -0,0 +1,104 @@
+/*
+ * Copyright Elasticsearch B.V. and/or licensed to Elasticsearch B.V. under one
+ * or more contributor license agreements. See the NOTICE file distributed with
+ * this work for additional information regarding copyright
+ * ownership. Elasticsearch B.V. licenses this file to you under
+ * the Apache License, Version 2.0 (the "License"); you may
+ * not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *	http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing,
+ * software distributed under the License is distributed on an
+ * "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+ * KIND, either express or implied.  See the License for the
+ * specific language governing permissions and limitations
+ * under the License.
+ */
+
+package internal
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/collector/pdata/plog"
+	"go.opentelemetry.io/collector/pdata/pmetric"
+)
+
+func Test_droppedItems(t *testing.T) {
+	input := []payloadItem{{index: []int{0}, key: "resource", items: []payloadItem{
+		{index: []int{0, 0}, key: "scope", items: []payloadItem{{index: []int{0, 0, 0}, key: "a"}}},
+		{index: []int{0, 1}, key: "scope", items: []payloadItem{{index: []int{0, 1, 0}, key: "b"}, {index: []int{0, 1, 1}, key: "a"}}},
+	}}}
+	output := []payloadItem{{key: "resource", items: []payloadItem{
+		{key: "scope", items: []payloadItem{{key: "b"}}},
+	}}}
+
+	dropped := map[string]bool{}
+	droppedItems(input, output, dropped)
+	// Identical scopes are told apart by the items they hold
+	assert.Equal(t, map[string]bool{
+		itemIndexKey([]int{0, 0}):    true,
+		itemIndexKey([]int{0, 0, 0}): true,
+		itemIndexKey([]int{0, 1, 1}): true,
+	}, dropped)
+}
+
+func Test_droppedItems_Logs(t *testing.T) {
+	input := plog.NewLogs()
+	records := input.ResourceLogs().AppendEmpty().ScopeLogs().AppendEmpty().LogRecords()
+	records.AppendEmpty().Body().SetStr("a")
+	records.AppendEmpty().Body().SetStr("b")
+	records.AppendEmpty().Body().SetStr("a")
+
+	output := plog.NewLogs()
+	input.CopyTo(output)
+	output.ResourceLogs().At(0).ScopeLogs().At(0).LogRecords().RemoveIf(func(lr plog.LogRecord) bool {
+		return lr.Body().Str() == "b"
+	})
+
+	inputItems, err := logsItems(input)
+	require.NoError(t, err)
+	outputItems, err := logsItems(output)
+	require.NoError(t, err)
+	dropped := map[string]bool{}
+	droppedItems(inputItems, outputItems, dropped)
+	assert.Equal(t, map[string]bool{itemIndexKey([]int{0, 0, 1}): true}, dropped)
+}
+
+func Test_droppedItems_Metrics(t *testing.T) {
+	input := pmetric.NewMetrics()
+	metrics := input.ResourceMetrics().AppendEmpty().ScopeMetrics().AppendEmpty().Metrics()
+	sum := metrics.AppendEmpty()
+	sum.SetName("sum")
+	sum.SetEmptySum().DataPoints().AppendEmpty().SetIntValue(1)
+	sum.Sum().DataPoints().AppendEmpty().SetIntValue(2)
+	gauge := metrics.AppendEmpty()
+	gauge.SetName("gauge")
+	gauge.SetEmptyGauge().DataPoints().AppendEmpty().SetIntValue(1)
+
+	output := pmetric.NewMetrics()
+	input.CopyTo(output)
+	output.ResourceMetrics().At(0).ScopeMetrics().At(0).Metrics().At(0).Sum().DataPoints().RemoveIf(func(dp pmetric.NumberDataPoint) bool {
+		return dp.IntValue() == 1
+	})
+	output.ResourceMetrics().At(0).ScopeMetrics().At(0).Metrics().RemoveIf(func(metric pmetric.Metric) bool {
+		return metric.Name() == "gauge"
+	})
+
+	inputItems, err := metricsItems(input)
+	require.NoError(t, err)
+	require.Len(t, inputItems[0].items[0].items[0].items, 2)
+	outputItems, err := metricsItems(output)
+	require.NoError(t, err)
+	dropped := map[string]bool{}
+	droppedItems(inputItems, outputItems, dropped)
+	assert.Equal(t, map[string]bool{
+		itemIndexKey([]int{0, 0, 0, 0}): true,
+		itemIndexKey([]int{0, 0, 1}):    true,
+		itemIndexKey([]int{0, 0, 1, 0}): true,
+	}, dropped)
+}
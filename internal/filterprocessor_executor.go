@@ -20,9 +20,25 @@
 package internal
 
 import (
+	"maps"
+
+	"github.com/open-telemetry/opentelemetry-collector-contrib/pkg/ottl"
+	"github.com/open-telemetry/opentelemetry-collector-contrib/pkg/ottl/contexts/ottldatapoint"
+	"github.com/open-telemetry/opentelemetry-collector-contrib/pkg/ottl/contexts/ottllog"
+	"github.com/open-telemetry/opentelemetry-collector-contrib/pkg/ottl/contexts/ottlprofile"
+	"github.com/open-telemetry/opentelemetry-collector-contrib/pkg/ottl/contexts/ottlresource"
+	"github.com/open-telemetry/opentelemetry-collector-contrib/pkg/ottl/contexts/ottlspan"
+	"github.com/open-telemetry/opentelemetry-collector-contrib/pkg/ottl/contexts/ottlspanevent"
+	"github.com/open-telemetry/opentelemetry-collector-contrib/pkg/ottl/ottlfuncs"
 	"github.com/open-telemetry/opentelemetry-collector-contrib/processor/filterprocessor"
 )
 
+// filterProcessorFixedTimeContexts are the OTTL contexts in which the
+// filterprocessor only registers the standard converters, plus IsRootSpan for
+// spans, which can therefore be replaced to pin Now(). The metric context also
+// holds the processor's own functions.
+var filterProcessorFixedTimeContexts = []string{"resource", "log", "datapoint", "span", "spanevent", "profile"}
+
 var filterProcessorConfigExamples = []ConfigExample{
 	{
 		Name:   "Drop specific metric and value",
@@ -59,11 +75,30 @@ var filterProcessorConfigExamples = []ConfigExample{
 	},
 }
 
+// newFilterProcessorConsumer creates the Consumer running the [filterprocessor].
+func newFilterProcessorConsumer() *processorConsumer[filterprocessor.Config] {
+	spanFunctions := ottlfuncs.StandardConverters[*ottlspan.TransformContext]()
+	maps.Copy(spanFunctions, ottl.CreateFactoryMap(ottlfuncs.NewIsRootSpanFactoryNew()))
+
+	return newProcessorConsumer[filterprocessor.Config](
+		filterprocessor.NewFactory(),
+		withFixedTimeFactory[filterprocessor.Config](filterprocessor.NewFactoryWithOptions(
+			filterprocessor.WithResourceFunctionsNew(withFixedTimeNow(ottlfuncs.StandardConverters[*ottlresource.TransformContext]())),
+			filterprocessor.WithLogFunctionsNew(withFixedTimeNow(ottlfuncs.StandardConverters[*ottllog.TransformContext]())),
+			filterprocessor.WithDataPointFunctionsNew(withFixedTimeNow(ottlfuncs.StandardConverters[*ottldatapoint.TransformContext]())),
+			filterprocessor.WithSpanFunctionsNew(withFixedTimeNow(spanFunctions)),
+			filterprocessor.WithSpanEventFunctionsNew(withFixedTimeNow(ottlfuncs.StandardConverters[*ottlspanevent.TransformContext]())),
+			filterprocessor.WithProfileFunctions(withFixedTimeNow(ottlfuncs.StandardConverters[ottlprofile.TransformContext]())),
+		)),
+		withReusableProcessors[filterprocessor.Config](),
+	)
+}
+
 // NewFilterProcessorExecutor creates an internal.Executor that runs OTTL statements using
 // the [filterprocessor].
 func NewFilterProcessorExecutor() Executor {
 	return NewJSONExecutor[filterprocessor.Config](
-		newProcessorConsumer[filterprocessor.Config](filterprocessor.NewFactory()),
+		newFilterProcessorConsumer(),
 		newMetadata(
 			ComponentTypeProcessor,
 			"filter_processor",
@@ -71,6 +106,9 @@ func NewFilterProcessorExecutor() Executor {
 			"github.com/open-telemetry/opentelemetry-collector-contrib/processor/filterprocessor",
 			"https://github.com/open-telemetry/opentelemetry-collector-contrib/blob/main/processor/filterprocessor",
 			withConfigExamples(filterProcessorConfigExamples...),
+			withClientInfo(),
+			withFixedTimeContexts(filterProcessorFixedTimeContexts...),
 		),
+		withDebugger[filterprocessor.Config](NewFilterProcessorDebugger()),
 	)
 }
@@ -107,11 +107,28 @@ func Test_FilterProcessorExecutor_ExecuteMetrics(t *testing.T) {
 	}
 }
 
-func Test_FilterProcessorExecutor_ObservedLogs(t *testing.T) {
-	executor := NewFilterProcessorExecutor().(*defaultExecutor[filterprocessor.Config])
-	executor.consumer.TelemetrySettings().Logger.Sugar().Debug("this is a log")
-	logEntries := executor.ObservedLogs().TakeAll()
+func Test_FilterProcessorExecutor_InternalMetrics(t *testing.T) {
+	executor := NewFilterProcessorExecutor()
+	config := readTestData(t, filterprocessorConfig)
+	payload := readTestData(t, "logs.json")
+
+	output, err := executor.ExecuteLogs(config, payload)
+	require.NoError(t, err)
+	require.NotEmpty(t, output.InternalMetrics)
 
-	assert.Len(t, logEntries, 1)
-	assert.Contains(t, logEntries[0].ConsoleEncodedEntry(), "this is a log")
+	unmarshaler := &pmetric.JSONUnmarshaler{}
+	internalMetrics, err := unmarshaler.UnmarshalMetrics([]byte(output.InternalMetrics))
+	require.NoError(t, err)
+
+	var filtered int64
+	for _, rm := range internalMetrics.ResourceMetrics().All() {
+		for _, sm := range rm.ScopeMetrics().All() {
+			for _, m := range sm.Metrics().All() {
+				if m.Name() == "otelcol_processor_filter_logs.filtered" {
+					filtered += m.Sum().DataPoints().At(0).IntValue()
+				}
+			}
+		}
+	}
+	assert.Equal(t, int64(1), filtered)
 }
@@ -0,0 +1,319 @@
+/*
+ * Copyright Elasticsearch B.V. and/or licensed to Elasticsearch B.V. under one
+ * or more contributor license agreements. See the NOTICE file distributed with
+ * this work for additional information regarding copyright
+ * ownership. Elasticsearch B.V. licenses this file to you under
+ * the Apache License, Version 2.0 (the "License"); you may
+ * not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *	http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing,
+ * software distributed under the License is distributed on an
+ * "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+ * KIND, either express or implied.  See the License for the
+ * specific language governing permissions and limitations
+ * under the License.
+ */
+
+package internal
+
+import (
+	"github.com/open-telemetry/opentelemetry-collector-contrib/pkg/ottl/contexts/ottldatapoint"
+	"github.com/open-telemetry/opentelemetry-collector-contrib/pkg/ottl/contexts/ottllog"
+	"github.com/open-telemetry/opentelemetry-collector-contrib/pkg/ottl/contexts/ottlmetric"
+	"github.com/open-telemetry/opentelemetry-collector-contrib/pkg/ottl/contexts/ottlprofile"
+	"github.com/open-telemetry/opentelemetry-collector-contrib/pkg/ottl/contexts/ottlresource"
+	"github.com/open-telemetry/opentelemetry-collector-contrib/pkg/ottl/contexts/ottlspan"
+	"github.com/open-telemetry/opentelemetry-collector-contrib/pkg/ottl/contexts/ottlspanevent"
+	"github.com/open-telemetry/opentelemetry-collector-contrib/processor/filterprocessor"
+	"go.opentelemetry.io/collector/pdata/plog"
+	"go.opentelemetry.io/collector/pdata/pmetric"
+	"go.opentelemetry.io/collector/pdata/pprofile"
+	"go.opentelemetry.io/collector/pdata/ptrace"
+)
+
+// filterGroup is a list of conditions of a filter processor configuration, such
+// as the "log_record" conditions of the "logs" signal.
+type filterGroup struct {
+	name       string
+	conditions func(cfg *filterprocessor.Config) *[]string
+}
+
+// filterSignal adapts a signal's conditions and data to the signal-agnostic
+// filter processor debugger.
+type filterSignal[T any] struct {
+	// configKey is the configuration key holding the signal's conditions, such
+	// as "logs".
+	configKey string
+	// groups lists the signal's conditions lists in the order the processor
+	// evaluates them.
+	groups    []filterGroup
+	unmarshal func([]byte) (T, error)
+	marshal   func(T) ([]byte, error)
+	consume   func(exec *execution, cfg parsedConfig[filterprocessor.Config], payload T) (T, error)
+	// start starts a processor running cfg, and returns the function passing
+	// it the payload, and the one shutting it down.
+	start       func(exec *execution, cfg parsedConfig[filterprocessor.Config]) (func(T) (T, error), func(), error)
+	copyPayload func(T) T
+	// items returns the items of the payload, from the resources down.
+	items func(T) ([]payloadItem, error)
+	// inspect evaluates the conditions of cfg on every item of the payload, and
+	// reports the items, dropped if their index is in dropped.
+	inspect func(exec *execution, locator *statementLocator, cfg parsedConfig[filterprocessor.Config], payload T, dropped map[string]bool) ([]FilteredItem, error)
+}
+
+func newLogsFilterSignal(consumer *processorConsumer[filterprocessor.Config]) filterSignal[plog.Logs] {
+	um := plog.JSONUnmarshaler{}
+	ma := plog.JSONMarshaler{}
+	return filterSignal[plog.Logs]{
+		configKey: "logs",
+		groups: []filterGroup{
+			{"resource", func(cfg *filterprocessor.Config) *[]string { return &cfg.Logs.ResourceConditions }},
+			{"log_record", func(cfg *filterprocessor.Config) *[]string { return &cfg.Logs.LogConditions }},
+		},
+		unmarshal:   um.UnmarshalLogs,
+		marshal:     ma.MarshalLogs,
+		consume:     consumer.ConsumeLogs,
+		start:       consumer.startLogs,
+		copyPayload: copyLogs,
+		items:       logsItems,
+		inspect:     inspectFilteredLogs,
+	}
+}
+
+func newTracesFilterSignal(consumer *processorConsumer[filterprocessor.Config]) filterSignal[ptrace.Traces] {
+	um := ptrace.JSONUnmarshaler{}
+	ma := ptrace.JSONMarshaler{}
+	return filterSignal[ptrace.Traces]{
+		configKey: "traces",
+		groups: []filterGroup{
+			{"resource", func(cfg *filterprocessor.Config) *[]string { return &cfg.Traces.ResourceConditions }},
+			{"span", func(cfg *filterprocessor.Config) *[]string { return &cfg.Traces.SpanConditions }},
+			{"spanevent", func(cfg *filterprocessor.Config) *[]string { return &cfg.Traces.SpanEventConditions }},
+		},
+		unmarshal:   um.UnmarshalTraces,
+		marshal:     ma.MarshalTraces,
+		consume:     consumer.ConsumeTraces,
+		start:       consumer.startTraces,
+		copyPayload: copyTraces,
+		items:       tracesItems,
+		inspect:     inspectFilteredTraces,
+	}
+}
+
+func newMetricsFilterSignal(consumer *processorConsumer[filterprocessor.Config]) filterSignal[pmetric.Metrics] {
+	um := pmetric.JSONUnmarshaler{}
+	ma := pmetric.JSONMarshaler{}
+	return filterSignal[pmetric.Metrics]{
+		configKey: "metrics",
+		groups: []filterGroup{
+			{"resource", func(cfg *filterprocessor.Config) *[]string { return &cfg.Metrics.ResourceConditions }},
+			{"metric", func(cfg *filterprocessor.Config) *[]string { return &cfg.Metrics.MetricConditions }},
+			{"datapoint", func(cfg *filterprocessor.Config) *[]string { return &cfg.Metrics.DataPointConditions }},
+		},
+		unmarshal:   um.UnmarshalMetrics,
+		marshal:     ma.MarshalMetrics,
+		consume:     consumer.ConsumeMetrics,
+		start:       consumer.startMetrics,
+		copyPayload: copyMetrics,
+		items:       metricsItems,
+		inspect:     inspectFilteredMetrics,
+	}
+}
+
+func newProfilesFilterSignal(consumer *processorConsumer[filterprocessor.Config]) filterSignal[pprofile.Profiles] {
+	um := pprofile.JSONUnmarshaler{}
+	ma := pprofile.JSONMarshaler{}
+	return filterSignal[pprofile.Profiles]{
+		configKey: "profiles",
+		groups: []filterGroup{
+			{"resource", func(cfg *filterprocessor.Config) *[]string { return &cfg.Profiles.ResourceConditions }},
+			{"profile", func(cfg *filterprocessor.Config) *[]string { return &cfg.Profiles.ProfileConditions }},
+		},
+		unmarshal:   um.UnmarshalProfiles,
+		marshal:     ma.MarshalProfiles,
+		consume:     consumer.ConsumeProfiles,
+		start:       consumer.startProfiles,
+		copyPayload: copyProfiles,
+		items:       profilesItems,
+		inspect:     inspectFilteredProfiles,
+	}
+}
+
+// The inspect functions below evaluate the conditions on the items of the
+// payload, but on the items of a parent a condition matched, which the filter
+// processor doesn't evaluate. Whether an item is dropped is told by the
+// processor's output.
+
+func inspectFilteredLogs(exec *execution, locator *statementLocator, cfg parsedConfig[filterprocessor.Config], ld plog.Logs, dropped map[string]bool) ([]FilteredItem, error) {
+	resourceConditions, err := parseFilterConditions(exec, locator, cfg.Key, "logs.resource", cfg.Value.Logs.ResourceConditions, ottlresource.NewParser, filterprocessor.DefaultResourceFunctions())
+	if err != nil {
+		return nil, err
+	}
+	logConditions, err := parseFilterConditions(exec, locator, cfg.Key, "logs.log_record", cfg.Value.Logs.LogConditions, ottllog.NewParser, filterprocessor.DefaultLogFunctionsNew())
+	if err != nil {
+		return nil, err
+	}
+	if resourceConditions.empty() && logConditions.empty() {
+		return nil, nil
+	}
+
+	ctx := exec.Context()
+	items := filteredItems{configKey: cfg.Key, dropped: dropped}
+	for r, rl := range ld.ResourceLogs().All() {
+		resourceMatched := items.add("resource", resourceConditions.evaluate(ctx, false, func() *ottlresource.TransformContext {
+			return ottlresource.NewTransformContextPtr(rl.Resource(), rl)
+		}), r)
+		for s, sl := range rl.ScopeLogs().All() {
+			items.add("scope", nil, r, s)
+			for l, lr := range sl.LogRecords().All() {
+				items.add("log", logConditions.evaluate(ctx, resourceMatched, func() *ottllog.TransformContext {
+					return ottllog.NewTransformContextPtr(rl, sl, lr)
+				}), r, s, l)
+			}
+		}
+	}
+	return items.items, nil
+}
+
+func inspectFilteredTraces(exec *execution, locator *statementLocator, cfg parsedConfig[filterprocessor.Config], td ptrace.Traces, dropped map[string]bool) ([]FilteredItem, error) {
+	resourceConditions, err := parseFilterConditions(exec, locator, cfg.Key, "traces.resource", cfg.Value.Traces.ResourceConditions, ottlresource.NewParser, filterprocessor.DefaultResourceFunctions())
+	if err != nil {
+		return nil, err
+	}
+	spanConditions, err := parseFilterConditions(exec, locator, cfg.Key, "traces.span", cfg.Value.Traces.SpanConditions, ottlspan.NewParser, filterprocessor.DefaultSpanFunctionsNew())
+	if err != nil {
+		return nil, err
+	}
+	spanEventConditions, err := parseFilterConditions(exec, locator, cfg.Key, "traces.spanevent", cfg.Value.Traces.SpanEventConditions, ottlspanevent.NewParser, filterprocessor.DefaultSpanEventFunctionsNew())
+	if err != nil {
+		return nil, err
+	}
+	if resourceConditions.empty() && spanConditions.empty() && spanEventConditions.empty() {
+		return nil, nil
+	}
+
+	ctx := exec.Context()
+	items := filteredItems{configKey: cfg.Key, dropped: dropped}
+	for r, rs := range td.ResourceSpans().All() {
+		resourceMatched := items.add("resource", resourceConditions.evaluate(ctx, false, func() *ottlresource.TransformContext {
+			return ottlresource.NewTransformContextPtr(rs.Resource(), rs)
+		}), r)
+		for s, ss := range rs.ScopeSpans().All() {
+			items.add("scope", nil, r, s)
+			for sp, span := range ss.Spans().All() {
+				spanMatched := items.add("span", spanConditions.evaluate(ctx, resourceMatched, func() *ottlspan.TransformContext {
+					return ottlspan.NewTransformContextPtr(rs, ss, span)
+				}), r, s, sp)
+				for e, event := range span.Events().All() {
+					items.add("spanevent", spanEventConditions.evaluate(ctx, resourceMatched || spanMatched, func() *ottlspanevent.TransformContext {
+						return ottlspanevent.NewTransformContextPtr(rs, ss, span, event)
+					}), r, s, sp, e)
+				}
+			}
+		}
+	}
+	return items.items, nil
+}
+
+func inspectFilteredMetrics(exec *execution, locator *statementLocator, cfg parsedConfig[filterprocessor.Config], md pmetric.Metrics, dropped map[string]bool) ([]FilteredItem, error) {
+	resourceConditions, err := parseFilterConditions(exec, locator, cfg.Key, "metrics.resource", cfg.Value.Metrics.ResourceConditions, ottlresource.NewParser, filterprocessor.DefaultResourceFunctions())
+	if err != nil {
+		return nil, err
+	}
+	metricConditions, err := parseFilterConditions(exec, locator, cfg.Key, "metrics.metric", cfg.Value.Metrics.MetricConditions, ottlmetric.NewParser, filterprocessor.DefaultMetricFunctionsNew())
+	if err != nil {
+		return nil, err
+	}
+	dataPointConditions, err := parseFilterConditions(exec, locator, cfg.Key, "metrics.datapoint", cfg.Value.Metrics.DataPointConditions, ottldatapoint.NewParser, filterprocessor.DefaultDataPointFunctionsNew())
+	if err != nil {
+		return nil, err
+	}
+	if resourceConditions.empty() && metricConditions.empty() && dataPointConditions.empty() {
+		return nil, nil
+	}
+
+	ctx := exec.Context()
+	items := filteredItems{configKey: cfg.Key, dropped: dropped}
+	for r, rm := range md.ResourceMetrics().All() {
+		resourceMatched := items.add("resource", resourceConditions.evaluate(ctx, false, func() *ottlresource.TransformContext {
+			return ottlresource.NewTransformContextPtr(rm.Resource(), rm)
+		}), r)
+		for s, sm := range rm.ScopeMetrics().All() {
+			items.add("scope", nil, r, s)
+			for m, metric := range sm.Metrics().All() {
+				metricMatched := items.add("metric", metricConditions.evaluate(ctx, resourceMatched, func() *ottlmetric.TransformContext {
+					return ottlmetric.NewTransformContextPtr(rm, sm, metric)
+				}), r, s, m)
+				for d, dataPoint := range metricDataPoints(metric) {
+					items.add("datapoint", dataPointConditions.evaluate(ctx, resourceMatched || metricMatched, func() *ottldatapoint.TransformContext {
+						return ottldatapoint.NewTransformContextPtr(rm, sm, metric, dataPoint)
+					}), r, s, m, d)
+				}
+			}
+		}
+	}
+	return items.items, nil
+}
+
+func inspectFilteredProfiles(exec *execution, locator *statementLocator, cfg parsedConfig[filterprocessor.Config], pd pprofile.Profiles, dropped map[string]bool) ([]FilteredItem, error) {
+	resourceConditions, err := parseFilterConditions(exec, locator, cfg.Key, "profiles.resource", cfg.Value.Profiles.ResourceConditions, ottlresource.NewParser, filterprocessor.DefaultResourceFunctions())
+	if err != nil {
+		return nil, err
+	}
+	profileConditions, err := parseFilterConditions(exec, locator, cfg.Key, "profiles.profile", cfg.Value.Profiles.ProfileConditions, ottlprofile.NewParser, filterprocessor.DefaultProfileFunctions())
+	if err != nil {
+		return nil, err
+	}
+	if resourceConditions.empty() && profileConditions.empty() {
+		return nil, nil
+	}
+
+	ctx := exec.Context()
+	dictionary := pd.Dictionary()
+	items := filteredItems{configKey: cfg.Key, dropped: dropped}
+	for r, rp := range pd.ResourceProfiles().All() {
+		resourceMatched := items.add("resource", resourceConditions.evaluate(ctx, false, func() *ottlresource.TransformContext {
+			return ottlresource.NewTransformContextPtr(rp.Resource(), rp)
+		}), r)
+		for s, sp := range rp.ScopeProfiles().All() {
+			items.add("scope", nil, r, s)
+			for p, profile := range sp.Profiles().All() {
+				items.add("profile", profileConditions.evaluate(ctx, resourceMatched, func() ottlprofile.TransformContext {
+					return ottlprofile.NewTransformContext(profile, dictionary, sp.Scope(), rp.Resource(), sp, rp)
+				}), r, s, p)
+			}
+		}
+	}
+	return items.items, nil
+}
+
+// metricDataPoints returns the data points of the metric.
+func metricDataPoints(metric pmetric.Metric) []any {
+	var dataPoints []any
+	switch metric.Type() {
+	case pmetric.MetricTypeSum:
+		for _, dp := range metric.Sum().DataPoints().All() {
+			dataPoints = append(dataPoints, dp)
+		}
+	case pmetric.MetricTypeGauge:
+		for _, dp := range metric.Gauge().DataPoints().All() {
+			dataPoints = append(dataPoints, dp)
+		}
+	case pmetric.MetricTypeHistogram:
+		for _, dp := range metric.Histogram().DataPoints().All() {
+			dataPoints = append(dataPoints, dp)
+		}
+	case pmetric.MetricTypeExponentialHistogram:
+		for _, dp := range metric.ExponentialHistogram().DataPoints().All() {
+			dataPoints = append(dataPoints, dp)
+		}
+	case pmetric.MetricTypeSummary:
+		for _, dp := range metric.Summary().DataPoints().All() {
+			dataPoints = append(dataPoints, dp)
+		}
+	}
+	return dataPoints
+}
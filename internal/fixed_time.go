@@ -0,0 +1,129 @@
+/*
+ * Copyright Elasticsearch B.V. and/or licensed to Elasticsearch B.V. under one
+ * or more contributor license agreements. See the NOTICE file distributed with
+ * this work for additional information regarding copyright
+ * ownership. Elasticsearch B.V. licenses this file to you under
+ * the Apache License, Version 2.0 (the "License"); you may
+ * not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *	http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing,
+ * software distributed under the License is distributed on an
+ * "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+ * KIND, either express or implied.  See the License for the
+ * specific language governing permissions and limitations
+ * under the License.
+ */
+
+package internal
+
+import (
+	"context"
+	"maps"
+	"regexp"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/open-telemetry/opentelemetry-collector-contrib/pkg/ottl"
+)
+
+const nowFunctionName = "Now"
+
+var (
+	// nondeterministicFunctionPattern matches the calls to the OTTL functions
+	// returning a different value on every run: the ones reading the wall clock
+	// or generating random identifiers.
+	nondeterministicFunctionPattern = regexp.MustCompile(`\b(Now|UUID|UUIDv7)\(\s*\)`)
+	// contextPathPattern matches the OTTL context names used as path prefixes.
+	contextPathPattern = regexp.MustCompile(`\b(resource|instrumentation_scope|scope|log|metric|datapoint|span|spanevent|profile)\.`)
+	// inferredContexts lists the OTTL contexts from the most to the least
+	// specific, which is the order used to infer a statement's context from the
+	// paths it uses.
+	inferredContexts = []string{"log", "datapoint", "spanevent", "profile", "metric", "span", "scope", "resource"}
+)
+
+// fixedTimeKey is the context key of the time set by WithFixedTime.
+type fixedTimeKey struct{}
+
+// fixedTimeFromContext returns the time set by WithFixedTime, if any.
+func fixedTimeFromContext(ctx context.Context) (time.Time, bool) {
+	t, ok := ctx.Value(fixedTimeKey{}).(time.Time)
+	return t, ok
+}
+
+// newNowFactory returns an OTTL Now() function returning the execution's fixed
+// time, or the current time if it isn't set.
+func newNowFactory[K any]() ottl.Factory[K] {
+	return ottl.NewFactory(nowFunctionName, nil, func(_ ottl.FunctionContext, _ ottl.Arguments) (ottl.ExprFunc[K], error) {
+		return func(ctx context.Context, _ K) (any, error) {
+			if t, ok := fixedTimeFromContext(ctx); ok {
+				return t, nil
+			}
+			return time.Now(), nil
+		}, nil
+	})
+}
+
+// withFixedTimeNow returns the given OTTL functions, with Now() replaced by the
+// one honoring WithFixedTime.
+func withFixedTimeNow[K any](functions map[string]ottl.Factory[K]) []ottl.Factory[K] {
+	functions[nowFunctionName] = newNowFactory[K]()
+	return slices.Collect(maps.Values(functions))
+}
+
+// deterministic reports whether the located statements produce the same output
+// on every run, that is, whether they don't generate UUIDs and all their Now()
+// calls return the fixed time. Statements calling Now() whose OTTL context
+// can't be told are considered non-deterministic.
+func (l *statementLocator) deterministic(fixedTime bool, fixedTimeContexts []string) bool {
+	for _, s := range l.statements {
+		for _, match := range nondeterministicFunctionPattern.FindAllStringSubmatch(s.text, -1) {
+			if match[1] != nowFunctionName {
+				return false
+			}
+			if !fixedTime || !slices.Contains(fixedTimeContexts, statementContext(s)) {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+// statementContext returns the OTTL context the statement runs in: the one set
+// for its group, the one of the filter conditions list holding it, the one
+// inferred for its group, or else the most specific context its paths refer
+// to. It returns an empty string if the context can't be told.
+func statementContext(s locatedStatement) string {
+	if s.context != "" {
+		return s.context
+	}
+
+	list := s.location.Path[strings.LastIndex(s.location.Path, ".")+1:]
+	switch list {
+	case "log_record":
+		return "log"
+	case "resource", "metric", "datapoint", "span", "spanevent", "profile":
+		return list
+	}
+	if s.inferred != "" {
+		return s.inferred
+	}
+
+	used := map[string]bool{}
+	for _, match := range contextPathPattern.FindAllStringSubmatch(s.text, -1) {
+		name := match[1]
+		if name == "instrumentation_scope" {
+			name = "scope"
+		}
+		used[name] = true
+	}
+	for _, name := range inferredContexts {
+		if used[name] {
+			return name
+		}
+	}
+	return ""
+}
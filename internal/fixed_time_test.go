@@ -0,0 +1,208 @@
+/*
+ * Copyright Elasticsearch B.V. and/or licensed to Elasticsearch B.V. under one
+ * or more contributor license agreements. See the NOTICE file distributed with
+ * this work for additional information regarding copyright
+ * ownership. Elasticsearch B.V. licenses this file to you under
+ * the Apache License, Version 2.0 (the "License"); you may
+ * not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *	http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing,
+ * software distributed under the License is distributed on an
+ * "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+ * KIND, either express or implied.  See the License for the
+ * specific language governing permissions and limitations
+ * under the License.
+ */
+
+package internal
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/open-telemetry/opentelemetry-collector-contrib/pkg/ottl"
+	"github.com/open-telemetry/opentelemetry-collector-contrib/pkg/ottl/contexts/ottllog"
+	"github.com/open-telemetry/opentelemetry-collector-contrib/processor/transformprocessor"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/collector/pdata/plog"
+	"go.opentelemetry.io/collector/pdata/ptrace"
+)
+
+func Test_newNowFactory(t *testing.T) {
+	factory := newNowFactory[*ottllog.TransformContext]()
+	assert.Equal(t, nowFunctionName, factory.Name())
+
+	now, err := factory.CreateFunction(ottl.FunctionContext{}, nil)
+	require.NoError(t, err)
+
+	fixedTime := time.Date(2025, 1, 2, 3, 4, 5, 0, time.UTC)
+	value, err := now(context.WithValue(context.Background(), fixedTimeKey{}, fixedTime), nil)
+	require.NoError(t, err)
+	assert.Equal(t, fixedTime, value)
+
+	value, err = now(context.Background(), nil)
+	require.NoError(t, err)
+	assert.WithinDuration(t, time.Now(), value.(time.Time), time.Minute)
+}
+
+func Test_statementContext(t *testing.T) {
+	tests := []struct {
+		name      string
+		statement locatedStatement
+		expected  string
+	}{
+		{
+			name:      "group context",
+			statement: locatedStatement{text: `set(attributes["a"], Now())`, context: "log", location: StatementLocation{Path: "transform.log_statements.0.statements"}},
+			expected:  "log",
+		},
+		{
+			name:      "filter conditions list",
+			statement: locatedStatement{text: `Now() > time`, location: StatementLocation{Path: "filter.logs.log_record"}},
+			expected:  "log",
+		},
+		{
+			name:      "most specific path",
+			statement: locatedStatement{text: `set(resource.attributes["a"], log.attributes["b"])`, location: StatementLocation{Path: "transform.log_statements"}},
+			expected:  "log",
+		},
+		{
+			name:      "instrumentation scope path",
+			statement: locatedStatement{text: `set(instrumentation_scope.attributes["a"], Now())`, location: StatementLocation{Path: "transform.log_statements"}},
+			expected:  "scope",
+		},
+		{
+			name:      "unknown",
+			statement: locatedStatement{text: `set(attributes["a"], Now())`, location: StatementLocation{Path: "transform.log_statements"}},
+			expected:  "",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, statementContext(tt.statement))
+		})
+	}
+}
+
+func Test_statementLocator_deterministic(t *testing.T) {
+	config := `transform:
+  log_statements:
+    - context: log
+      statements:
+        - set(attributes["now"], Now())
+  trace_statements:
+    - set(span.attributes["a"], "a")`
+
+	cfgs := []parsedConfig[transformprocessor.Config]{{Key: ""}}
+	locator, err := newStatementLocator(config, cfgs)
+	require.NoError(t, err)
+
+	assert.False(t, locator.deterministic(false, transformProcessorFixedTimeContexts))
+	assert.True(t, locator.deterministic(true, transformProcessorFixedTimeContexts))
+	assert.False(t, locator.deterministic(true, []string{"span"}))
+
+	locator, err = newStatementLocator(`transform:
+  log_statements:
+    - set(log.attributes["a"], "a")`, cfgs)
+	require.NoError(t, err)
+	assert.True(t, locator.deterministic(false, nil))
+
+	for _, function := range []string{"UUID()", "UUIDv7()"} {
+		locator, err = newStatementLocator(`transform:
+  log_statements:
+    - set(log.attributes["now"], Now())
+    - set(log.attributes["id"], `+function+`)`, cfgs)
+		require.NoError(t, err)
+		assert.False(t, locator.deterministic(true, transformProcessorFixedTimeContexts), function)
+	}
+}
+
+func Test_TransformProcessorExecutor_ExecuteLogs_UUID(t *testing.T) {
+	executor := NewTransformProcessorExecutor()
+	config := "transform:\n  log_statements:\n    - set(log.attributes[\"id\"], UUID())"
+	payload := `{"resourceLogs":[{"scopeLogs":[{"logRecords":[{"body":{"stringValue":"log"}}]}]}]}`
+
+	result, err := executor.ExecuteLogs(config, payload, WithFixedTime(time.Now()))
+	require.NoError(t, err)
+	require.NotNil(t, result.Deterministic)
+	assert.False(t, *result.Deterministic)
+
+	encoded, err := json.Marshal(result)
+	require.NoError(t, err)
+	assert.Contains(t, string(encoded), `"deterministic":false`)
+
+	// Results not evaluated don't tell
+	encoded, err = json.Marshal(&Result{})
+	require.NoError(t, err)
+	assert.NotContains(t, string(encoded), `"deterministic"`)
+}
+
+func Test_TransformProcessorExecutor_ExecuteLogs_FixedTime(t *testing.T) {
+	executor := NewTransformProcessorExecutor()
+	config := "transform:\n  log_statements:\n    - set(log.attributes[\"now\"], UnixNano(Now()))"
+	payload := `{"resourceLogs":[{"scopeLogs":[{"logRecords":[{"body":{"stringValue":"log"}}]}]}]}`
+	fixedTime := time.Date(2025, 1, 2, 3, 4, 5, 0, time.UTC)
+
+	result, err := executor.ExecuteLogs(config, payload, WithFixedTime(fixedTime))
+	require.NoError(t, err)
+	require.NotNil(t, result.Deterministic)
+	assert.True(t, *result.Deterministic)
+
+	unmarshaler := &plog.JSONUnmarshaler{}
+	logs, err := unmarshaler.UnmarshalLogs([]byte(result.Value))
+	require.NoError(t, err)
+	value, ok := logs.ResourceLogs().At(0).ScopeLogs().At(0).LogRecords().At(0).Attributes().Get("now")
+	require.True(t, ok)
+	assert.Equal(t, fixedTime.UnixNano(), value.Int())
+
+	result, err = executor.ExecuteLogs(config, payload)
+	require.NoError(t, err)
+	require.NotNil(t, result.Deterministic)
+	assert.False(t, *result.Deterministic)
+}
+
+func Test_TransformProcessorExecutor_ExecuteTraces_FixedTimeUnsupportedContext(t *testing.T) {
+	executor := NewTransformProcessorExecutor()
+	config := "transform:\n  trace_statements:\n    - set(span.attributes[\"now\"], UnixNano(Now()))"
+	payload := `{"resourceSpans":[{"scopeSpans":[{"spans":[{"name":"span"}]}]}]}`
+	fixedTime := time.Date(2025, 1, 2, 3, 4, 5, 0, time.UTC)
+
+	result, err := executor.ExecuteTraces(config, payload, WithFixedTime(fixedTime))
+	require.NoError(t, err)
+	require.NotNil(t, result.Deterministic)
+	assert.False(t, *result.Deterministic)
+
+	unmarshaler := &ptrace.JSONUnmarshaler{}
+	traces, err := unmarshaler.UnmarshalTraces([]byte(result.Value))
+	require.NoError(t, err)
+	value, ok := traces.ResourceSpans().At(0).ScopeSpans().At(0).Spans().At(0).Attributes().Get("now")
+	require.True(t, ok)
+	assert.NotEqual(t, fixedTime.UnixNano(), value.Int())
+}
+
+func Test_FilterProcessorExecutor_ExecuteLogs_FixedTime(t *testing.T) {
+	executor := NewFilterProcessorExecutor()
+	config := "filter:\n  logs:\n    log_record:\n      - time < Now()"
+	payload := `{"resourceLogs":[{"scopeLogs":[{"logRecords":[{"timeUnixNano":"1735786800000000000","body":{"stringValue":"log"}}]}]}]}`
+
+	result, err := executor.ExecuteLogs(config, payload, WithFixedTime(time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)))
+	require.NoError(t, err)
+	require.NotNil(t, result.Deterministic)
+	assert.True(t, *result.Deterministic)
+	assert.Contains(t, result.Value, `"body":{"stringValue":"log"}`)
+
+	result, err = executor.ExecuteLogs(config, payload, WithFixedTime(time.Date(2025, 1, 3, 0, 0, 0, 0, time.UTC)))
+	require.NoError(t, err)
+	assert.NotContains(t, result.Value, `"body":{"stringValue":"log"}`)
+}
+
+func Test_Executor_Metadata_FixedTimeContexts(t *testing.T) {
+	assert.Equal(t, transformProcessorFixedTimeContexts, NewTransformProcessorExecutor().Metadata().FixedTimeContexts)
+	assert.Equal(t, filterProcessorFixedTimeContexts, NewFilterProcessorExecutor().Metadata().FixedTimeContexts)
+}
@@ -0,0 +1,43 @@
+/*
+ * Copyright Elasticsearch B.V. and/or licensed to Elasticsearch B.V. under one
+ * or more contributor license agreements. See the NOTICE file distributed with
+ * this work for additional information regarding copyright
+ * ownership. Elasticsearch B.V. licenses this file to you under
+ * the Apache License, Version 2.0 (the "License"); you may
+ * not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *	http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing,
+ * software distributed under the License is distributed on an
+ * "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+ * KIND, either express or implied.  See the License for the
+ * specific language governing permissions and limitations
+ * under the License.
+ */
+
+package internal
+
+import (
+	"go.opentelemetry.io/collector/component"
+)
+
+// playgroundHost is the component.Host the playground components are started
+// with.
+type playgroundHost struct {
+	extensions map[component.ID]component.Component
+}
+
+var _ component.Host = (*playgroundHost)(nil)
+
+func newPlaygroundHost() *playgroundHost {
+	return &playgroundHost{
+		extensions: map[component.ID]component.Component{},
+	}
+}
+
+// GetExtensions returns the extensions available to the components.
+func (h *playgroundHost) GetExtensions() map[component.ID]component.Component {
+	return h.extensions
+}
@@ -0,0 +1,387 @@
+/*
+ * Copyright Elasticsearch B.V. and/or licensed to Elasticsearch B.V. under one
+ * or more contributor license agreements. See the NOTICE file distributed with
+ * this work for additional information regarding copyright
+ * ownership. Elasticsearch B.V. licenses this file to you under
+ * the Apache License, Version 2.0 (the "License"); you may
+ * not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *	http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing,
+ * software distributed under the License is distributed on an
+ * "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+ * KIND, either express or implied.  See the License for the
+ * specific language governing permissions and limitations
+ * under the License.
+ */
+
+package internal
+
+import (
+	"context"
+
+	"go.opentelemetry.io/collector/pdata/pcommon"
+	"go.opentelemetry.io/collector/pdata/pmetric"
+	"go.opentelemetry.io/collector/pdata/ptrace"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/sdk/instrumentation"
+	sdkmetric "go.opentelemetry.io/otel/sdk/metric"
+	"go.opentelemetry.io/otel/sdk/metric/metricdata"
+	"go.opentelemetry.io/otel/sdk/resource"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// internalTelemetry records the metrics and spans the components emit about
+// themselves.
+type internalTelemetry struct {
+	meterProvider  *sdkmetric.MeterProvider
+	tracerProvider *sdktrace.TracerProvider
+	metricReader   *sdkmetric.ManualReader
+	spanRecorder   *tracetest.SpanRecorder
+}
+
+func newInternalTelemetry() *internalTelemetry {
+	// Delta temporality makes each collection report only what was recorded
+	// since the previous one, which is what the debugger steps need.
+	metricReader := sdkmetric.NewManualReader(sdkmetric.WithTemporalitySelector(func(sdkmetric.InstrumentKind) metricdata.Temporality {
+		return metricdata.DeltaTemporality
+	}))
+	spanRecorder := tracetest.NewSpanRecorder()
+
+	return &internalTelemetry{
+		meterProvider: sdkmetric.NewMeterProvider(
+			sdkmetric.WithReader(metricReader),
+			sdkmetric.WithResource(resource.Empty()),
+		),
+		tracerProvider: sdktrace.NewTracerProvider(
+			sdktrace.WithSpanProcessor(spanRecorder),
+			sdktrace.WithResource(resource.Empty()),
+		),
+		metricReader: metricReader,
+		spanRecorder: spanRecorder,
+	}
+}
+
+// collectMetrics returns the metrics recorded since the last collection.
+func (t *internalTelemetry) collectMetrics() (pmetric.Metrics, error) {
+	var rm metricdata.ResourceMetrics
+	if err := t.metricReader.Collect(context.Background(), &rm); err != nil {
+		return pmetric.Metrics{}, err
+	}
+	return internalMetricsToPdata(rm), nil
+}
+
+// collectSpans returns the spans ended since the last collection.
+func (t *internalTelemetry) collectSpans() ptrace.Traces {
+	spans := t.spanRecorder.Ended()
+	t.spanRecorder.Reset()
+	return internalSpansToPdata(spans)
+}
+
+func (t *internalTelemetry) shutdown() {
+	_ = t.tracerProvider.Shutdown(context.Background())
+	_ = t.meterProvider.Shutdown(context.Background())
+}
+
+// moveInternalMetrics moves the internal metrics from src into dest. Both hold
+// at most one, empty, resource, so the scopes are merged into that resource.
+func moveInternalMetrics(src, dest pmetric.Metrics) {
+	if dest.ResourceMetrics().Len() == 0 {
+		src.ResourceMetrics().MoveAndAppendTo(dest.ResourceMetrics())
+		return
+	}
+	for _, resourceMetrics := range src.ResourceMetrics().All() {
+		resourceMetrics.ScopeMetrics().MoveAndAppendTo(dest.ResourceMetrics().At(0).ScopeMetrics())
+	}
+}
+
+// moveInternalSpans is like moveInternalMetrics, but for spans.
+func moveInternalSpans(src, dest ptrace.Traces) {
+	if dest.ResourceSpans().Len() == 0 {
+		src.ResourceSpans().MoveAndAppendTo(dest.ResourceSpans())
+		return
+	}
+	for _, resourceSpans := range src.ResourceSpans().All() {
+		resourceSpans.ScopeSpans().MoveAndAppendTo(dest.ResourceSpans().At(0).ScopeSpans())
+	}
+}
+
+// internalMetricsToPdata converts the metrics recorded by the components about
+// themselves into pmetric.Metrics, so they can be rendered like any OTLP payload.
+func internalMetricsToPdata(rm metricdata.ResourceMetrics) pmetric.Metrics {
+	metrics := pmetric.NewMetrics()
+	if len(rm.ScopeMetrics) == 0 {
+		return metrics
+	}
+
+	resourceMetrics := metrics.ResourceMetrics().AppendEmpty()
+	if rm.Resource != nil {
+		resourceMetrics.SetSchemaUrl(rm.Resource.SchemaURL())
+		putAttributes(resourceMetrics.Resource().Attributes(), rm.Resource.Attributes())
+	}
+
+	for _, sm := range rm.ScopeMetrics {
+		scopeMetrics := resourceMetrics.ScopeMetrics().AppendEmpty()
+		scopeMetrics.SetSchemaUrl(sm.Scope.SchemaURL)
+		scopeMetrics.Scope().SetName(sm.Scope.Name)
+		scopeMetrics.Scope().SetVersion(sm.Scope.Version)
+		putAttributes(scopeMetrics.Scope().Attributes(), sm.Scope.Attributes.ToSlice())
+
+		for _, m := range sm.Metrics {
+			metric := scopeMetrics.Metrics().AppendEmpty()
+			metric.SetName(m.Name)
+			metric.SetDescription(m.Description)
+			metric.SetUnit(m.Unit)
+
+			switch data := m.Data.(type) {
+			case metricdata.Gauge[int64]:
+				putNumberDataPoints(metric.SetEmptyGauge().DataPoints(), data.DataPoints)
+			case metricdata.Gauge[float64]:
+				putNumberDataPoints(metric.SetEmptyGauge().DataPoints(), data.DataPoints)
+			case metricdata.Sum[int64]:
+				putSum(metric.SetEmptySum(), data)
+			case metricdata.Sum[float64]:
+				putSum(metric.SetEmptySum(), data)
+			case metricdata.Histogram[int64]:
+				putHistogram(metric.SetEmptyHistogram(), data)
+			case metricdata.Histogram[float64]:
+				putHistogram(metric.SetEmptyHistogram(), data)
+			case metricdata.ExponentialHistogram[int64]:
+				putExponentialHistogram(metric.SetEmptyExponentialHistogram(), data)
+			case metricdata.ExponentialHistogram[float64]:
+				putExponentialHistogram(metric.SetEmptyExponentialHistogram(), data)
+			case metricdata.Summary:
+				putSummary(metric.SetEmptySummary(), data)
+			}
+		}
+	}
+
+	return metrics
+}
+
+// internalSpansToPdata converts the spans recorded by the components into
+// ptrace.Traces, grouping them by instrumentation scope.
+func internalSpansToPdata(spans []sdktrace.ReadOnlySpan) ptrace.Traces {
+	traces := ptrace.NewTraces()
+	if len(spans) == 0 {
+		return traces
+	}
+
+	resourceSpans := traces.ResourceSpans().AppendEmpty()
+	if res := spans[0].Resource(); res != nil {
+		resourceSpans.SetSchemaUrl(res.SchemaURL())
+		putAttributes(resourceSpans.Resource().Attributes(), res.Attributes())
+	}
+
+	scopes := map[instrumentation.Scope]ptrace.ScopeSpans{}
+	for _, span := range spans {
+		scope := span.InstrumentationScope()
+		scopeSpans, ok := scopes[scope]
+		if !ok {
+			scopeSpans = resourceSpans.ScopeSpans().AppendEmpty()
+			scopeSpans.SetSchemaUrl(scope.SchemaURL)
+			scopeSpans.Scope().SetName(scope.Name)
+			scopeSpans.Scope().SetVersion(scope.Version)
+			putAttributes(scopeSpans.Scope().Attributes(), scope.Attributes.ToSlice())
+			scopes[scope] = scopeSpans
+		}
+		putSpan(scopeSpans.Spans().AppendEmpty(), span)
+	}
+
+	return traces
+}
+
+func putSpan(dest ptrace.Span, span sdktrace.ReadOnlySpan) {
+	dest.SetTraceID(pcommon.TraceID(span.SpanContext().TraceID()))
+	dest.SetSpanID(pcommon.SpanID(span.SpanContext().SpanID()))
+	dest.TraceState().FromRaw(span.SpanContext().TraceState().String())
+	if span.Parent().IsValid() {
+		dest.SetParentSpanID(pcommon.SpanID(span.Parent().SpanID()))
+	}
+	dest.SetName(span.Name())
+	dest.SetKind(spanKind(span.SpanKind()))
+	dest.SetStartTimestamp(pcommon.NewTimestampFromTime(span.StartTime()))
+	dest.SetEndTimestamp(pcommon.NewTimestampFromTime(span.EndTime()))
+	putAttributes(dest.Attributes(), span.Attributes())
+	dest.SetDroppedAttributesCount(uint32(span.DroppedAttributes()))
+
+	for _, event := range span.Events() {
+		spanEvent := dest.Events().AppendEmpty()
+		spanEvent.SetName(event.Name)
+		spanEvent.SetTimestamp(pcommon.NewTimestampFromTime(event.Time))
+		putAttributes(spanEvent.Attributes(), event.Attributes)
+		spanEvent.SetDroppedAttributesCount(uint32(event.DroppedAttributeCount))
+	}
+	dest.SetDroppedEventsCount(uint32(span.DroppedEvents()))
+
+	for _, link := range span.Links() {
+		spanLink := dest.Links().AppendEmpty()
+		spanLink.SetTraceID(pcommon.TraceID(link.SpanContext.TraceID()))
+		spanLink.SetSpanID(pcommon.SpanID(link.SpanContext.SpanID()))
+		spanLink.TraceState().FromRaw(link.SpanContext.TraceState().String())
+		putAttributes(spanLink.Attributes(), link.Attributes)
+		spanLink.SetDroppedAttributesCount(uint32(link.DroppedAttributeCount))
+	}
+	dest.SetDroppedLinksCount(uint32(span.DroppedLinks()))
+
+	switch span.Status().Code {
+	case codes.Ok:
+		dest.Status().SetCode(ptrace.StatusCodeOk)
+	case codes.Error:
+		dest.Status().SetCode(ptrace.StatusCodeError)
+	}
+	dest.Status().SetMessage(span.Status().Description)
+}
+
+func spanKind(kind trace.SpanKind) ptrace.SpanKind {
+	switch kind {
+	case trace.SpanKindInternal:
+		return ptrace.SpanKindInternal
+	case trace.SpanKindServer:
+		return ptrace.SpanKindServer
+	case trace.SpanKindClient:
+		return ptrace.SpanKindClient
+	case trace.SpanKindProducer:
+		return ptrace.SpanKindProducer
+	case trace.SpanKindConsumer:
+		return ptrace.SpanKindConsumer
+	default:
+		return ptrace.SpanKindUnspecified
+	}
+}
+
+func putNumberDataPoints[N int64 | float64](dest pmetric.NumberDataPointSlice, points []metricdata.DataPoint[N]) {
+	for _, point := range points {
+		dp := dest.AppendEmpty()
+		putAttributes(dp.Attributes(), point.Attributes.ToSlice())
+		dp.SetStartTimestamp(pcommon.NewTimestampFromTime(point.StartTime))
+		dp.SetTimestamp(pcommon.NewTimestampFromTime(point.Time))
+		switch v := any(point.Value).(type) {
+		case int64:
+			dp.SetIntValue(v)
+		case float64:
+			dp.SetDoubleValue(v)
+		}
+	}
+}
+
+func putSum[N int64 | float64](dest pmetric.Sum, sum metricdata.Sum[N]) {
+	dest.SetIsMonotonic(sum.IsMonotonic)
+	dest.SetAggregationTemporality(aggregationTemporality(sum.Temporality))
+	putNumberDataPoints(dest.DataPoints(), sum.DataPoints)
+}
+
+func putHistogram[N int64 | float64](dest pmetric.Histogram, histogram metricdata.Histogram[N]) {
+	dest.SetAggregationTemporality(aggregationTemporality(histogram.Temporality))
+	for _, point := range histogram.DataPoints {
+		dp := dest.DataPoints().AppendEmpty()
+		putAttributes(dp.Attributes(), point.Attributes.ToSlice())
+		dp.SetStartTimestamp(pcommon.NewTimestampFromTime(point.StartTime))
+		dp.SetTimestamp(pcommon.NewTimestampFromTime(point.Time))
+		dp.SetCount(point.Count)
+		dp.SetSum(float64(point.Sum))
+		dp.ExplicitBounds().FromRaw(point.Bounds)
+		dp.BucketCounts().FromRaw(point.BucketCounts)
+		if v, ok := point.Min.Value(); ok {
+			dp.SetMin(float64(v))
+		}
+		if v, ok := point.Max.Value(); ok {
+			dp.SetMax(float64(v))
+		}
+	}
+}
+
+func putExponentialHistogram[N int64 | float64](dest pmetric.ExponentialHistogram, histogram metricdata.ExponentialHistogram[N]) {
+	dest.SetAggregationTemporality(aggregationTemporality(histogram.Temporality))
+	for _, point := range histogram.DataPoints {
+		dp := dest.DataPoints().AppendEmpty()
+		putAttributes(dp.Attributes(), point.Attributes.ToSlice())
+		dp.SetStartTimestamp(pcommon.NewTimestampFromTime(point.StartTime))
+		dp.SetTimestamp(pcommon.NewTimestampFromTime(point.Time))
+		dp.SetCount(point.Count)
+		dp.SetSum(float64(point.Sum))
+		dp.SetScale(point.Scale)
+		dp.SetZeroCount(point.ZeroCount)
+		dp.SetZeroThreshold(point.ZeroThreshold)
+		dp.Positive().SetOffset(point.PositiveBucket.Offset)
+		dp.Positive().BucketCounts().FromRaw(point.PositiveBucket.Counts)
+		dp.Negative().SetOffset(point.NegativeBucket.Offset)
+		dp.Negative().BucketCounts().FromRaw(point.NegativeBucket.Counts)
+		if v, ok := point.Min.Value(); ok {
+			dp.SetMin(float64(v))
+		}
+		if v, ok := point.Max.Value(); ok {
+			dp.SetMax(float64(v))
+		}
+	}
+}
+
+func putSummary(dest pmetric.Summary, summary metricdata.Summary) {
+	for _, point := range summary.DataPoints {
+		dp := dest.DataPoints().AppendEmpty()
+		putAttributes(dp.Attributes(), point.Attributes.ToSlice())
+		dp.SetStartTimestamp(pcommon.NewTimestampFromTime(point.StartTime))
+		dp.SetTimestamp(pcommon.NewTimestampFromTime(point.Time))
+		dp.SetCount(point.Count)
+		dp.SetSum(point.Sum)
+		for _, quantile := range point.QuantileValues {
+			qv := dp.QuantileValues().AppendEmpty()
+			qv.SetQuantile(quantile.Quantile)
+			qv.SetValue(quantile.Value)
+		}
+	}
+}
+
+func aggregationTemporality(temporality metricdata.Temporality) pmetric.AggregationTemporality {
+	switch temporality {
+	case metricdata.CumulativeTemporality:
+		return pmetric.AggregationTemporalityCumulative
+	case metricdata.DeltaTemporality:
+		return pmetric.AggregationTemporalityDelta
+	default:
+		return pmetric.AggregationTemporalityUnspecified
+	}
+}
+
+func putAttributes(dest pcommon.Map, attributes []attribute.KeyValue) {
+	for _, kv := range attributes {
+		key := string(kv.Key)
+		switch kv.Value.Type() {
+		case attribute.BOOL:
+			dest.PutBool(key, kv.Value.AsBool())
+		case attribute.INT64:
+			dest.PutInt(key, kv.Value.AsInt64())
+		case attribute.FLOAT64:
+			dest.PutDouble(key, kv.Value.AsFloat64())
+		case attribute.STRING:
+			dest.PutStr(key, kv.Value.AsString())
+		case attribute.BOOLSLICE:
+			slice := dest.PutEmptySlice(key)
+			for _, v := range kv.Value.AsBoolSlice() {
+				slice.AppendEmpty().SetBool(v)
+			}
+		case attribute.INT64SLICE:
+			slice := dest.PutEmptySlice(key)
+			for _, v := range kv.Value.AsInt64Slice() {
+				slice.AppendEmpty().SetInt(v)
+			}
+		case attribute.FLOAT64SLICE:
+			slice := dest.PutEmptySlice(key)
+			for _, v := range kv.Value.AsFloat64Slice() {
+				slice.AppendEmpty().SetDouble(v)
+			}
+		case attribute.STRINGSLICE:
+			slice := dest.PutEmptySlice(key)
+			for _, v := range kv.Value.AsStringSlice() {
+				slice.AppendEmpty().SetStr(v)
+			}
+		default:
+			dest.PutStr(key, kv.Value.Emit())
+		}
+	}
+}
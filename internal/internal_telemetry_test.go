@@ -0,0 +1,195 @@
+/*
+ * Copyright Elasticsearch B.V. and/or licensed to Elasticsearch B.V. under one
+ * or more contributor license agreements. See the NOTICE file distributed with
+ * this work for additional information regarding copyright
+ * ownership. Elasticsearch B.V. licenses this file to you under
+ * the Apache License, Version 2.0 (the "License"); you may
+ * not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *	http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing,
+ * software distributed under the License is distributed on an
+ * "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+ * KIND, either express or implied.  See the License for the
+ * specific language governing permissions and limitations
+ * under the License.
+ */
+
+package internal
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/collector/pdata/pmetric"
+	"go.opentelemetry.io/collector/pdata/ptrace"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/sdk/instrumentation"
+	"go.opentelemetry.io/otel/sdk/metric/metricdata"
+	"go.opentelemetry.io/otel/sdk/resource"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"go.opentelemetry.io/otel/trace"
+)
+
+func Test_internalMetricsToPdata_Empty(t *testing.T) {
+	metrics := internalMetricsToPdata(metricdata.ResourceMetrics{})
+	assert.Equal(t, 0, metrics.ResourceMetrics().Len())
+}
+
+func Test_internalMetricsToPdata(t *testing.T) {
+	now := time.Now()
+	attrs := attribute.NewSet(
+		attribute.String("processor", "filter/ottl_playground"),
+		attribute.StringSlice("values", []string{"a", "b"}),
+	)
+
+	rm := metricdata.ResourceMetrics{
+		Resource: resource.NewSchemaless(attribute.Bool("resource.bool", true)),
+		ScopeMetrics: []metricdata.ScopeMetrics{
+			{
+				Scope: instrumentation.Scope{Name: "test.scope", Version: "1.0.0"},
+				Metrics: []metricdata.Metrics{
+					{
+						Name: "test.sum",
+						Unit: "{items}",
+						Data: metricdata.Sum[int64]{
+							Temporality: metricdata.DeltaTemporality,
+							IsMonotonic: true,
+							DataPoints:  []metricdata.DataPoint[int64]{{Attributes: attrs, StartTime: now, Time: now, Value: 3}},
+						},
+					},
+					{
+						Name: "test.gauge",
+						Data: metricdata.Gauge[float64]{
+							DataPoints: []metricdata.DataPoint[float64]{{Time: now, Value: 1.5}},
+						},
+					},
+					{
+						Name: "test.histogram",
+						Data: metricdata.Histogram[float64]{
+							Temporality: metricdata.CumulativeTemporality,
+							DataPoints: []metricdata.HistogramDataPoint[float64]{{
+								Count:        2,
+								Sum:          3,
+								Bounds:       []float64{1, 2},
+								BucketCounts: []uint64{0, 1, 1},
+								Min:          metricdata.NewExtrema(1.0),
+								Max:          metricdata.NewExtrema(2.0),
+							}},
+						},
+					},
+				},
+			},
+		},
+	}
+
+	metrics := internalMetricsToPdata(rm)
+	require.Equal(t, 1, metrics.ResourceMetrics().Len())
+
+	resourceMetrics := metrics.ResourceMetrics().At(0)
+	resourceValue, ok := resourceMetrics.Resource().Attributes().Get("resource.bool")
+	require.True(t, ok)
+	assert.True(t, resourceValue.Bool())
+
+	scopeMetrics := resourceMetrics.ScopeMetrics().At(0)
+	assert.Equal(t, "test.scope", scopeMetrics.Scope().Name())
+	assert.Equal(t, "1.0.0", scopeMetrics.Scope().Version())
+	require.Equal(t, 3, scopeMetrics.Metrics().Len())
+
+	sum := scopeMetrics.Metrics().At(0)
+	assert.Equal(t, "test.sum", sum.Name())
+	assert.Equal(t, "{items}", sum.Unit())
+	assert.True(t, sum.Sum().IsMonotonic())
+	assert.Equal(t, pmetric.AggregationTemporalityDelta, sum.Sum().AggregationTemporality())
+	assert.Equal(t, int64(3), sum.Sum().DataPoints().At(0).IntValue())
+	assert.Equal(t, map[string]any{
+		"processor": "filter/ottl_playground",
+		"values":    []any{"a", "b"},
+	}, sum.Sum().DataPoints().At(0).Attributes().AsRaw())
+
+	gauge := scopeMetrics.Metrics().At(1)
+	assert.Equal(t, 1.5, gauge.Gauge().DataPoints().At(0).DoubleValue())
+
+	histogram := scopeMetrics.Metrics().At(2).Histogram()
+	assert.Equal(t, pmetric.AggregationTemporalityCumulative, histogram.AggregationTemporality())
+	histogramDataPoint := histogram.DataPoints().At(0)
+	assert.Equal(t, uint64(2), histogramDataPoint.Count())
+	assert.Equal(t, 3.0, histogramDataPoint.Sum())
+	assert.Equal(t, []float64{1, 2}, histogramDataPoint.ExplicitBounds().AsRaw())
+	assert.Equal(t, []uint64{0, 1, 1}, histogramDataPoint.BucketCounts().AsRaw())
+	assert.Equal(t, 1.0, histogramDataPoint.Min())
+	assert.Equal(t, 2.0, histogramDataPoint.Max())
+}
+
+func Test_internalSpansToPdata_Empty(t *testing.T) {
+	traces := internalSpansToPdata(nil)
+	assert.Equal(t, 0, traces.ResourceSpans().Len())
+}
+
+func Test_internalSpansToPdata(t *testing.T) {
+	recorder := tracetest.NewSpanRecorder()
+	provider := sdktrace.NewTracerProvider(
+		sdktrace.WithSpanProcessor(recorder),
+		sdktrace.WithResource(resource.NewSchemaless(attribute.String("resource.key", "value"))),
+	)
+	tracer := provider.Tracer("test.scope", trace.WithInstrumentationVersion("1.0.0"))
+
+	ctx, parent := tracer.Start(context.Background(), "parent", trace.WithSpanKind(trace.SpanKindServer))
+	_, child := tracer.Start(ctx, "child",
+		trace.WithAttributes(attribute.Int("count", 2)),
+		trace.WithLinks(trace.Link{SpanContext: parent.SpanContext()}),
+	)
+	child.AddEvent("event", trace.WithAttributes(attribute.Bool("flag", true)))
+	child.SetStatus(codes.Error, "failed")
+	child.End()
+	parent.End()
+
+	traces := internalSpansToPdata(recorder.Ended())
+	require.Equal(t, 1, traces.ResourceSpans().Len())
+	require.Equal(t, 2, traces.SpanCount())
+
+	resourceSpans := traces.ResourceSpans().At(0)
+	resourceValue, ok := resourceSpans.Resource().Attributes().Get("resource.key")
+	require.True(t, ok)
+	assert.Equal(t, "value", resourceValue.Str())
+
+	require.Equal(t, 1, resourceSpans.ScopeSpans().Len())
+	scopeSpans := resourceSpans.ScopeSpans().At(0)
+	assert.Equal(t, "test.scope", scopeSpans.Scope().Name())
+	assert.Equal(t, "1.0.0", scopeSpans.Scope().Version())
+
+	childSpan := scopeSpans.Spans().At(0)
+	parentSpan := scopeSpans.Spans().At(1)
+	assert.Equal(t, "child", childSpan.Name())
+	assert.Equal(t, "parent", parentSpan.Name())
+	assert.Equal(t, ptrace.SpanKindServer, parentSpan.Kind())
+	assert.Equal(t, ptrace.SpanKindInternal, childSpan.Kind())
+	assert.True(t, parentSpan.ParentSpanID().IsEmpty())
+	assert.Equal(t, parentSpan.SpanID(), childSpan.ParentSpanID())
+	assert.Equal(t, parentSpan.TraceID(), childSpan.TraceID())
+	assert.NotZero(t, childSpan.StartTimestamp())
+	assert.GreaterOrEqual(t, childSpan.EndTimestamp(), childSpan.StartTimestamp())
+
+	count, ok := childSpan.Attributes().Get("count")
+	require.True(t, ok)
+	assert.Equal(t, int64(2), count.Int())
+
+	require.Equal(t, 1, childSpan.Events().Len())
+	assert.Equal(t, "event", childSpan.Events().At(0).Name())
+	flag, ok := childSpan.Events().At(0).Attributes().Get("flag")
+	require.True(t, ok)
+	assert.True(t, flag.Bool())
+
+	require.Equal(t, 1, childSpan.Links().Len())
+	assert.Equal(t, parentSpan.SpanID(), childSpan.Links().At(0).SpanID())
+
+	assert.Equal(t, ptrace.StatusCodeError, childSpan.Status().Code())
+	assert.Equal(t, "failed", childSpan.Status().Message())
+}
@@ -20,24 +20,54 @@
 package internal
 
 import (
+	"encoding/json"
 	"strings"
 	"sync"
+	"time"
 
 	"go.uber.org/zap/zapcore"
 )
 
+// LogEntry is the structured representation of an observed log entry.
+type LogEntry struct {
+	Time    time.Time      `json:"time"`
+	Level   string         `json:"level"`
+	Logger  string         `json:"logger,omitempty"`
+	Message string         `json:"message"`
+	Caller  string         `json:"caller,omitempty"`
+	Fields  map[string]any `json:"fields,omitempty"`
+}
+
 type LoggedEntry struct {
 	entry               zapcore.Entry
 	consoleEncodedEntry string
+	fields              map[string]any
 }
 
 func (e *LoggedEntry) ConsoleEncodedEntry() string {
 	return e.consoleEncodedEntry
 }
 
+// LogEntry returns the structured representation of the logged entry.
+func (e *LoggedEntry) LogEntry() LogEntry {
+	logEntry := LogEntry{
+		Time:    e.entry.Time,
+		Level:   e.entry.Level.String(),
+		Logger:  e.entry.LoggerName,
+		Message: e.entry.Message,
+		Fields:  e.fields,
+	}
+	if e.entry.Caller.Defined {
+		logEntry.Caller = e.entry.Caller.TrimmedPath()
+	}
+	return logEntry
+}
+
 type ObservedLogs struct {
 	mu   sync.RWMutex
 	logs []LoggedEntry
+	// discarded tells the entries that must not be observed, if set.
+	discarded func(LoggedEntry) bool
 }
 
 func (o *ObservedLogs) Len() int {
@@ -64,6 +94,16 @@ func (o *ObservedLogs) TakeAllString() string {
 	return s.String()
 }
 
+// TakeAllEntries is like TakeAllString, but returns the structured log entries.
+func (o *ObservedLogs) TakeAllEntries() []LogEntry {
+	all := o.TakeAll()
+	entries := make([]LogEntry, 0, len(all))
+	for _, entry := range all {
+		entries = append(entries, entry.LogEntry())
+	}
+	return entries
+}
+
 func (o *ObservedLogs) TakeAll() []LoggedEntry {
 	o.mu.Lock()
 	ret := o.logs
@@ -74,7 +114,16 @@ func (o *ObservedLogs) TakeAll() []LoggedEntry {
 
 func (o *ObservedLogs) add(log LoggedEntry) {
 	o.mu.Lock()
-	o.logs = append(o.logs, log)
+	if o.discarded == nil || !o.discarded(log) {
+		o.logs = append(o.logs, log)
+	}
+	o.mu.Unlock()
+}
+
+// discardWhere discards the entries added from now on that match fn.
+func (o *ObservedLogs) discardWhere(fn func(LoggedEntry) bool) {
+	o.mu.Lock()
+	o.discarded = fn
 	o.mu.Unlock()
 }
 
@@ -110,22 +159,50 @@ func (co *contextObserver) Check(ent zapcore.Entry, ce *zapcore.CheckedEntry) *z
 func (co *contextObserver) With(fields []zapcore.Field) zapcore.Core {
 	return &contextObserver{
 		LevelEnabler: co.LevelEnabler,
+		config:       co.config,
 		logs:         co.logs,
 		context:      append(co.context[:len(co.context):len(co.context)], fields...),
 	}
 }
 
 func (co *contextObserver) Write(entry zapcore.Entry, fields []zapcore.Field) error {
+	allFields := append(co.context[:len(co.context):len(co.context)], fields...)
+
 	encoder := zapcore.NewConsoleEncoder(co.config)
-	encodedEntryBuffer, err := encoder.EncodeEntry(entry, fields)
+	encodedEntryBuffer, err := encoder.EncodeEntry(entry, allFields)
 	if err != nil {
 		return err
 	}
 
-	co.logs.add(LoggedEntry{entry, encodedEntryBuffer.String()})
+	structuredFields, err := encodeFields(allFields)
+	if err != nil {
+		return err
+	}
+
+	co.logs.add(LoggedEntry{entry, encodedEntryBuffer.String(), structuredFields})
 	return nil
 }
 
+// encodeFields converts the given fields into a JSON compatible map.
+func encodeFields(fields []zapcore.Field) (map[string]any, error) {
+	if len(fields) == 0 {
+		return nil, nil
+	}
+
+	encoder := zapcore.NewJSONEncoder(zapcore.EncoderConfig{})
+	encodedFieldsBuffer, err := encoder.EncodeEntry(zapcore.Entry{}, fields)
+	if err != nil {
+		return nil, err
+	}
+	defer encodedFieldsBuffer.Free()
+
+	var encodedFields map[string]any
+	if err = json.Unmarshal(encodedFieldsBuffer.Bytes(), &encodedFields); err != nil {
+		return nil, err
+	}
+	return encodedFields, nil
+}
+
 func (co *contextObserver) Sync() error {
 	return nil
 }
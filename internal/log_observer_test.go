@@ -104,6 +104,18 @@ func Test_ObservedLogs_add(t *testing.T) {
 	assert.Equal(t, entry.consoleEncodedEntry, result[0].consoleEncodedEntry)
 }
 
+func Test_ObservedLogs_discardWhere(t *testing.T) {
+	observedLogs := &ObservedLogs{}
+	observedLogs.discardWhere(func(entry LoggedEntry) bool {
+		return entry.consoleEncodedEntry == "discarded"
+	})
+
+	observedLogs.add(LoggedEntry{consoleEncodedEntry: "kept"})
+	observedLogs.add(LoggedEntry{consoleEncodedEntry: "discarded"})
+
+	assert.Equal(t, "kept", observedLogs.TakeAllString())
+}
+
 func Test_NewLogObserver(t *testing.T) {
 	level := zap.DebugLevel
 	config := zap.NewDevelopmentEncoderConfig()
@@ -202,3 +214,48 @@ func Test_contextObserver_Sync(t *testing.T) {
 	err := contextObserver.Sync()
 	assert.NoError(t, err)
 }
+
+func Test_contextObserver_WriteWithContext(t *testing.T) {
+	core, logs := NewLogObserver(zap.DebugLevel, zap.NewDevelopmentEncoderConfig())
+	logger := zap.New(core).With(zap.String("context.key", "context value"))
+
+	logger.Info("test message", zap.Int("key", 1))
+
+	require.Len(t, logs.All(), 1)
+	loggedEntry := logs.All()[0]
+	assert.Contains(t, loggedEntry.ConsoleEncodedEntry(), `"context.key": "context value"`)
+	assert.Equal(t, map[string]any{"context.key": "context value", "key": float64(1)}, loggedEntry.fields)
+}
+
+func Test_LoggedEntry_LogEntry(t *testing.T) {
+	core, logs := NewLogObserver(zap.DebugLevel, zap.NewDevelopmentEncoderConfig())
+	logger := zap.New(core, zap.AddCaller()).Named("processor")
+
+	logger.Warn("failed to execute statement", zap.String("statement", `set(attributes["a"], 1)`))
+
+	require.Len(t, logs.All(), 1)
+	loggedEntry := logs.All()[0]
+	logEntry := loggedEntry.LogEntry()
+	assert.Equal(t, "warn", logEntry.Level)
+	assert.Equal(t, "processor", logEntry.Logger)
+	assert.Equal(t, "failed to execute statement", logEntry.Message)
+	assert.Contains(t, logEntry.Caller, "log_observer_test.go")
+	assert.False(t, logEntry.Time.IsZero())
+	assert.Equal(t, map[string]any{"statement": `set(attributes["a"], 1)`}, logEntry.Fields)
+}
+
+func Test_ObservedLogs_TakeAllEntries(t *testing.T) {
+	core, logs := NewLogObserver(zap.DebugLevel, zap.NewDevelopmentEncoderConfig())
+	logger := zap.New(core)
+
+	logger.Debug("debug message")
+	logger.Error("error message", zap.Error(assert.AnError))
+
+	entries := logs.TakeAllEntries()
+	require.Len(t, entries, 2)
+	assert.Equal(t, "debug", entries[0].Level)
+	assert.Nil(t, entries[0].Fields)
+	assert.Equal(t, "error", entries[1].Level)
+	assert.Equal(t, assert.AnError.Error(), entries[1].Fields["error"])
+	assert.Equal(t, 0, logs.Len())
+}
@@ -0,0 +1,117 @@
+/*
+ * Copyright Elasticsearch B.V. and/or licensed to Elasticsearch B.V. under one
+ * or more contributor license agreements. See the NOTICE file distributed with
+ * this work for additional information regarding copyright
+ * ownership. Elasticsearch B.V. licenses this file to you under
+ * the Apache License, Version 2.0 (the "License"); you may
+ * not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *	http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing,
+ * software distributed under the License is distributed on an
+ * "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+ * KIND, either express or implied.  See the License for the
+ * specific language governing permissions and limitations
+ * under the License.
+ */
+
+package internal
+
+import (
+	"context"
+	"sync"
+
+	"go.opentelemetry.io/collector/component"
+	"go.opentelemetry.io/collector/extension"
+	"go.opentelemetry.io/collector/extension/xextension/storage"
+)
+
+const memoryStorageExtensionType = "memory_storage"
+
+type memoryStorageConfig struct{}
+
+func newMemoryStorageExtensionFactory() extension.Factory {
+	return extension.NewFactory(
+		component.MustNewType(memoryStorageExtensionType),
+		func() component.Config {
+			return &memoryStorageConfig{}
+		},
+		func(context.Context, extension.Settings, component.Config) (extension.Extension, error) {
+			return &memoryStorageExtension{clients: map[string]*memoryStorageClient{}}, nil
+		},
+		component.StabilityLevelDevelopment,
+	)
+}
+
+// memoryStorageExtension is a storage.Extension keeping the data in memory,
+// so it only lasts for the execution.
+type memoryStorageExtension struct {
+	component.StartFunc
+	component.ShutdownFunc
+
+	mu      sync.Mutex
+	clients map[string]*memoryStorageClient
+}
+
+var _ storage.Extension = (*memoryStorageExtension)(nil)
+
+// GetClient returns the client for the given component and storage name.
+// Clients requested with the same arguments share the same data.
+func (m *memoryStorageExtension) GetClient(_ context.Context, kind component.Kind, id component.ID, storageName string) (storage.Client, error) {
+	key := kind.String() + "/" + id.String()
+	if storageName != "" {
+		key += "/" + storageName
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	client, ok := m.clients[key]
+	if !ok {
+		client = &memoryStorageClient{data: map[string][]byte{}}
+		m.clients[key] = client
+	}
+	return client, nil
+}
+
+type memoryStorageClient struct {
+	mu   sync.Mutex
+	data map[string][]byte
+}
+
+var _ storage.Client = (*memoryStorageClient)(nil)
+
+func (c *memoryStorageClient) Get(ctx context.Context, key string) ([]byte, error) {
+	op := storage.GetOperation(key)
+	err := c.Batch(ctx, op)
+	return op.Value, err
+}
+
+func (c *memoryStorageClient) Set(ctx context.Context, key string, value []byte) error {
+	return c.Batch(ctx, storage.SetOperation(key, value))
+}
+
+func (c *memoryStorageClient) Delete(ctx context.Context, key string) error {
+	return c.Batch(ctx, storage.DeleteOperation(key))
+}
+
+func (c *memoryStorageClient) Batch(_ context.Context, ops ...*storage.Operation) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	for _, op := range ops {
+		switch op.Type {
+		case storage.Get:
+			op.Value = c.data[op.Key]
+		case storage.Set:
+			c.data[op.Key] = op.Value
+		case storage.Delete:
+			delete(c.data, op.Key)
+		}
+	}
+	return nil
+}
+
+func (c *memoryStorageClient) Close(context.Context) error {
+	return nil
+}
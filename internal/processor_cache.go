@@ -0,0 +1,172 @@
+/*
+ * Copyright Elasticsearch B.V. and/or licensed to Elasticsearch B.V. under one
+ * or more contributor license agreements. See the NOTICE file distributed with
+ * this work for additional information regarding copyright
+ * ownership. Elasticsearch B.V. licenses this file to you under
+ * the Apache License, Version 2.0 (the "License"); you may
+ * not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *	http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing,
+ * software distributed under the License is distributed on an
+ * "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+ * KIND, either express or implied.  See the License for the
+ * specific language governing permissions and limitations
+ * under the License.
+ */
+
+package internal
+
+import (
+	"context"
+	"sync"
+	"sync/atomic"
+
+	"go.opentelemetry.io/collector/component"
+	"go.opentelemetry.io/collector/component/componenttest"
+	"go.opentelemetry.io/collector/pdata/pmetric"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+)
+
+// processorCacheSize is the number of started processors kept by each consumer.
+const processorCacheSize = 16
+
+// processorCacheKey identifies the processors that can be reused by an execution.
+type processorCacheKey struct {
+	signal     string
+	configHash string
+	configKey  string
+	fixedTime  bool
+}
+
+// cachedProcessor is a started processor kept across the executions running the
+// same configuration, so its OTTL statements are compiled only once. Only the
+// processors that don't do anything when shutting down are kept, see
+// withReusableProcessors. It's used
+// by one execution at a time, to which its logs, internal telemetry and emitted
+// data are routed.
+type cachedProcessor struct {
+	mu                sync.Mutex
+	closed            bool
+	processor         component.Component
+	telemetrySettings component.TelemetrySettings
+	telemetry         *internalTelemetry
+	logCore           *switchableCore
+	emitFunc          func(any)
+}
+
+func newCachedProcessor() *cachedProcessor {
+	logCore := newSwitchableCore()
+	telemetry := newInternalTelemetry()
+
+	telemetrySettings := componenttest.NewNopTelemetrySettings()
+	telemetrySettings.Logger = newLogger(logCore)
+	telemetrySettings.MeterProvider = telemetry.meterProvider
+	telemetrySettings.TracerProvider = telemetry.tracerProvider
+
+	return &cachedProcessor{
+		telemetrySettings: telemetrySettings,
+		telemetry:         telemetry,
+		logCore:           logCore,
+	}
+}
+
+// acquire routes the processor's output to the given execution, returning false
+// if it's used by another execution or was evicted.
+func (c *cachedProcessor) acquire(exec *execution, emit func(any)) bool {
+	if !c.mu.TryLock() {
+		return false
+	}
+	if c.closed {
+		c.mu.Unlock()
+		return false
+	}
+	c.logCore.set(exec.logCore)
+	c.emitFunc = emit
+	return true
+}
+
+// emit passes the data emitted by the processor to the execution using it.
+func (c *cachedProcessor) emit(data any) {
+	if c.emitFunc != nil {
+		c.emitFunc(data)
+	}
+}
+
+// release hands the internal telemetry recorded while the execution used the
+// processor over to it, and makes the processor available to other executions.
+func (c *cachedProcessor) release(exec *execution) {
+	metrics, err := c.telemetry.collectMetrics()
+	if err != nil {
+		exec.TelemetrySettings().Logger.Warn("[playground] Failed to collect internal metrics", zap.Error(err))
+		metrics = pmetric.NewMetrics()
+	}
+	exec.addInternalTelemetry(metrics, c.telemetry.collectSpans())
+
+	c.logCore.set(zapcore.NewNopCore())
+	c.emitFunc = nil
+	c.mu.Unlock()
+}
+
+// close shuts the processor down, once no execution uses it anymore, and
+// returns the error it failed to shut down with, if any.
+func (c *cachedProcessor) close() error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.closed = true
+	defer c.telemetry.shutdown()
+	if c.processor == nil {
+		return nil
+	}
+	return c.processor.Shutdown(context.Background())
+}
+
+// switchableCore is a zapcore.Core writing to a target core that can be
+// switched, so long-lived components log to the execution using them.
+type switchableCore struct {
+	target *atomic.Pointer[zapcore.Core]
+	fields []zapcore.Field
+}
+
+func newSwitchableCore() *switchableCore {
+	core := &switchableCore{target: &atomic.Pointer[zapcore.Core]{}}
+	core.set(zapcore.NewNopCore())
+	return core
+}
+
+func (c *switchableCore) set(core zapcore.Core) {
+	c.target.Store(&core)
+}
+
+func (c *switchableCore) core() zapcore.Core {
+	return *c.target.Load()
+}
+
+func (c *switchableCore) Enabled(level zapcore.Level) bool {
+	return c.core().Enabled(level)
+}
+
+func (c *switchableCore) With(fields []zapcore.Field) zapcore.Core {
+	return &switchableCore{
+		target: c.target,
+		fields: append(c.fields[:len(c.fields):len(c.fields)], fields...),
+	}
+}
+
+func (c *switchableCore) Check(entry zapcore.Entry, checked *zapcore.CheckedEntry) *zapcore.CheckedEntry {
+	if c.Enabled(entry.Level) {
+		return checked.AddCore(entry, c)
+	}
+	return checked
+}
+
+func (c *switchableCore) Write(entry zapcore.Entry, fields []zapcore.Field) error {
+	return c.core().With(c.fields).Write(entry, fields)
+}
+
+func (c *switchableCore) Sync() error {
+	return c.core().Sync()
+}
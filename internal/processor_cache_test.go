@@ -0,0 +1,72 @@
+/*
+ * Copyright Elasticsearch B.V. and/or licensed to Elasticsearch B.V. under one
+ * or more contributor license agreements. See the NOTICE file distributed with
+ * this work for additional information regarding copyright
+ * ownership. Elasticsearch B.V. licenses this file to you under
+ * the Apache License, Version 2.0 (the "License"); you may
+ * not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *	http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing,
+ * software distributed under the License is distributed on an
+ * "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+ * KIND, either express or implied.  See the License for the
+ * specific language governing permissions and limitations
+ * under the License.
+ */
+
+package internal
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+func Test_switchableCore(t *testing.T) {
+	core := newSwitchableCore()
+	logger := newLogger(core).With(zap.String("component", "test"))
+
+	// Discarded while no execution uses it
+	logger.Info("discarded")
+
+	exec := newExecution()
+	defer exec.close()
+	core.set(exec.logCore)
+	logger.Info("routed")
+
+	entries := exec.ObservedLogs().TakeAllEntries()
+	require.Len(t, entries, 1)
+	assert.Equal(t, "routed", entries[0].Message)
+	assert.Equal(t, "test", entries[0].Fields["component"])
+}
+
+func Test_cachedProcessor_acquire(t *testing.T) {
+	cached := newCachedProcessor()
+	first := newExecution()
+	defer first.close()
+	second := newExecution()
+	defer second.close()
+
+	var emitted []any
+	require.True(t, cached.acquire(first, func(data any) { emitted = append(emitted, data) }))
+	assert.False(t, cached.acquire(second, nil), "used by another execution")
+
+	cached.emit("data")
+	cached.telemetrySettings.Logger.Info("first execution log")
+	cached.release(first)
+	cached.emit("discarded")
+	assert.Equal(t, []any{"data"}, emitted)
+	assert.Len(t, first.ObservedLogs().TakeAllEntries(), 1)
+
+	require.True(t, cached.acquire(second, nil))
+	cached.release(second)
+	assert.Empty(t, second.ObservedLogs().TakeAllEntries())
+
+	cached.close()
+	assert.False(t, cached.acquire(second, nil), "closed")
+}
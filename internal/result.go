@@ -21,18 +21,95 @@ package internal
 
 import (
 	"fmt"
+	"strings"
 	"time"
+
+	"go.opentelemetry.io/collector/pdata/pmetric"
+	"go.opentelemetry.io/collector/pdata/ptrace"
+	"go.uber.org/zap"
 )
 
 type Result struct {
-	Value         string  `json:"value"`
-	JSON          *string `json:"json,omitempty"`
-	ExecutionTime int64   `json:"executionTime"`
-	Error         string  `json:"error,omitempty"`
-	Logs          string  `json:"logs"`
-	Debug         bool    `json:"debug"`
-	Line          int64   `json:"line"`
-	start         time.Time
+	Value              string             `json:"value"`
+	Batches            []string           `json:"batches,omitempty"`
+	JSON               *string            `json:"json,omitempty"`
+	ExecutionTime      int64              `json:"executionTime"`
+	Error              string             `json:"error,omitempty"`
+	Logs               string             `json:"logs"`
+	LogEntries         []LogEntry         `json:"logEntries,omitempty"`
+	Warnings           []StatementWarning `json:"warnings,omitempty"`
+	InternalMetrics    string             `json:"internalMetrics,omitempty"`
+	InternalSpans      string             `json:"internalSpans,omitempty"`
+	ShutdownErrors     []ComponentError   `json:"shutdownErrors,omitempty"`
+	Cancelled          bool               `json:"cancelled,omitempty"`
+	CancellationReason string             `json:"cancellationReason,omitempty"`
+	Deterministic      *bool              `json:"deterministic,omitempty"`
+	Cache              CacheStatus        `json:"cache,omitempty"`
+	Timings            *Timings           `json:"timings,omitempty"`
+	Benchmark          *Benchmark         `json:"benchmark,omitempty"`
+	Profile            []StatementCost    `json:"profile,omitempty"`
+	Filtered           []FilteredItem     `json:"filtered,omitempty"`
+	Where              *WhereReport       `json:"where,omitempty"`
+	Changes            []FieldChange      `json:"changes,omitempty"`
+	Step               *DebugStep         `json:"step,omitempty"`
+	Caches             []RecordCache      `json:"caches,omitempty"`
+	Paused             *DebugPause        `json:"paused,omitempty"`
+	Debug              bool               `json:"debug"`
+	Line               int64              `json:"line"`
+	start              time.Time
+}
+
+// CacheStatus tells whether an execution reused the configuration and the
+// components cached by a previous one with the same configuration.
+type CacheStatus string
+
+const (
+	CacheStatusHit  CacheStatus = "hit"
+	CacheStatusMiss CacheStatus = "miss"
+)
+
+// Timings breaks down the time spent by an execution, in microseconds.
+type Timings struct {
+	// Parse is the time spent unmarshalling the configuration.
+	Parse int64 `json:"parse"`
+	// Validate is the time spent validating the configuration.
+	Validate int64 `json:"validate"`
+	// Create is the time spent creating the components, which compiles the
+	// OTTL statements.
+	Create int64 `json:"create"`
+	// Start is the time spent starting the components.
+	Start int64 `json:"start"`
+	// Consume is the time spent running the components on the payload.
+	Consume int64 `json:"consume"`
+	// Marshal is the time spent marshalling the output.
+	Marshal int64 `json:"marshal"`
+	// Compile is the time spent preparing the components, that is, the sum of
+	// Parse, Validate, Create and Start.
+	Compile int64 `json:"compile"`
+	// Execution is the time spent running the components, that is, Consume.
+	Execution int64 `json:"execution"`
+}
+
+// add adds the given timings to these ones.
+func (t *Timings) add(other *Timings) {
+	if other == nil {
+		return
+	}
+	t.Parse += other.Parse
+	t.Validate += other.Validate
+	t.Create += other.Create
+	t.Start += other.Start
+	t.Consume += other.Consume
+	t.Marshal += other.Marshal
+	t.Compile += other.Compile
+	t.Execution += other.Execution
+}
+
+// ComponentError is an error returned by a component outside of the data
+// processing, such as when shutting it down.
+type ComponentError struct {
+	ComponentID string `json:"componentID"`
+	Error       string `json:"error"`
 }
 
 func NewErrorResult(err string, logs string) *Result {
@@ -56,6 +133,54 @@ func (r *Result) stopTimer() {
 	r.ExecutionTime = time.Since(r.start).Milliseconds()
 }
 
+// takeLogs moves the logs observed so far into the result, both console-encoded
+// and as structured entries.
+func (r *Result) takeLogs(observable Observable) {
+	all := observable.ObservedLogs().TakeAll()
+	var logs strings.Builder
+	r.LogEntries = make([]LogEntry, 0, len(all))
+	for _, entry := range all {
+		logs.WriteString(entry.ConsoleEncodedEntry())
+		r.LogEntries = append(r.LogEntries, entry.LogEntry())
+	}
+	r.Logs = logs.String()
+}
+
+// collectTelemetry moves the telemetry observed so far by the execution, such
+// as logs, the components' internal metrics and spans, their shutdown errors,
+// and the execution's timings, into the result.
+func (r *Result) collectTelemetry(exec *execution) {
+	r.ShutdownErrors = exec.takeShutdownErrors()
+	r.Cache = exec.cacheStatus
+	r.Timings = exec.takeTimings()
+
+	metrics, err := exec.collectMetrics()
+	if err != nil {
+		exec.TelemetrySettings().Logger.Warn("[playground] Failed to collect internal metrics", zap.Error(err))
+	} else if metrics.MetricCount() > 0 {
+		metricsMarshaler := pmetric.JSONMarshaler{}
+		metricsBytes, err := metricsMarshaler.MarshalMetrics(metrics)
+		if err != nil {
+			exec.TelemetrySettings().Logger.Warn("[playground] Failed to marshal internal metrics", zap.Error(err))
+		} else {
+			r.InternalMetrics = string(metricsBytes)
+		}
+	}
+
+	spans := exec.collectSpans()
+	if spans.SpanCount() > 0 {
+		tracesMarshaler := ptrace.JSONMarshaler{}
+		tracesBytes, err := tracesMarshaler.MarshalTraces(spans)
+		if err != nil {
+			exec.TelemetrySettings().Logger.Warn("[playground] Failed to marshal internal spans", zap.Error(err))
+		} else {
+			r.InternalSpans = string(tracesBytes)
+		}
+	}
+
+	r.takeLogs(exec)
+}
+
 func (r *Result) AsRaw() map[string]any {
 	res, err := structToMap(r)
 	if err != nil {
@@ -67,7 +192,7 @@ func (r *Result) AsRaw() map[string]any {
 }
 
 func newExecutionResult[T any](
-	observable Observable,
+	exec *execution,
 	valueMarshaller func(T) ([]byte, error),
 	command func() (T, error),
 
@@ -76,14 +201,50 @@ func newExecutionResult[T any](
 	res.start = time.Now()
 	b, err := command()
 	if err != nil {
-		return nil, err
+		cause := exec.cancellationCause()
+		if cause == nil {
+			res.collectTelemetry(exec)
+			return res, err
+		}
+		exec.TelemetrySettings().Logger.Warn("[playground] Execution cancelled, the result is partial", zap.Error(cause))
+		res.Cancelled = true
+		res.CancellationReason = cause.Error()
 	}
 	res.ExecutionTime = time.Since(res.start).Milliseconds()
+	marshalStart := time.Now()
 	valueBytes, err := valueMarshaller(b)
 	if err != nil {
 		return nil, err
 	}
 	res.Value = string(valueBytes)
-	res.Logs = observable.ObservedLogs().TakeAllString()
+	res.Batches, err = marshalBatches(exec, valueMarshaller)
+	if err != nil {
+		return nil, err
+	}
+	exec.timePhase(phaseMarshal, marshalStart)
+	res.collectTelemetry(exec)
 	return res, nil
 }
+
+// marshalBatches returns the batches emitted by the component, when it emitted
+// more than one. Otherwise, the result's value already is the only batch.
+func marshalBatches[T any](exec *execution, valueMarshaller func(T) ([]byte, error)) ([]string, error) {
+	recorded := exec.takeBatches()
+	if len(recorded) < 2 {
+		return nil, nil
+	}
+
+	batches := make([]string, 0, len(recorded))
+	for _, batch := range recorded {
+		value, ok := batch.(T)
+		if !ok {
+			continue
+		}
+		batchBytes, err := valueMarshaller(value)
+		if err != nil {
+			return nil, err
+		}
+		batches = append(batches, string(batchBytes))
+	}
+	return batches, nil
+}
@@ -20,6 +20,7 @@
 package internal
 
 import (
+	"context"
 	"encoding/json"
 	"errors"
 	"testing"
@@ -27,6 +28,9 @@ import (
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/collector/component"
+	"go.opentelemetry.io/collector/pdata/pmetric"
+	"go.opentelemetry.io/collector/pdata/ptrace"
 )
 
 func Test_NewErrorResult(t *testing.T) {
@@ -136,20 +140,9 @@ func Test_Result_AsRaw_WithNilJSON(t *testing.T) {
 	assert.False(t, jsonExists, "json field should not exist when nil")
 }
 
-// Mock Observable for testing
-type mockObservable struct {
-	observedLogs *ObservedLogs
-}
-
-func (m *mockObservable) ObservedLogs() *ObservedLogs {
-	return m.observedLogs
-}
-
 func Test_newExecutionResult_Success(t *testing.T) {
-	observedLogs := &ObservedLogs{}
-	observedLogs.add(LoggedEntry{consoleEncodedEntry: "test log entry"})
-
-	mock := &mockObservable{observedLogs: observedLogs}
+	exec := newExecution()
+	exec.observedLogs.add(LoggedEntry{consoleEncodedEntry: "test log entry"})
 
 	valueMarshaller := func(input string) ([]byte, error) {
 		return []byte(input), nil
@@ -160,18 +153,19 @@ func Test_newExecutionResult_Success(t *testing.T) {
 		return "test result", nil
 	}
 
-	result, err := newExecutionResult(mock, valueMarshaller, command)
+	result, err := newExecutionResult(exec, valueMarshaller, command)
 
 	require.NoError(t, err)
 	require.NotNil(t, result)
 	assert.Equal(t, "test result", result.Value)
 	assert.Greater(t, result.ExecutionTime, int64(0))
 	assert.Equal(t, "test log entry", result.Logs)
+	assert.Len(t, result.LogEntries, 1)
 }
 
 func Test_newExecutionResult_CommandError(t *testing.T) {
-	observedLogs := &ObservedLogs{}
-	mock := &mockObservable{observedLogs: observedLogs}
+	exec := newExecution()
+	exec.observedLogs.add(LoggedEntry{consoleEncodedEntry: "failure log entry"})
 
 	valueMarshaller := func(input string) ([]byte, error) {
 		return []byte(input), nil
@@ -182,15 +176,16 @@ func Test_newExecutionResult_CommandError(t *testing.T) {
 		return "", expectedError
 	}
 
-	result, err := newExecutionResult(mock, valueMarshaller, command)
+	result, err := newExecutionResult(exec, valueMarshaller, command)
 
 	assert.Equal(t, expectedError, err)
-	assert.Nil(t, result)
+	require.NotNil(t, result)
+	assert.Empty(t, result.Value)
+	assert.Equal(t, "failure log entry", result.Logs)
 }
 
 func Test_newExecutionResult_MarshallerError(t *testing.T) {
-	observedLogs := &ObservedLogs{}
-	mock := &mockObservable{observedLogs: observedLogs}
+	exec := newExecution()
 
 	expectedError := errors.New("marshaller failed")
 	valueMarshaller := func(input string) ([]byte, error) {
@@ -201,15 +196,14 @@ func Test_newExecutionResult_MarshallerError(t *testing.T) {
 		return "test result", nil
 	}
 
-	result, err := newExecutionResult(mock, valueMarshaller, command)
+	result, err := newExecutionResult(exec, valueMarshaller, command)
 
 	assert.Equal(t, expectedError, err)
 	assert.Nil(t, result)
 }
 
 func Test_newExecutionResult_WithComplexType(t *testing.T) {
-	observedLogs := &ObservedLogs{}
-	mock := &mockObservable{observedLogs: observedLogs}
+	exec := newExecution()
 
 	type complexType struct {
 		Name  string `json:"name"`
@@ -224,7 +218,7 @@ func Test_newExecutionResult_WithComplexType(t *testing.T) {
 		return complexType{Name: "test", Value: 42}, nil
 	}
 
-	result, err := newExecutionResult(mock, valueMarshaller, command)
+	result, err := newExecutionResult(exec, valueMarshaller, command)
 
 	require.NoError(t, err)
 	require.NotNil(t, result)
@@ -273,3 +267,102 @@ func Test_Result_MultipleTimerCalls(t *testing.T) {
 	assert.Greater(t, secondTime, int64(0))
 	assert.Greater(t, secondTime, firstTime) // Second timing should be longer
 }
+
+func Test_newExecutionResult_InternalMetrics(t *testing.T) {
+	exec := newExecution()
+	counter, err := exec.TelemetrySettings().MeterProvider.Meter("test").Int64Counter("test.counter")
+	require.NoError(t, err)
+
+	command := func() (string, error) {
+		counter.Add(context.Background(), 2)
+		return "test result", nil
+	}
+	valueMarshaller := func(input string) ([]byte, error) {
+		return []byte(input), nil
+	}
+
+	result, err := newExecutionResult(exec, valueMarshaller, command)
+	require.NoError(t, err)
+	require.NotEmpty(t, result.InternalMetrics)
+
+	unmarshaler := &pmetric.JSONUnmarshaler{}
+	metrics, err := unmarshaler.UnmarshalMetrics([]byte(result.InternalMetrics))
+	require.NoError(t, err)
+	require.Equal(t, 1, metrics.MetricCount())
+
+	metric := metrics.ResourceMetrics().At(0).ScopeMetrics().At(0).Metrics().At(0)
+	assert.Equal(t, "test.counter", metric.Name())
+	assert.Equal(t, int64(2), metric.Sum().DataPoints().At(0).IntValue())
+
+	// Collected metrics are only reported once
+	result, err = newExecutionResult(exec, valueMarshaller, func() (string, error) { return "", nil })
+	require.NoError(t, err)
+	assert.Empty(t, result.InternalMetrics)
+}
+
+func Test_newExecutionResult_InternalSpans(t *testing.T) {
+	exec := newExecution()
+	tracer := exec.TelemetrySettings().TracerProvider.Tracer("test")
+
+	command := func() (string, error) {
+		_, span := tracer.Start(context.Background(), "test.span")
+		span.End()
+		return "test result", nil
+	}
+	valueMarshaller := func(input string) ([]byte, error) {
+		return []byte(input), nil
+	}
+
+	result, err := newExecutionResult(exec, valueMarshaller, command)
+	require.NoError(t, err)
+	require.NotEmpty(t, result.InternalSpans)
+
+	unmarshaler := &ptrace.JSONUnmarshaler{}
+	traces, err := unmarshaler.UnmarshalTraces([]byte(result.InternalSpans))
+	require.NoError(t, err)
+	require.Equal(t, 1, traces.SpanCount())
+
+	scopeSpans := traces.ResourceSpans().At(0).ScopeSpans().At(0)
+	assert.Equal(t, "test", scopeSpans.Scope().Name())
+	assert.Equal(t, "test.span", scopeSpans.Spans().At(0).Name())
+
+	// Collected spans are only reported once
+	result, err = newExecutionResult(exec, valueMarshaller, func() (string, error) { return "", nil })
+	require.NoError(t, err)
+	assert.Empty(t, result.InternalSpans)
+}
+
+func Test_newExecutionResult_ShutdownErrors(t *testing.T) {
+	exec := newExecution()
+	command := func() (string, error) {
+		exec.addShutdownError(component.MustNewID("transform"), errors.New("shutdown failure"))
+		return "test result", nil
+	}
+	valueMarshaller := func(input string) ([]byte, error) {
+		return []byte(input), nil
+	}
+
+	result, err := newExecutionResult(exec, valueMarshaller, command)
+	require.NoError(t, err)
+	assert.Equal(t, []ComponentError{{ComponentID: "transform", Error: "shutdown failure"}}, result.ShutdownErrors)
+}
+
+func Test_newExecutionResult_Timings(t *testing.T) {
+	exec := newExecution()
+	valueMarshaller := func(input string) ([]byte, error) {
+		time.Sleep(time.Millisecond)
+		return []byte(input), nil
+	}
+
+	result, err := newExecutionResult(exec, valueMarshaller, func() (string, error) { return "test result", nil })
+	require.NoError(t, err)
+	require.NotNil(t, result.Timings)
+	assert.GreaterOrEqual(t, result.Timings.Marshal, int64(1000))
+}
+
+func Test_Timings_add(t *testing.T) {
+	timings := &Timings{Parse: 1, Validate: 2, Create: 3, Start: 4, Consume: 5, Marshal: 6, Compile: 10, Execution: 5}
+	timings.add(&Timings{Parse: 1, Validate: 1, Create: 1, Start: 1, Consume: 1, Marshal: 1, Compile: 4, Execution: 1})
+	timings.add(nil)
+	assert.Equal(t, &Timings{Parse: 2, Validate: 3, Create: 4, Start: 5, Consume: 6, Marshal: 7, Compile: 14, Execution: 6}, timings)
+}
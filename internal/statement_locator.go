@@ -0,0 +1,299 @@
+/*
+ * Copyright Elasticsearch B.V. and/or licensed to Elasticsearch B.V. under one
+ * or more contributor license agreements. See the NOTICE file distributed with
+ * this work for additional information regarding copyright
+ * ownership. Elasticsearch B.V. licenses this file to you under
+ * the Apache License, Version 2.0 (the "License"); you may
+ * not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *	http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing,
+ * software distributed under the License is distributed on an
+ * "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+ * KIND, either express or implied.  See the License for the
+ * specific language governing permissions and limitations
+ * under the License.
+ */
+
+package internal
+
+import (
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+
+	"go.opentelemetry.io/collector/component"
+	"go.uber.org/zap/zapcore"
+	"gopkg.in/yaml.v3"
+)
+
+// StatementLocation identifies a statement or condition in the YAML configuration.
+type StatementLocation struct {
+	// ConfigKey is the key of the configuration holding the statement, or empty
+	// if the configuration isn't keyed by component ID.
+	ConfigKey string `json:"configKey,omitempty"`
+	// Path is the dot-separated path of the list holding the statement, such as
+	// "log_statements.0.statements" or "logs.log_record".
+	Path string `json:"path"`
+	// Index is the position of the statement within the list at Path.
+	Index int `json:"index"`
+	// Line is the statement's line in the YAML configuration.
+	Line int64 `json:"line"`
+}
+
+// StatementWarning is a warning logged by a component while running a statement
+// or condition, attached to the location of that statement in the configuration.
+type StatementWarning struct {
+	StatementLocation
+	Statement string `json:"statement"`
+	Message   string `json:"message"`
+	Error     string `json:"error,omitempty"`
+}
+
+type locatedStatement struct {
+	location StatementLocation
+	text     string
+	// context is the OTTL context explicitly set for the statement's group, if any.
+	context string
+	// inferred is the OTTL context inferred from the paths used by the whole
+	// group of the statement, if it has no explicit context.
+	inferred string
+	// prefixed is the text of the statement with the context prefixed to its
+	// context-less paths, as the components log it.
+	prefixed string
+}
+
+// statementLocator finds the location of statements and conditions in a YAML
+// configuration by their text.
+type statementLocator struct {
+	statements []locatedStatement
+}
+
+func newStatementLocator[C any](yamlConfig string, cfgs []parsedConfig[C]) (*statementLocator, error) {
+	var root yaml.Node
+	if err := yaml.Unmarshal([]byte(yamlConfig), &root); err != nil {
+		return nil, fmt.Errorf("failed to parse YAML: %w", err)
+	}
+	if root.Kind != yaml.DocumentNode || len(root.Content) == 0 {
+		return nil, fmt.Errorf("unexpected YAML structure")
+	}
+
+	locator := &statementLocator{}
+	for _, cfg := range cfgs {
+		node := root.Content[0]
+		if cfg.Key != "" {
+			node = mappingValue(node, cfg.Key)
+			if node == nil {
+				return nil, fmt.Errorf("'%s' not found in the configuration", cfg.Key)
+			}
+		}
+		locator.collect(cfg.Key, node, nil, "", "")
+	}
+	return locator, nil
+}
+
+func (l *statementLocator) collect(configKey string, node *yaml.Node, path []string, context, inferred string) {
+	switch node.Kind {
+	case yaml.MappingNode:
+		if contextNode := mappingValue(node, "context"); contextNode != nil && contextNode.Kind == yaml.ScalarNode {
+			context = contextNode.Value
+		}
+		// The conditions and statements of a group share the inferred context.
+		if context == "" && mappingValue(node, "statements") != nil {
+			inferred = groupContext(statementGroup{
+				conditions: scalarValues(mappingValue(node, "conditions")),
+				statements: scalarValues(mappingValue(node, "statements")),
+			})
+		}
+		for i := 0; i+1 < len(node.Content); i += 2 {
+			l.collect(configKey, node.Content[i+1], append(path[:len(path):len(path)], node.Content[i].Value), context, inferred)
+		}
+	case yaml.SequenceNode:
+		// The plain statements of a list make up a group of their own.
+		plainInferred := inferred
+		if context == "" && inferred == "" {
+			plainInferred = groupContext(statementGroup{statements: scalarValues(node)})
+		}
+		for i, item := range node.Content {
+			if item.Kind != yaml.ScalarNode {
+				l.collect(configKey, item, append(path[:len(path):len(path)], strconv.Itoa(i)), context, inferred)
+				continue
+			}
+			statement := locatedStatement{
+				location: StatementLocation{
+					ConfigKey: configKey,
+					Path:      strings.Join(path, "."),
+					Index:     i,
+					Line:      int64(item.Line),
+				},
+				text:     strings.ReplaceAll(item.Value, "$$", "$"),
+				context:  context,
+				inferred: plainInferred,
+			}
+			statement.prefixed = prependPathsContext(statementContext(statement), statement.text)
+			l.statements = append(l.statements, statement)
+		}
+	}
+}
+
+// scalarValues returns the values of the scalar items of the sequence node, if
+// it's one.
+func scalarValues(node *yaml.Node) []string {
+	if node == nil || node.Kind != yaml.SequenceNode {
+		return nil
+	}
+	var values []string
+	for _, item := range node.Content {
+		if item.Kind == yaml.ScalarNode {
+			values = append(values, strings.ReplaceAll(item.Value, "$$", "$"))
+		}
+	}
+	return values
+}
+
+// ottlKeywords are the lowercase OTTL words which aren't paths.
+var ottlKeywords = []string{"where", "and", "or", "not", "true", "false", "nil"}
+
+// prependPathsContext returns the OTTL statement or condition with the given
+// context prefixed to the paths that have none, as the OTTL parser does when
+// the components parse them with their context. Paths start with a lowercase
+// identifier, which isn't a function name, a named argument, nor a keyword.
+func prependPathsContext(context, statement string) string {
+	if context == "" {
+		return statement
+	}
+
+	var prefixed strings.Builder
+	inString := false
+	for i := 0; i < len(statement); i++ {
+		c := statement[i]
+		switch {
+		case inString:
+			if c == '\\' && i+1 < len(statement) {
+				prefixed.WriteByte(c)
+				i++
+				c = statement[i]
+			} else if c == '"' {
+				inString = false
+			}
+		case c == '"':
+			inString = true
+		case isIdentifierByte(c) && (i == 0 || !isIdentifierByte(statement[i-1]) && statement[i-1] != '.'):
+			end := i
+			for end < len(statement) && isIdentifierByte(statement[end]) {
+				end++
+			}
+			word := statement[i:end]
+			if isPathStart(word, statement[end:]) && !slices.Contains(inferredContexts, word) && word != "instrumentation_scope" {
+				prefixed.WriteString(context + ".")
+			}
+			prefixed.WriteString(word)
+			i = end - 1
+			continue
+		}
+		prefixed.WriteByte(c)
+	}
+	return prefixed.String()
+}
+
+// isPathStart reports whether the identifier, followed by rest, starts a path.
+func isPathStart(word, rest string) bool {
+	if word[0] < 'a' || word[0] > 'z' || slices.Contains(ottlKeywords, word) {
+		return false
+	}
+	rest = strings.TrimLeft(rest, " \t")
+	if strings.HasPrefix(rest, "(") {
+		return false
+	}
+	// Named arguments are followed by a single equal sign.
+	return !strings.HasPrefix(rest, "=") || strings.HasPrefix(rest, "==")
+}
+
+// locate returns the location of the given statement within the configuration
+// with the given key, as written or as logged by the components, with the
+// context prefixed to its paths. Identical statements can't be told apart, so
+// the first one is returned.
+func (l *statementLocator) locate(configKey, statement string) (StatementLocation, bool) {
+	for _, s := range l.statements {
+		if s.location.ConfigKey == configKey && (s.text == statement || s.prefixed == statement) {
+			return s.location, true
+		}
+	}
+	return StatementLocation{}, false
+}
+
+// locateLine is like locate, but only returns the statement at the given line,
+// telling identical statements apart.
+func (l *statementLocator) locateLine(configKey string, line int64, statement string) (StatementLocation, bool) {
+	for _, s := range l.statements {
+		if s.location.ConfigKey == configKey && s.location.Line == line && s.text == statement {
+			return s.location, true
+		}
+	}
+	return StatementLocation{}, false
+}
+
+// locateIndex returns the location of the statement at the given index of the
+// list at the given path, within the configuration with the given key.
+func (l *statementLocator) locateIndex(configKey, path string, index int) (StatementLocation, bool) {
+	for _, s := range l.statements {
+		if s.location.ConfigKey == configKey && s.location.Path == path && s.location.Index == index {
+			return s.location, true
+		}
+	}
+	return StatementLocation{}, false
+}
+
+// warnings returns the statement warnings found in the given log entries. The
+// componentID is the ID used by the consumer when the configuration isn't keyed.
+func (l *statementLocator) warnings(componentID component.ID, entries []LogEntry) []StatementWarning {
+	var warnings []StatementWarning
+	for _, entry := range entries {
+		level, err := zapcore.ParseLevel(entry.Level)
+		if err != nil || level < zapcore.WarnLevel {
+			continue
+		}
+
+		statement, ok := entry.Fields["statement"].(string)
+		if !ok {
+			statement, ok = entry.Fields["condition"].(string)
+		}
+		if !ok {
+			continue
+		}
+
+		configKey, _ := entry.Fields[componentIDLogField].(string)
+		if configKey == componentID.String() {
+			configKey = ""
+		}
+
+		location, ok := l.locate(configKey, statement)
+		if !ok {
+			continue
+		}
+
+		warning := StatementWarning{
+			StatementLocation: location,
+			Statement:         statement,
+			Message:           entry.Message,
+		}
+		warning.Error, _ = entry.Fields["error"].(string)
+		warnings = append(warnings, warning)
+	}
+	return warnings
+}
+
+func mappingValue(node *yaml.Node, key string) *yaml.Node {
+	if node.Kind != yaml.MappingNode {
+		return nil
+	}
+	for i := 0; i+1 < len(node.Content); i += 2 {
+		if node.Content[i].Value == key {
+			return node.Content[i+1]
+		}
+	}
+	return nil
+}
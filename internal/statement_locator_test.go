@@ -0,0 +1,193 @@
+/*
+ * Copyright Elasticsearch B.V. and/or licensed to Elasticsearch B.V. under one
+ * or more contributor license agreements. See the NOTICE file distributed with
+ * this work for additional information regarding copyright
+ * ownership. Elasticsearch B.V. licenses this file to you under
+ * the Apache License, Version 2.0 (the "License"); you may
+ * not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *	http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing,
+ * software distributed under the License is distributed on an
+ * "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+ * KIND, either express or implied.  See the License for the
+ * specific language governing permissions and limitations
+ * under the License.
+ */
+
+package internal
+
+import (
+	"testing"
+
+	"github.com/open-telemetry/opentelemetry-collector-contrib/processor/filterprocessor"
+	"github.com/open-telemetry/opentelemetry-collector-contrib/processor/transformprocessor"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/collector/component"
+)
+
+func Test_statementLocator_locate(t *testing.T) {
+	config := `transform:
+  log_statements:
+    - context: log
+      statements:
+        - set(attributes["a"], "a")
+        - replace_pattern(body, "my.(.+)", "$$1")
+  trace_statements:
+    - set(span.attributes["b"], "b")`
+
+	cfgs := []parsedConfig[transformprocessor.Config]{{Key: ""}}
+	locator, err := newStatementLocator(config, cfgs)
+	require.NoError(t, err)
+
+	location, ok := locator.locate("", `replace_pattern(body, "my.(.+)", "$1")`)
+	require.True(t, ok)
+	assert.Equal(t, StatementLocation{Path: "transform.log_statements.0.statements", Index: 1, Line: 6}, location)
+
+	location, ok = locator.locate("", `set(span.attributes["b"], "b")`)
+	require.True(t, ok)
+	assert.Equal(t, StatementLocation{Path: "transform.trace_statements", Index: 0, Line: 8}, location)
+
+	_, ok = locator.locate("", `set(attributes["unknown"], "a")`)
+	assert.False(t, ok)
+}
+
+func Test_statementLocator_locate_MultipleConfigs(t *testing.T) {
+	config := readTestData(t, "config_multiple.yaml")
+	cfgs := []parsedConfig[transformprocessor.Config]{{Key: "transform"}, {Key: "transform/b"}, {Key: "transform/a"}}
+
+	locator, err := newStatementLocator(config, cfgs)
+	require.NoError(t, err)
+
+	location, ok := locator.locate("transform/a", `set(attributes["foo"], "bar")`)
+	require.True(t, ok)
+	assert.Equal(t, StatementLocation{ConfigKey: "transform/a", Path: "trace_statements.0.statements", Index: 0, Line: 34}, location)
+
+	location, ok = locator.locate("transform/b", `set(attributes["foo"], "bar")`)
+	require.True(t, ok)
+	assert.Equal(t, StatementLocation{ConfigKey: "transform/b", Path: "trace_statements.0.statements", Index: 0, Line: 20}, location)
+}
+
+func Test_statementLocator_locate_FilterConditions(t *testing.T) {
+	config := `filter:
+  logs:
+    log_record:
+      - severity_number < SEVERITY_NUMBER_INFO
+      - IsMatch(body, ".*debug.*")`
+
+	cfgs := []parsedConfig[filterprocessor.Config]{{Key: ""}}
+	locator, err := newStatementLocator(config, cfgs)
+	require.NoError(t, err)
+
+	location, ok := locator.locate("", `IsMatch(body, ".*debug.*")`)
+	require.True(t, ok)
+	assert.Equal(t, StatementLocation{Path: "filter.logs.log_record", Index: 1, Line: 5}, location)
+}
+
+func Test_statementLocator_locateIndex(t *testing.T) {
+	config := `filter:
+  logs:
+    resource:
+      - severity_number < SEVERITY_NUMBER_INFO
+    log_record:
+      - severity_number < SEVERITY_NUMBER_INFO`
+
+	cfgs := []parsedConfig[filterprocessor.Config]{{Key: "filter"}}
+	locator, err := newStatementLocator(config, cfgs)
+	require.NoError(t, err)
+
+	// Identical conditions are told apart by their list
+	location, ok := locator.locateIndex("filter", "logs.log_record", 0)
+	require.True(t, ok)
+	assert.Equal(t, StatementLocation{ConfigKey: "filter", Path: "logs.log_record", Index: 0, Line: 6}, location)
+
+	_, ok = locator.locateIndex("filter", "logs.log_record", 1)
+	assert.False(t, ok)
+}
+
+func Test_statementLocator_locate_UnknownConfigKey(t *testing.T) {
+	cfgs := []parsedConfig[transformprocessor.Config]{{Key: "transform/unknown"}}
+	_, err := newStatementLocator("transform:\n  log_statements: []", cfgs)
+	assert.ErrorContains(t, err, "'transform/unknown' not found in the configuration")
+}
+
+func Test_statementLocator_warnings(t *testing.T) {
+	config := `transform/a:
+  log_statements:
+    - set(log.attributes["a"], ParseJSON(log.body))
+transform/b:
+  log_statements:
+    - set(log.attributes["a"], ParseJSON(log.body))`
+
+	cfgs := []parsedConfig[transformprocessor.Config]{{Key: "transform/a"}, {Key: "transform/b"}}
+	locator, err := newStatementLocator(config, cfgs)
+	require.NoError(t, err)
+
+	componentID := component.MustNewIDWithName("transform", "ottl_playground")
+	entries := []LogEntry{
+		{Level: "debug", Message: "debug entry", Fields: map[string]any{"statement": `set(log.attributes["a"], ParseJSON(log.body))`}},
+		{Level: "warn", Message: "entry without statement"},
+		{
+			Level:   "warn",
+			Message: "failed to execute statement",
+			Fields: map[string]any{
+				"statement":         `set(log.attributes["a"], ParseJSON(log.body))`,
+				"error":             "invalid JSON",
+				componentIDLogField: "transform/b",
+			},
+		},
+	}
+
+	warnings := locator.warnings(componentID, entries)
+	require.Len(t, warnings, 1)
+	assert.Equal(t, StatementWarning{
+		StatementLocation: StatementLocation{ConfigKey: "transform/b", Path: "log_statements", Index: 0, Line: 6},
+		Statement:         `set(log.attributes["a"], ParseJSON(log.body))`,
+		Message:           "failed to execute statement",
+		Error:             "invalid JSON",
+	}, warnings[0])
+}
+
+func Test_statementLocator_warnings_ContextLessPaths(t *testing.T) {
+	config := `log_statements:
+  - context: log
+    statements:
+      - merge_maps(attributes, ParseJSON(body), "upsert")
+  - set(attributes["a"], resource.attributes["a"]) where log.body != nil`
+
+	locator, err := newStatementLocator(config, []parsedConfig[transformprocessor.Config]{{}})
+	require.NoError(t, err)
+
+	componentID := component.MustNewIDWithName("transform", "ottl_playground")
+	entries := []LogEntry{
+		{Level: "warn", Message: "failed to execute statement", Fields: map[string]any{"statement": `merge_maps(log.attributes, ParseJSON(log.body), "upsert")`}},
+		{Level: "warn", Message: "failed to execute statement", Fields: map[string]any{"statement": `set(log.attributes["a"], resource.attributes["a"]) where log.body != nil`}},
+	}
+
+	warnings := locator.warnings(componentID, entries)
+	require.Len(t, warnings, 2)
+	assert.Equal(t, StatementLocation{Path: "log_statements.0.statements", Index: 0, Line: 4}, warnings[0].StatementLocation)
+	assert.Equal(t, StatementLocation{Path: "log_statements", Index: 1, Line: 5}, warnings[1].StatementLocation)
+}
+
+func Test_prependPathsContext(t *testing.T) {
+	tests := []struct {
+		statement string
+		want      string
+	}{
+		{`set(attributes["a"], body)`, `set(log.attributes["a"], log.body)`},
+		{`set(log.attributes["a"], resource.attributes["b"])`, `set(log.attributes["a"], resource.attributes["b"])`},
+		{`set(attributes["body"], "body") where body == nil and not IsMatch(body, "a\"b")`, `set(log.attributes["body"], "body") where log.body == nil and not IsMatch(log.body, "a\"b")`},
+		{`replace_pattern(body, "a", "b", function = SHA256)`, `replace_pattern(log.body, "a", "b", function = SHA256)`},
+		{`set(severity_number, SEVERITY_NUMBER_INFO) where severity_number == 0`, `set(log.severity_number, SEVERITY_NUMBER_INFO) where log.severity_number == 0`},
+		{`set(cache["a"], body.string)`, `set(log.cache["a"], log.body.string)`},
+		{`set(attributes["a"], instrumentation_scope.name)`, `set(log.attributes["a"], instrumentation_scope.name)`},
+	}
+	for _, tt := range tests {
+		assert.Equal(t, tt.want, prependPathsContext("log", tt.statement))
+	}
+	assert.Equal(t, `set(attributes["a"], 1)`, prependPathsContext("", `set(attributes["a"], 1)`))
+}
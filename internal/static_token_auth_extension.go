@@ -0,0 +1,129 @@
+/*
+ * Copyright Elasticsearch B.V. and/or licensed to Elasticsearch B.V. under one
+ * or more contributor license agreements. See the NOTICE file distributed with
+ * this work for additional information regarding copyright
+ * ownership. Elasticsearch B.V. licenses this file to you under
+ * the Apache License, Version 2.0 (the "License"); you may
+ * not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *	http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing,
+ * software distributed under the License is distributed on an
+ * "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+ * KIND, either express or implied.  See the License for the
+ * specific language governing permissions and limitations
+ * under the License.
+ */
+
+package internal
+
+import (
+	"context"
+	"errors"
+	"net/http"
+
+	"go.opentelemetry.io/collector/client"
+	"go.opentelemetry.io/collector/component"
+	"go.opentelemetry.io/collector/extension"
+	"go.opentelemetry.io/collector/extension/extensionauth"
+)
+
+const staticTokenAuthExtensionType = "static_token_auth"
+
+var errUnauthenticated = errors.New("missing or invalid authentication token")
+
+type staticTokenAuthConfig struct {
+	// Token is the token clients must send, and the one sent by this
+	// extension when used as a client authenticator.
+	Token string `mapstructure:"token"`
+	// Header is the header holding the token, defaults to "authorization".
+	Header string `mapstructure:"header"`
+	// Scheme is the token prefix, defaults to "Bearer".
+	Scheme string `mapstructure:"scheme"`
+	// Attributes are the auth attributes set on the client.Info of the
+	// authenticated requests.
+	Attributes map[string]string `mapstructure:"attributes"`
+}
+
+func (c *staticTokenAuthConfig) Validate() error {
+	if c.Token == "" {
+		return errors.New("token must not be empty")
+	}
+	return nil
+}
+
+func newStaticTokenAuthExtensionFactory() extension.Factory {
+	return extension.NewFactory(
+		component.MustNewType(staticTokenAuthExtensionType),
+		func() component.Config {
+			return &staticTokenAuthConfig{
+				Header: "authorization",
+				Scheme: "Bearer",
+			}
+		},
+		func(_ context.Context, _ extension.Settings, cfg component.Config) (extension.Extension, error) {
+			return &staticTokenAuthExtension{config: cfg.(*staticTokenAuthConfig)}, nil
+		},
+		component.StabilityLevelDevelopment,
+	)
+}
+
+// staticTokenAuthExtension authenticates requests carrying a fixed token, and
+// adds it to the outgoing HTTP requests.
+type staticTokenAuthExtension struct {
+	component.StartFunc
+	component.ShutdownFunc
+
+	config *staticTokenAuthConfig
+}
+
+var (
+	_ extensionauth.Server     = (*staticTokenAuthExtension)(nil)
+	_ extensionauth.HTTPClient = (*staticTokenAuthExtension)(nil)
+)
+
+func (s *staticTokenAuthExtension) headerValue() string {
+	if s.config.Scheme == "" {
+		return s.config.Token
+	}
+	return s.config.Scheme + " " + s.config.Token
+}
+
+// Authenticate checks the configured header holds the token, and returns a
+// context whose client.Info carries the configured auth attributes.
+func (s *staticTokenAuthExtension) Authenticate(ctx context.Context, sources map[string][]string) (context.Context, error) {
+	values := http.Header(sources).Values(s.config.Header)
+	if len(values) == 0 {
+		values = sources[s.config.Header]
+	}
+	for _, value := range values {
+		if value == s.headerValue() {
+			info := client.FromContext(ctx)
+			attributes := make(authData, len(s.config.Attributes))
+			for k, v := range s.config.Attributes {
+				attributes[k] = v
+			}
+			info.Auth = attributes
+			return client.NewContext(ctx, info), nil
+		}
+	}
+	return ctx, errUnauthenticated
+}
+
+// RoundTripper returns a http.RoundTripper setting the token header on the
+// requests.
+func (s *staticTokenAuthExtension) RoundTripper(base http.RoundTripper) (http.RoundTripper, error) {
+	return roundTripperFunc(func(req *http.Request) (*http.Response, error) {
+		req = req.Clone(req.Context())
+		req.Header.Set(s.config.Header, s.headerValue())
+		return base.RoundTrip(req)
+	}), nil
+}
+
+type roundTripperFunc func(*http.Request) (*http.Response, error)
+
+func (f roundTripperFunc) RoundTrip(req *http.Request) (*http.Response, error) {
+	return f(req)
+}
@@ -0,0 +1,450 @@
+/*
+ * Copyright Elasticsearch B.V. and/or licensed to Elasticsearch B.V. under one
+ * or more contributor license agreements. See the NOTICE file distributed with
+ * this work for additional information regarding copyright
+ * ownership. Elasticsearch B.V. licenses this file to you under
+ * the Apache License, Version 2.0 (the "License"); you may
+ * not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *	http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing,
+ * software distributed under the License is distributed on an
+ * "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+ * KIND, either express or implied.  See the License for the
+ * specific language governing permissions and limitations
+ * under the License.
+ */
+
+package internal
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/open-telemetry/opentelemetry-collector-contrib/processor/transformprocessor"
+	"go.opentelemetry.io/collector/component"
+)
+
+// transformDebugStep is a step of a transform processor debug session, running
+// either a condition of a statement group, or one of its statements.
+type transformDebugStep struct {
+	cfg parsedConfig[transformprocessor.Config]
+	// group is the step's group, holding the inferred context if it has none.
+	group   statementGroup
+	stash   cacheStash
+	stashed bool
+	mark    conditionsMark
+	marked  bool
+	step    DebugStep
+	line    int64
+}
+
+// restore returns the statements restoring the cache left by the previous
+// statements of the group, if any.
+func (s transformDebugStep) restore() []string {
+	if !s.stashed || s.step.Condition || s.step.Index == 0 {
+		return nil
+	}
+	return s.stash.restore
+}
+
+// conditions returns the conditions gating the statement step. Only the first
+// statement of the group evaluates the group's conditions, the following ones
+// apply to the records it marked.
+func (s transformDebugStep) conditions() []string {
+	if !s.marked || s.step.Index == 0 {
+		return s.group.conditions
+	}
+	return []string{s.mark.condition}
+}
+
+// last reports whether the step runs the last statement of its group.
+func (s transformDebugStep) last() bool {
+	return s.step.Index+1 == len(s.group.statements)
+}
+
+// annotated reports whether the payload the step runs on holds the attributes
+// the debugger stores its data in, left by the previous statements of the
+// group.
+func (s transformDebugStep) annotated() bool {
+	return !s.step.Condition && s.step.Index > 0 && (s.stashed || s.marked)
+}
+
+// statements returns the statements the step runs: its statement, surrounded
+// by the ones restoring and saving the group's cache and mark, if needed.
+func (s transformDebugStep) statements() []string {
+	return s.surround(s.group.statements[s.step.Index])
+}
+
+// surround returns the given statements, surrounded by the ones restoring and
+// saving the group's cache and mark before and after the step, if needed.
+func (s transformDebugStep) surround(inner ...string) []string {
+	var statements []string
+	if s.marked && s.step.Index > 0 {
+		// The mark isn't shown to the statement.
+		statements = append(statements, s.mark.remove)
+	}
+	statements = append(statements, s.restore()...)
+	statements = append(statements, inner...)
+	if s.stashed {
+		statements = append(statements, s.stash.save)
+	}
+	if s.marked && !s.last() {
+		statements = append(statements, s.mark.set)
+	}
+	return statements
+}
+
+// transformDebugSession runs the steps of a transform processor configuration
+// one at a time, each of them on the output of the previous one.
+type transformDebugSession[T any] struct {
+	signal        transformSignal[T]
+	options       []ExecutionOption
+	exec          *execution
+	consumerID    component.ID
+	locator       *statementLocator
+	deterministic bool
+	breakpoints   []Breakpoint
+	steps         []transformDebugStep
+	next          int
+	payload       T
+	// previous is the output of the last statement step, which the changes of
+	// the next one are diffed with.
+	previous map[string]any
+	closed   bool
+}
+
+func newTransformDebugSession[T any](
+	consumer *processorConsumer[transformprocessor.Config],
+	config, input string,
+	options []ExecutionOption,
+	signal transformSignal[T],
+	breakpoints []Breakpoint,
+) (session *transformDebugSession[T], err error) {
+	restoreFeatureGates, err := applyFeatureGates(options...)
+	if err != nil {
+		return nil, err
+	}
+	defer restoreFeatureGates()
+
+	exec := newExecution(options...)
+	defer func() {
+		if err != nil {
+			exec.close()
+		}
+	}()
+
+	configs, err := parseExecutionConfig(exec, consumer.id, config, consumer.CreateDefaultConfig)
+	if err != nil {
+		return nil, err
+	}
+
+	locator, err := newStatementLocator(config, configs)
+	if err != nil {
+		return nil, err
+	}
+
+	if err = exec.startExtensions(config); err != nil {
+		return nil, err
+	}
+
+	payload, err := signal.unmarshal([]byte(input))
+	if err != nil {
+		return nil, err
+	}
+
+	exec.ObservedLogs().discardWhere(isDebuggerEntry)
+
+	// Each step's changes are diffed with the output of the previous one.
+	marshalled, err := signal.marshal(payload)
+	if err != nil {
+		return nil, err
+	}
+	previous, err := parsePayloadJSON(marshalled)
+	if err != nil {
+		return nil, err
+	}
+
+	session = &transformDebugSession[T]{
+		signal:        signal,
+		options:       options,
+		exec:          exec,
+		consumerID:    consumer.id,
+		locator:       locator,
+		deterministic: locator.deterministic(exec.hasFixedTime(), transformProcessorFixedTimeContexts),
+		breakpoints:   breakpoints,
+		payload:       payload,
+		previous:      previous,
+	}
+	for _, cfg := range configs {
+		for g, group := range signal.groups(cfg.Value) {
+			groupNode, err := findYAMLGroup(config, cfg.Key, signal.configKey, g)
+			if err != nil {
+				return nil, fmt.Errorf("failed to find YAML path index: %w", err)
+			}
+			statementsNode := groupNode
+			if statements := mappingValue(groupNode, "statements"); statements != nil {
+				statementsNode = statements
+			}
+			// A single statement may infer another context than its group.
+			step := DebugStep{ConfigKey: cfg.Key, Context: group.context, Group: g}
+			if group.context == "" {
+				group.context = groupContext(group)
+				step.Context, step.InferredContext = group.context, true
+			}
+
+			// The group's conditions are steps of their own, reporting the
+			// records they match without changing the payload.
+			conditionsNode := mappingValue(groupNode, "conditions")
+			for c := range group.conditions {
+				conditionStep := transformDebugStep{cfg: cfg, group: group, step: step}
+				conditionStep.step.Index, conditionStep.step.Condition = c, true
+				if conditionsNode != nil && c < len(conditionsNode.Content) {
+					conditionStep.line = int64(conditionsNode.Content[c].Line)
+				}
+				session.steps = append(session.steps, conditionStep)
+			}
+
+			stash, stashed := newCacheStash(group)
+			mark, marked := newConditionsMark(group)
+			for i := range group.statements {
+				statementStep := transformDebugStep{cfg: cfg, group: group, stash: stash, stashed: stashed, mark: mark, marked: marked, step: step}
+				statementStep.step.Index = i
+				statementStep.line = int64(statementsNode.Content[i].Line)
+				session.steps = append(session.steps, statementStep)
+			}
+		}
+	}
+	return session, nil
+}
+
+func (s *transformDebugSession[T]) Continue() (*Result, error) {
+	return s.run(false, false)
+}
+
+func (s *transformDebugSession[T]) Step() (*Result, error) {
+	return s.run(false, true)
+}
+
+func (s *transformDebugSession[T]) Close() {
+	if !s.closed {
+		s.closed = true
+		s.exec.close()
+	}
+}
+
+// done reports whether the session ran all of its steps.
+func (s *transformDebugSession[T]) done() bool {
+	return s.next >= len(s.steps)
+}
+
+// run runs the next step of the session, and the following ones up to the
+// next breakpoint, or only the next one if stepping. The first run of the
+// session stops before the first step if a breakpoint is set on it.
+func (s *transformDebugSession[T]) run(first, stepping bool) (*Result, error) {
+	if s.closed {
+		return nil, fmt.Errorf("the debug session is closed")
+	}
+	// The gates set while parsing the configuration are restored once the
+	// session is created, so every run sets them again.
+	restoreFeatureGates, err := applyFeatureGates(s.options...)
+	if err != nil {
+		return nil, err
+	}
+	defer restoreFeatureGates()
+	if !first {
+		s.exec.renewContext()
+	}
+
+	res := &Result{}
+	res.Debug = true
+	deterministic := s.deterministic
+	res.Deterministic = &deterministic
+	// The first run's timings are added to the configuration parsing ones.
+	res.Timings = s.exec.takeTimings()
+	var results []*Result
+	for !s.done() {
+		if first || len(results) > 0 {
+			breakpoint, selected, err := s.breakpoint()
+			if err != nil {
+				return nil, err
+			}
+			if breakpoint >= 0 || stepping {
+				if res.Paused, err = s.pause(breakpoint, selected); err != nil {
+					return nil, err
+				}
+				break
+			}
+		}
+
+		result, err := s.runStep()
+		if err != nil {
+			s.next = len(s.steps)
+			return result, err
+		}
+		results = append(results, result)
+		res.Timings.add(result.Timings)
+		if result.Cancelled {
+			// The payload may have been partially modified by the step.
+			s.next = len(s.steps)
+			res.Cancelled = true
+			res.CancellationReason = result.CancellationReason
+			break
+		}
+	}
+
+	marshal, err := json.Marshal(results)
+	if err != nil {
+		return nil, err
+	}
+
+	res.Value = string(marshal)
+	return res, nil
+}
+
+// runStep runs the next step of the session.
+func (s *transformDebugSession[T]) runStep() (*Result, error) {
+	step := s.steps[s.next]
+	s.next++
+	if step.step.Condition {
+		return s.runCondition(step)
+	}
+	return s.runStatement(step)
+}
+
+// runCondition reports the records matched by a condition of the group,
+// without changing the payload.
+func (s *transformDebugSession[T]) runCondition(step transformDebugStep) (*Result, error) {
+	exec := s.exec
+	condition := step.group.conditions[step.step.Index]
+	var where *WhereReport
+	var probeErr error
+	result, err := newExecutionResult(exec, s.signal.marshal, func() (T, error) {
+		where, probeErr = probeCondition(exec, step.cfg, step.step.Group, s.signal, step.group.context, nil, nil, condition, s.payload)
+		return s.payload, exec.checkCancelled()
+	})
+	if err != nil {
+		return result, err
+	}
+	result.Line = step.line
+	result.Step = &step.step
+	result.Where = where
+	if probeErr != nil && !result.Cancelled {
+		result.Error = probeErr.Error()
+	}
+	return result, nil
+}
+
+// runStatement runs a statement of the group, on the records matched by the
+// group's conditions before its first statement, and with the group's cache as
+// left by the previous statement. If the group uses the cache, the result
+// reports the cache of each record after the statement.
+func (s *transformDebugSession[T]) runStatement(step transformDebugStep) (*Result, error) {
+	exec := s.exec
+	group := step.group
+	statement := group.statements[step.step.Index]
+	stepConfig, err := debugStep(step.cfg, step.step.Group, s.signal, statementGroup{
+		context:    group.context,
+		conditions: step.conditions(),
+		statements: step.statements(),
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	where := probeWhereClause(exec, step.cfg, step.step.Group, s.signal, group.context, step.conditions(), step.restore(), statement, s.payload)
+	var caches []RecordCache
+	result, err := newExecutionResult(exec, s.signal.marshal, func() (T, error) {
+		// The output is marshalled before the next step modifies it.
+		s.payload, err = s.signal.consume(exec, stepConfig, s.payload)
+		if err != nil || !(step.stashed || step.marked && !step.last()) {
+			return s.payload, err
+		}
+		if step.stashed {
+			caches = s.signal.caches(s.payload, group.context)
+		}
+		stripped, err := stripDebuggerAttributes(exec, step.cfg, step.step.Group, s.signal, group.context, s.payload)
+		if err == nil && step.last() {
+			// The stash and the mark are only used by the following statements.
+			s.payload = stripped
+		}
+		return stripped, err
+	})
+	if err != nil {
+		return result, err
+	}
+	result.Line = step.line
+	result.Step = &step.step
+	result.Where = where
+	result.Caches = caches
+	current, err := parsePayloadJSON([]byte(result.Value))
+	if err != nil {
+		return nil, err
+	}
+	result.Changes = diffPayloads(s.previous, current)
+	s.previous = current
+	result.Warnings = s.locator.warnings(s.consumerID, result.LogEntries)
+	return result, nil
+}
+
+// breakpoint returns the index of the first breakpoint set on the next step
+// whose selector, if any, matches any of the records the step applies to, and
+// the matched records. It returns -1 if there is none.
+func (s *transformDebugSession[T]) breakpoint() (int, [][]int, error) {
+	step := s.steps[s.next]
+	for b, breakpoint := range s.breakpoints {
+		if !breakpoint.matches(step.step, step.line) {
+			continue
+		}
+		if breakpoint.Selector == "" {
+			return b, nil, nil
+		}
+		conditions := step.conditions()
+		if step.step.Condition {
+			conditions = nil
+		}
+		report, err := probeCondition(s.exec, step.cfg, step.step.Group, s.signal, step.group.context, conditions, step.restore(), breakpoint.Selector, s.payload)
+		if err != nil {
+			return -1, nil, fmt.Errorf("failed to evaluate the selector of breakpoint %d: %w", b, err)
+		}
+		if report != nil && report.Matched > 0 {
+			return b, report.MatchedRecords, nil
+		}
+	}
+	return -1, nil, nil
+}
+
+// pause returns the state of the session before its next step.
+func (s *transformDebugSession[T]) pause(breakpoint int, selected [][]int) (*DebugPause, error) {
+	step := s.steps[s.next]
+	payload := s.payload
+	var caches []RecordCache
+	if step.annotated() {
+		// The snapshot isn't reported, nor accounted in the steps' timings.
+		defer s.exec.takeTimings()
+		defer s.exec.discardTelemetry()
+
+		if step.restore() != nil {
+			caches = s.signal.caches(s.payload, step.group.context)
+		}
+		var err error
+		payload, err = stripDebuggerAttributes(s.exec, step.cfg, step.step.Group, s.signal, step.group.context, s.payload)
+		if err != nil {
+			return nil, err
+		}
+	}
+	marshalled, err := s.signal.marshal(payload)
+	if err != nil {
+		return nil, err
+	}
+	return &DebugPause{
+		Step:       step.step,
+		Line:       step.line,
+		Breakpoint: breakpoint,
+		Payload:    string(marshalled),
+		Caches:     caches,
+		Selected:   selected,
+	}, nil
+}
@@ -0,0 +1,299 @@
+/*
+ * Copyright Elasticsearch B.V. and/or licensed to Elasticsearch B.V. under one
+ * or more contributor license agreements. See the NOTICE file distributed with
+ * this work for additional information regarding copyright
+ * ownership. Elasticsearch B.V. licenses this file to you under
+ * the Apache License, Version 2.0 (the "License"); you may
+ * not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *	http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing,
+ * software distributed under the License is distributed on an
+ * "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+ * KIND, either express or implied.  See the License for the
+ * specific language governing permissions and limitations
+ * under the License.
+ */
+
+package internal
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+
+	"github.com/open-telemetry/opentelemetry-collector-contrib/pkg/ottl"
+	"github.com/open-telemetry/opentelemetry-collector-contrib/pkg/ottl/contexts/ottllog"
+	"github.com/open-telemetry/opentelemetry-collector-contrib/pkg/ottl/ottlfuncs"
+	"github.com/open-telemetry/opentelemetry-collector-contrib/processor/transformprocessor"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_transformProcessorDebugger_StartLogs_Breakpoint(t *testing.T) {
+	debugger := NewTransformProcessorDebugger().(SessionDebugger)
+	config := `log_statements:
+  - context: log
+    statements:
+      - set(cache["a"], body)
+      - set(cache["b"], "x")
+      - set(attributes["a"], cache["a"])`
+	payload := `{"resourceLogs":[{"scopeLogs":[{"logRecords":[{"body":{"stringValue":"a"}},{"body":{"stringValue":"b"}}]}]}]}`
+
+	session, result, err := debugger.StartLogs(config, payload, []Breakpoint{{Line: 6}})
+	require.NoError(t, err)
+	defer session.Close()
+
+	assert.Len(t, stepResults(t, result), 2)
+	require.NotNil(t, result.Paused)
+	assert.Equal(t, DebugStep{Index: 2, Context: "log"}, result.Paused.Step)
+	assert.Equal(t, int64(6), result.Paused.Line)
+	assert.Equal(t, 0, result.Paused.Breakpoint)
+	assert.NotContains(t, result.Paused.Payload, cacheStashKey)
+	assert.Equal(t, []RecordCache{
+		{Index: []int{0, 0, 0}, Cache: map[string]any{"a": "a", "b": "x"}},
+		{Index: []int{0, 0, 1}, Cache: map[string]any{"a": "b", "b": "x"}},
+	}, result.Paused.Caches)
+
+	result, err = session.Step()
+	require.NoError(t, err)
+	assert.Nil(t, result.Paused)
+	steps := stepResults(t, result)
+	require.Len(t, steps, 1)
+	assert.Equal(t, int64(6), steps[0].Line)
+	assert.Contains(t, steps[0].Value, `"attributes":[{"key":"a","value":{"stringValue":"a"}}]`)
+	assert.NotContains(t, steps[0].Value, cacheStashKey)
+}
+
+func Test_transformProcessorDebugger_StartLogs_Step(t *testing.T) {
+	debugger := NewTransformProcessorDebugger().(SessionDebugger)
+	config := `log_statements:
+  - context: log
+    conditions:
+      - body != nil
+    statements:
+      - set(attributes["a"], true)
+      - set(attributes["b"], true)`
+	payload := `{"resourceLogs":[{"scopeLogs":[{"logRecords":[{"body":{"stringValue":"a"}}]}]}]}`
+
+	session, result, err := debugger.StartLogs(config, payload, []Breakpoint{{Line: 4}, {Line: 7}})
+	require.NoError(t, err)
+	defer session.Close()
+
+	// The session pauses before its first step
+	assert.Empty(t, stepResults(t, result))
+	require.NotNil(t, result.Paused)
+	assert.Equal(t, DebugStep{Condition: true, Context: "log"}, result.Paused.Step)
+	assert.NotContains(t, result.Paused.Payload, `"attributes"`)
+
+	result, err = session.Step()
+	require.NoError(t, err)
+	assert.Len(t, stepResults(t, result), 1)
+	require.NotNil(t, result.Paused)
+	assert.Equal(t, int64(6), result.Paused.Line)
+	assert.Equal(t, -1, result.Paused.Breakpoint)
+
+	result, err = session.Step()
+	require.NoError(t, err)
+	require.NotNil(t, result.Paused)
+	assert.Equal(t, int64(7), result.Paused.Line)
+	assert.Equal(t, 1, result.Paused.Breakpoint)
+	assert.Contains(t, result.Paused.Payload, `"a"`)
+
+	result, err = session.Continue()
+	require.NoError(t, err)
+	assert.Len(t, stepResults(t, result), 1)
+	assert.Nil(t, result.Paused)
+
+	result, err = session.Continue()
+	require.NoError(t, err)
+	assert.Empty(t, stepResults(t, result))
+	assert.Nil(t, result.Paused)
+
+	session.Close()
+	_, err = session.Step()
+	assert.ErrorContains(t, err, "the debug session is closed")
+}
+
+func Test_transformProcessorDebugger_StartLogs_Selector(t *testing.T) {
+	debugger := NewTransformProcessorDebugger().(SessionDebugger)
+	config := `log_statements:
+  - context: log
+    statements:
+      - set(attributes["a"], true)
+      - set(attributes["b"], true)`
+	payload := `{"resourceLogs":[{"scopeLogs":[{"logRecords":[{"body":{"stringValue":"a"}},{"body":{"stringValue":"b"}}]}]}]}`
+
+	session, result, err := debugger.StartLogs(config, payload, []Breakpoint{
+		{Line: 4, Selector: `body == "c"`},
+		{Line: 5, Selector: `body == "b"`},
+	})
+	require.NoError(t, err)
+	defer session.Close()
+
+	// The first breakpoint's selector doesn't match any record
+	assert.Len(t, stepResults(t, result), 1)
+	require.NotNil(t, result.Paused)
+	assert.Equal(t, 1, result.Paused.Breakpoint)
+	assert.Equal(t, [][]int{{0, 0, 1}}, result.Paused.Selected)
+
+	_, _, err = debugger.StartLogs(config, payload, []Breakpoint{{Line: 4, Selector: `body ==`}})
+	assert.ErrorContains(t, err, "failed to evaluate the selector of breakpoint 0")
+}
+
+func Test_transformProcessorDebugger_StartLogs_SelectorGroupConditions(t *testing.T) {
+	debugger := NewTransformProcessorDebugger().(SessionDebugger)
+	config := `log_statements:
+  - context: log
+    conditions:
+      - attributes["x"] == nil
+    statements:
+      - set(attributes["x"], 1)
+      - set(attributes["y"], 2)`
+	payload := `{"resourceLogs":[{"scopeLogs":[{"logRecords":[{"body":{"stringValue":"a"}},{"body":{"stringValue":"b"},"attributes":[{"key":"x","value":{"intValue":"1"}}]}]}]}]}`
+
+	// The conditions no longer match once the first statement ran, but the
+	// second one still applies to the records they matched before it
+	session, result, err := debugger.StartLogs(config, payload, []Breakpoint{{Line: 7, Selector: `body == "a"`}})
+	require.NoError(t, err)
+	defer session.Close()
+
+	require.NotNil(t, result.Paused)
+	assert.Equal(t, 0, result.Paused.Breakpoint)
+	assert.Equal(t, [][]int{{0, 0, 0}}, result.Paused.Selected)
+	assert.NotContains(t, result.Paused.Payload, conditionsMatchKey)
+}
+
+func Test_transformProcessorDebugger_StartLogs_SelectorWithoutDebuggerAttributes(t *testing.T) {
+	debugger := NewTransformProcessorDebugger().(SessionDebugger)
+	config := `log_statements:
+  - context: log
+    conditions:
+      - body != nil
+    statements:
+      - set(cache["a"], body)
+      - set(cache["b"], cache["a"])
+      - set(attributes["a"], cache["b"]) where Len(attributes) == 0`
+	payload := `{"resourceLogs":[{"scopeLogs":[{"logRecords":[{"body":{"stringValue":"a"}},{"body":{"stringValue":"b"}}]}]}]}`
+
+	// The records hold the stashed cache and the conditions mark in between
+	// the statements, which the probes don't see
+	session, result, err := debugger.StartLogs(config, payload, []Breakpoint{{Line: 8, Selector: `Len(attributes) == 0 and cache["a"] == "b"`}})
+	require.NoError(t, err)
+	defer session.Close()
+
+	require.NotNil(t, result.Paused)
+	assert.Equal(t, [][]int{{0, 0, 1}}, result.Paused.Selected)
+
+	result, err = session.Continue()
+	require.NoError(t, err)
+	steps := stepResults(t, result)
+	require.Len(t, steps, 1)
+	require.NotNil(t, steps[0].Where)
+	assert.Equal(t, 2, steps[0].Where.Matched)
+}
+
+func Test_transformProcessorDebugger_StartTraces_StatementBreakpoint(t *testing.T) {
+	debugger := NewTransformProcessorDebugger().(SessionDebugger)
+	config := readTestData(t, configMultiple)
+	payload := readTestData(t, "traces.json")
+
+	session, result, err := debugger.StartTraces(config, payload, []Breakpoint{{ConfigKey: "transform/a"}})
+	require.NoError(t, err)
+	defer session.Close()
+
+	assert.Len(t, stepResults(t, result), 3)
+	require.NotNil(t, result.Paused)
+	assert.Equal(t, DebugStep{ConfigKey: "transform/a", Context: "resource"}, result.Paused.Step)
+	assert.Equal(t, int64(34), result.Paused.Line)
+
+	result, err = session.Continue()
+	require.NoError(t, err)
+	assert.Len(t, stepResults(t, result), 1)
+	assert.Nil(t, result.Paused)
+}
+
+func Test_transformProcessorDebugger_StartLogs_NoBreakpoints(t *testing.T) {
+	debugger := NewTransformProcessorDebugger().(SessionDebugger)
+	config := readTestData(t, configMultiple)
+	payload := readTestData(t, "logs.json")
+
+	session, result, err := debugger.StartLogs(config, payload, nil)
+	require.NoError(t, err)
+	defer session.Close()
+
+	debugResult, err := debugger.DebugLogs(config, payload)
+	require.NoError(t, err)
+	assert.Len(t, stepResults(t, result), len(stepResults(t, debugResult)))
+	assert.Nil(t, result.Paused)
+}
+
+func Test_transformProcessorDebugger_StartLogs_InvalidBreakpoint(t *testing.T) {
+	debugger := NewTransformProcessorDebugger().(SessionDebugger)
+	_, _, err := debugger.StartLogs("log_statements: []", "{}", []Breakpoint{{Line: 1}, {}})
+	assert.ErrorContains(t, err, "invalid breakpoint 1: either a line or a config key must be set")
+}
+
+func Test_Breakpoint_validate(t *testing.T) {
+	assert.NoError(t, Breakpoint{Line: 1}.validate())
+	assert.NoError(t, Breakpoint{ConfigKey: "transform"}.validate())
+	assert.Error(t, Breakpoint{}.validate())
+	assert.Error(t, Breakpoint{Line: -1}.validate())
+	assert.Error(t, Breakpoint{Line: 1, ConfigKey: "transform"}.validate())
+	assert.Error(t, Breakpoint{ConfigKey: "transform", Index: -1}.validate())
+}
+
+func Test_Breakpoint_matches(t *testing.T) {
+	step := DebugStep{ConfigKey: "transform/a", Group: 1, Index: 2}
+	assert.True(t, Breakpoint{Line: 5}.matches(step, 5))
+	assert.False(t, Breakpoint{Line: 4}.matches(step, 5))
+	assert.True(t, Breakpoint{ConfigKey: "transform/a", Group: 1, Index: 2}.matches(step, 5))
+	assert.False(t, Breakpoint{ConfigKey: "transform/a", Group: 1, Index: 2, Condition: true}.matches(step, 5))
+	assert.False(t, Breakpoint{ConfigKey: "transform", Group: 1, Index: 2}.matches(step, 5))
+}
+
+// stepResults returns the steps run by a debugger call.
+func stepResults(t *testing.T, result *Result) []*Result {
+	t.Helper()
+	var steps []*Result
+	require.NoError(t, json.Unmarshal([]byte(result.Value), &steps))
+	return steps
+}
+
+func Test_transformProcessorDebugger_FeatureGates(t *testing.T) {
+	// GateEnabled() tells whether the test feature gate is enabled when the
+	// statements run
+	functions := ottlfuncs.StandardFuncs[*ottllog.TransformContext]()
+	functions["GateEnabled"] = ottl.NewFactory("GateEnabled", nil, func(ottl.FunctionContext, ottl.Arguments) (ottl.ExprFunc[*ottllog.TransformContext], error) {
+		return func(context.Context, *ottllog.TransformContext) (any, error) {
+			return testFeatureGate.IsEnabled(), nil
+		}, nil
+	})
+	debugger := &transformProcessorDebugger{newProcessorConsumer[transformprocessor.Config](
+		transformprocessor.NewFactoryWithOptions(transformprocessor.WithLogFunctionsNew(withFixedTimeNow(functions))),
+	)}
+	config := `log_statements:
+  - set(log.attributes["first"], GateEnabled())
+  - set(log.attributes["second"], GateEnabled())`
+	payload := `{"resourceLogs":[{"scopeLogs":[{"logRecords":[{"body":{"stringValue":"a"}}]}]}]}`
+	gates := WithFeatureGates(map[string]bool{testFeatureGate.ID(): true})
+
+	result, err := debugger.DebugLogs(config, payload, gates)
+	require.NoError(t, err)
+	steps := stepResults(t, result)
+	require.Len(t, steps, 2)
+	assert.Contains(t, steps[0].Value, `{"key":"first","value":{"boolValue":true}}`)
+	assert.False(t, testFeatureGate.IsEnabled())
+
+	session, result, err := debugger.StartLogs(config, payload, []Breakpoint{{Line: 3}}, gates)
+	require.NoError(t, err)
+	defer session.Close()
+	assert.Contains(t, stepResults(t, result)[0].Value, `{"key":"first","value":{"boolValue":true}}`)
+
+	result, err = session.Continue()
+	require.NoError(t, err)
+	assert.Contains(t, stepResults(t, result)[0].Value, `{"key":"second","value":{"boolValue":true}}`)
+	assert.False(t, testFeatureGate.IsEnabled())
+}
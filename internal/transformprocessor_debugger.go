@@ -20,14 +20,12 @@
 package internal
 
 import (
-	"encoding/json"
 	"fmt"
+	"regexp"
+	"slices"
+	"strings"
 
 	"github.com/open-telemetry/opentelemetry-collector-contrib/processor/transformprocessor"
-	"go.opentelemetry.io/collector/pdata/plog"
-	"go.opentelemetry.io/collector/pdata/pmetric"
-	"go.opentelemetry.io/collector/pdata/pprofile"
-	"go.opentelemetry.io/collector/pdata/ptrace"
 	"gopkg.in/yaml.v3"
 )
 
@@ -35,7 +33,22 @@ type transformProcessorDebugger struct {
 	consumer *processorConsumer[transformprocessor.Config]
 }
 
+// findYAMLPathIndex returns the node of the statements list of the group at
+// the given index.
 func findYAMLPathIndex(yamlData string, configID, configKey string, configIndex int) (*yaml.Node, error) {
+	current, err := findYAMLGroup(yamlData, configID, configKey, configIndex)
+	if err != nil {
+		return nil, err
+	}
+	if statements := mappingValue(current, "statements"); statements != nil {
+		return statements, nil
+	}
+	return current, nil
+}
+
+// findYAMLGroup returns the node of the group at the given index. Groups of
+// plain statements are returned as the sequence of their statements.
+func findYAMLGroup(yamlData string, configID, configKey string, configIndex int) (*yaml.Node, error) {
 	var root yaml.Node
 	if err := yaml.Unmarshal([]byte(yamlData), &root); err != nil {
 		return nil, fmt.Errorf("failed to parse YAML: %w", err)
@@ -68,292 +81,397 @@ func findYAMLPathIndex(yamlData string, configID, configKey string, configIndex
 		}
 	}
 
-	if current.Kind == yaml.SequenceNode && current.Content[0].Kind == yaml.MappingNode {
-		current = current.Content[configIndex]
+	if current.Kind == yaml.SequenceNode {
+		return sequenceGroup(current, configIndex)
 	}
 
-	if current.Kind == yaml.MappingNode {
-		for i, c := range current.Content {
-			if c.Value == "statements" {
-				current = current.Content[i+1]
-				break
-			}
+	return current, nil
+}
+
+// sequenceGroup returns the node of the group at the given index of a list of
+// statement groups. The processor gathers the plain statements of the list, if
+// any, into a last group of their own, which is returned as a sequence node.
+func sequenceGroup(list *yaml.Node, index int) (*yaml.Node, error) {
+	var groups []*yaml.Node
+	flat := &yaml.Node{Kind: yaml.SequenceNode, Tag: "!!seq"}
+	for _, item := range list.Content {
+		if item.Kind == yaml.ScalarNode {
+			flat.Content = append(flat.Content, item)
+			continue
 		}
+		groups = append(groups, item)
 	}
+	switch {
+	case len(groups) == 0:
+		groups = append(groups, list)
+	case len(flat.Content) > 0:
+		flat.Line, flat.Column = flat.Content[0].Line, flat.Content[0].Column
+		groups = append(groups, flat)
+	}
+	if index < 0 || index >= len(groups) {
+		return nil, fmt.Errorf("statement group %d not found in the configuration", index)
+	}
+	return groups[index], nil
+}
 
-	return current, nil
+func (t transformProcessorDebugger) DebugLogs(config, input string, options ...ExecutionOption) (*Result, error) {
+	return debugStatements(t.consumer, config, input, options, newLogsTransformSignal(t.consumer))
 }
 
-type configWithLine struct {
-	config transformprocessor.Config
-	line   int64
+func (t transformProcessorDebugger) DebugTraces(config, input string, options ...ExecutionOption) (*Result, error) {
+	return debugStatements(t.consumer, config, input, options, newTracesTransformSignal(t.consumer))
 }
 
-func (t transformProcessorDebugger) DebugLogs(config, input string) (*Result, error) {
-	configs, err := parseConfig[transformprocessor.Config](t.consumer.id, config, func() *transformprocessor.Config {
-		return t.consumer.factory.CreateDefaultConfig().(*transformprocessor.Config)
-	})
-	if err != nil {
-		return nil, err
-	}
+func (t transformProcessorDebugger) DebugMetrics(config, input string, options ...ExecutionOption) (*Result, error) {
+	return debugStatements(t.consumer, config, input, options, newMetricsTransformSignal(t.consumer))
+}
 
-	res := &Result{}
-	res.Debug = true
-	var results []*Result
-	for _, cfg := range configs {
-		cfgs := make([]configWithLine, 0, len(configs)*len(cfg.Value.LogStatements))
-		for i, contextStatements := range cfg.Value.LogStatements {
-			pathIndex, err := findYAMLPathIndex(config, cfg.Key, "log_statements", i)
-			if err != nil {
-				return nil, fmt.Errorf("failed to find YAML path index: %w", err)
-			}
-			for i, sv := range contextStatements.Statements {
-				cp, err := cfg.clone()
-				if err != nil {
-					return nil, err
-				}
-				contextStatementsCp := contextStatements
-				contextStatementsCp.Statements = append(contextStatements.Statements[:i], []string{sv}...)
-				cp.LogStatements = nil
-				cp.LogStatements = append(cp.LogStatements, contextStatementsCp)
-				cfgs = append(cfgs, configWithLine{cp, int64(pathIndex.Content[i].Line)})
-			}
-		}
+func (t transformProcessorDebugger) DebugProfiles(config, input string, options ...ExecutionOption) (*Result, error) {
+	return debugStatements(t.consumer, config, input, options, newProfilesTransformSignal(t.consumer))
+}
 
-		um := plog.JSONUnmarshaler{}
-		inputLogs, err := um.UnmarshalLogs([]byte(input))
-		if err != nil {
-			return nil, err
-		}
+func (t transformProcessorDebugger) StartLogs(config, input string, breakpoints []Breakpoint, options ...ExecutionOption) (DebugSession, *Result, error) {
+	return startDebugSession(t.consumer, config, input, breakpoints, options, newLogsTransformSignal(t.consumer))
+}
 
-		ma := plog.JSONMarshaler{}
-		for i, c := range cfgs {
-			cpLogs := plog.NewLogs()
-			inputLogs.CopyTo(cpLogs)
+func (t transformProcessorDebugger) StartTraces(config, input string, breakpoints []Breakpoint, options ...ExecutionOption) (DebugSession, *Result, error) {
+	return startDebugSession(t.consumer, config, input, breakpoints, options, newTracesTransformSignal(t.consumer))
+}
 
-			result, err := newExecutionResult(t, ma.MarshalLogs, func() (plog.Logs, error) {
-				return t.consumer.ConsumeLogs(&c.config, cpLogs)
-			})
-			if err != nil {
-				return nil, err
-			}
-			result.Line = c.line
-			results = append(results, result)
-			if i+1 == len(cfgs) {
-				input = result.Value
-			}
-		}
-	}
+func (t transformProcessorDebugger) StartMetrics(config, input string, breakpoints []Breakpoint, options ...ExecutionOption) (DebugSession, *Result, error) {
+	return startDebugSession(t.consumer, config, input, breakpoints, options, newMetricsTransformSignal(t.consumer))
+}
 
-	marshal, err := json.Marshal(results)
+func (t transformProcessorDebugger) StartProfiles(config, input string, breakpoints []Breakpoint, options ...ExecutionOption) (DebugSession, *Result, error) {
+	return startDebugSession(t.consumer, config, input, breakpoints, options, newProfilesTransformSignal(t.consumer))
+}
+
+// debugStatements runs the statements of the configuration one at a time, each
+// of them on the output of the previous one, and returns the result of every
+// step. Each statement runs on the records matched by the conditions of its
+// group, which are evaluated once, before the group's first statement.
+func debugStatements[T any](
+	consumer *processorConsumer[transformprocessor.Config],
+	config, input string,
+	options []ExecutionOption,
+	signal transformSignal[T],
+) (*Result, error) {
+	session, err := newTransformDebugSession(consumer, config, input, options, signal, nil)
 	if err != nil {
 		return nil, err
 	}
+	defer session.Close()
+	return session.run(true, false)
+}
 
-	res.Value = string(marshal)
-	return res, nil
+// startDebugSession starts a debug session of the statements of the
+// configuration, running its steps up to the first breakpoint.
+func startDebugSession[T any](
+	consumer *processorConsumer[transformprocessor.Config],
+	config, input string,
+	breakpoints []Breakpoint,
+	options []ExecutionOption,
+	signal transformSignal[T],
+) (DebugSession, *Result, error) {
+	for i, breakpoint := range breakpoints {
+		if err := breakpoint.validate(); err != nil {
+			return nil, nil, fmt.Errorf("invalid breakpoint %d: %w", i, err)
+		}
+	}
+	session, err := newTransformDebugSession(consumer, config, input, options, signal, breakpoints)
+	if err != nil {
+		return nil, nil, err
+	}
+	res, err := session.run(true, false)
+	if err != nil {
+		session.Close()
+		return nil, res, err
+	}
+	return session, res, nil
 }
 
-func (t transformProcessorDebugger) DebugTraces(config, input string) (*Result, error) {
-	configs, err := parseConfig[transformprocessor.Config](t.consumer.id, config, func() *transformprocessor.Config {
-		return t.consumer.factory.CreateDefaultConfig().(*transformprocessor.Config)
-	})
+// debugStep returns the configuration of cfg running the given group only, in
+// place of the group at the given index.
+func debugStep[T any](
+	cfg parsedConfig[transformprocessor.Config],
+	index int,
+	signal transformSignal[T],
+	group statementGroup,
+) (parsedConfig[transformprocessor.Config], error) {
+	cp, err := cfg.clone()
 	if err != nil {
-		return nil, err
+		return parsedConfig[transformprocessor.Config]{}, err
 	}
+	signal.setGroup(&cp, index, group)
+	return parsedConfig[transformprocessor.Config]{Key: cfg.Key, Value: &cp}, nil
+}
 
-	res := &Result{}
-	res.Debug = true
-	var results []*Result
-	for _, cfg := range configs {
-		cfgs := make([]configWithLine, 0, len(configs)*len(cfg.Value.TraceStatements))
-		for i, contextStatements := range cfg.Value.TraceStatements {
-			pathIndex, err := findYAMLPathIndex(config, cfg.Key, "trace_statements", i)
-			if err != nil {
-				return nil, fmt.Errorf("failed to find YAML path index: %w", err)
-			}
-			for i, sv := range contextStatements.Statements {
-				cp, err := cfg.clone()
-				if err != nil {
-					return nil, err
-				}
-				contextStatementsCp := contextStatements
-				contextStatementsCp.Statements = append(contextStatements.Statements[:i], []string{sv}...)
-				cp.TraceStatements = nil
-				cp.TraceStatements = append(cp.TraceStatements, contextStatementsCp)
-				cfgs = append(cfgs, configWithLine{cp, int64(pathIndex.Content[i].Line)})
-			}
-		}
+// cacheStashKey is the attribute the debugger stashes the cache of a context
+// in between steps. Each statement runs in its own step, which would otherwise
+// start with an empty cache.
+const cacheStashKey = "playground.debugger.cache"
 
-		um := ptrace.JSONUnmarshaler{}
-		inputTraces, err := um.UnmarshalTraces([]byte(input))
-		if err != nil {
-			return nil, err
-		}
+var cachePathPattern = regexp.MustCompile(`\bcache\b`)
 
-		ma := ptrace.JSONMarshaler{}
-		for i, c := range cfgs {
-			cpTraces := ptrace.NewTraces()
-			inputTraces.CopyTo(cpTraces)
+// cacheStash holds the statements moving the cache of a context to and from
+// the stash attribute.
+type cacheStash struct {
+	restore []string
+	save    string
+	remove  string
+}
 
-			result, err := newExecutionResult(t, ma.MarshalTraces, func() (ptrace.Traces, error) {
-				return t.consumer.ConsumeTraces(&c.config, cpTraces)
-			})
-			if err != nil {
-				return nil, err
-			}
-			result.Line = c.line
-			results = append(results, result)
-			if i+1 == len(cfgs) {
-				input = result.Value
-			}
-		}
+// newCacheStash returns the statements stashing the cache of the group's
+// context, and whether the group needs them, that is, whether it uses the
+// cache. The stash also lets each step report the cache of the records.
+func newCacheStash(group statementGroup) (cacheStash, bool) {
+	if group.context == "" ||
+		!slices.ContainsFunc(slices.Concat(group.conditions, group.statements), cachePathPattern.MatchString) {
+		return cacheStash{}, false
 	}
 
-	marshal, err := json.Marshal(results)
-	if err != nil {
-		return nil, err
+	attributes := contextAttributes(group.context)
+	stashed := fmt.Sprintf("%s[%q]", attributes, cacheStashKey)
+	remove := fmt.Sprintf("delete_key(%s, %q)", attributes, cacheStashKey)
+	return cacheStash{
+		restore: []string{
+			fmt.Sprintf(`merge_maps(%s.cache, %s, "upsert") where %s != nil`, group.context, stashed, stashed),
+			remove,
+		},
+		save:   fmt.Sprintf("set(%s, %s.cache)", stashed, group.context),
+		remove: remove,
+	}, true
+}
+
+// contextAttributes returns the path of the attributes of the given context's
+// records, which the debugger stores its own data in.
+func contextAttributes(context string) string {
+	if context == "metric" {
+		return "metric.metadata"
 	}
+	return context + ".attributes"
+}
 
-	res.Value = string(marshal)
-	return res, nil
+// conditionsMatchKey is the attribute the debugger marks the records matched
+// by the conditions of a group with, in between its statements.
+const conditionsMatchKey = "playground.debugger.conditions"
+
+// conditionsMark holds the statements marking the records matched by the
+// conditions of a group, and the condition gating the group's statements on
+// the mark. The processor evaluates the conditions once per record, before
+// the group's first statement, which may change what they match, so only the
+// first step evaluates them.
+type conditionsMark struct {
+	condition string
+	set       string
+	remove    string
 }
 
-func (t transformProcessorDebugger) DebugMetrics(config, input string) (*Result, error) {
-	configs, err := parseConfig[transformprocessor.Config](t.consumer.id, config, func() *transformprocessor.Config {
-		return t.consumer.factory.CreateDefaultConfig().(*transformprocessor.Config)
-	})
+// newConditionsMark returns the statements marking the records matched by the
+// group's conditions, and whether the group needs them, that is, whether it has
+// conditions and more than one statement.
+func newConditionsMark(group statementGroup) (conditionsMark, bool) {
+	if group.context == "" || len(group.conditions) == 0 || len(group.statements) < 2 {
+		return conditionsMark{}, false
+	}
+
+	marked := fmt.Sprintf("%s[%q]", contextAttributes(group.context), conditionsMatchKey)
+	return conditionsMark{
+		condition: marked + " == true",
+		set:       fmt.Sprintf("set(%s, true)", marked),
+		remove:    fmt.Sprintf("delete_key(%s, %q)", contextAttributes(group.context), conditionsMatchKey),
+	}, true
+}
+
+// stripDebuggerAttributes returns a copy of the payload without the attributes
+// the debugger stores its data in, such as the stashed cache, in the records of
+// the given context of the group at the given index of cfg.
+func stripDebuggerAttributes[T any](
+	exec *execution,
+	cfg parsedConfig[transformprocessor.Config],
+	index int,
+	signal transformSignal[T],
+	context string,
+	payload T,
+) (T, error) {
+	stepConfig, err := debugStep(cfg, index, signal, statementGroup{context: context, statements: debuggerAttributesRemovals(context)})
 	if err != nil {
-		return nil, err
+		return payload, err
 	}
+	// The copy is only used for the snapshot, and isn't a batch of the step.
+	defer exec.takeBatches()
+	return signal.consume(exec, stepConfig, signal.copyPayload(payload))
+}
 
-	res := &Result{}
-	res.Debug = true
-	var results []*Result
-	for _, cfg := range configs {
-		cfgs := make([]configWithLine, 0, len(configs)*len(cfg.Value.MetricStatements))
-		for i, contextStatements := range cfg.Value.MetricStatements {
-			pathIndex, err := findYAMLPathIndex(config, cfg.Key, "metric_statements", i)
-			if err != nil {
-				return nil, fmt.Errorf("failed to find YAML path index: %w", err)
-			}
-			for i, sv := range contextStatements.Statements {
-				cp, err := cfg.clone()
-				if err != nil {
-					return nil, err
-				}
-				contextStatementsCp := contextStatements
-				contextStatementsCp.Statements = append(contextStatements.Statements[:i], []string{sv}...)
-				cp.MetricStatements = nil
-				cp.MetricStatements = append(cp.MetricStatements, contextStatementsCp)
-				cfgs = append(cfgs, configWithLine{cp, int64(pathIndex.Content[i].Line)})
-			}
-		}
+// debuggerAttributesRemovals returns the statements removing the attributes
+// the debugger stores its data in from the records of the given context.
+func debuggerAttributesRemovals(context string) []string {
+	attributes := contextAttributes(context)
+	return []string{
+		fmt.Sprintf("delete_key(%s, %q)", attributes, cacheStashKey),
+		fmt.Sprintf("delete_key(%s, %q)", attributes, conditionsMatchKey),
+	}
+}
 
-		um := pmetric.JSONUnmarshaler{}
-		inputMetrics, err := um.UnmarshalMetrics([]byte(input))
-		if err != nil {
-			return nil, err
-		}
+// DebugStep tells which statement, or group condition, a debug step runs, as
+// lines alone can't tell apart the steps of different configurations.
+type DebugStep struct {
+	// ConfigKey is the key of the step's processor configuration, such as
+	// transform/a.
+	ConfigKey string `json:"configKey"`
+	// Group is the index of the step's statement group. Plain statements make
+	// up a group following the configured ones.
+	Group int `json:"group"`
+	// Index is the index of the statement within its group, or the index of the
+	// condition if Condition is set.
+	Index int `json:"index"`
+	// Condition tells whether the step runs a condition of the group rather
+	// than one of its statements.
+	Condition bool `json:"condition,omitempty"`
+	// Context is the OTTL context the step runs in.
+	Context string `json:"context"`
+	// InferredContext tells whether the context was inferred from the paths
+	// of the group's statements and conditions, as the processor does for
+	// groups without a configured context, such as plain statement lists.
+	InferredContext bool `json:"inferredContext,omitempty"`
+}
 
-		ma := pmetric.JSONMarshaler{}
-		for i, c := range cfgs {
-			cpMetrics := pmetric.NewMetrics()
-			inputMetrics.CopyTo(cpMetrics)
+// whereMatchKey is the attribute the debugger marks the records matched by a
+// where clause with.
+const whereMatchKey = "playground.debugger.where"
+
+// WhereReport tells which records the where clause of a statement, or a
+// condition of its group, matched, and which ones it skipped.
+type WhereReport struct {
+	// Context is the OTTL context of the records.
+	Context string `json:"context"`
+	// Condition is the statement's where clause.
+	Condition string `json:"condition"`
+	// Matched is the number of records the where clause matched.
+	Matched int `json:"matched"`
+	// MatchedRecords holds the positions of the matched records, from the
+	// resource down, such as [resource, scope, log record].
+	MatchedRecords [][]int `json:"matchedRecords"`
+	// SkippedRecords holds the positions of the records the statement doesn't
+	// apply to, as either the where clause or the group's conditions didn't
+	// match them.
+	SkippedRecords [][]int `json:"skippedRecords"`
+}
 
-			result, err := newExecutionResult(t, ma.MarshalMetrics, func() (pmetric.Metrics, error) {
-				return t.consumer.ConsumeMetrics(&c.config, cpMetrics)
-			})
-			if err != nil {
-				return nil, err
-			}
-			result.Line = c.line
-			results = append(results, result)
-			if i+1 == len(cfgs) {
-				input = result.Value
-			}
-		}
+// probeWhereClause reports the records of the payload matched by the where
+// clause of the statement, which runs in the given context, gated by the given
+// conditions. It returns nil if the statement has no where clause, if its
+// context can't be told, or if the clause fails, which its step reports.
+func probeWhereClause[T any](
+	exec *execution,
+	cfg parsedConfig[transformprocessor.Config],
+	index int,
+	signal transformSignal[T],
+	context string,
+	conditions []string,
+	restore []string,
+	statement string,
+	payload T,
+) *WhereReport {
+	condition, ok := whereClause(statement)
+	if !ok {
+		return nil
 	}
+	report, _ := probeCondition(exec, cfg, index, signal, context, conditions, restore, condition, payload)
+	return report
+}
 
-	marshal, err := json.Marshal(results)
-	if err != nil {
-		return nil, err
+// probeCondition reports the records of the payload matched by the condition,
+// evaluated in the given context, and gated by the given group conditions. The
+// condition runs on a copy of the payload, marking the records it matches,
+// once the attributes the debugger stores its data in are removed. It returns
+// nil if the context can't be told.
+func probeCondition[T any](
+	exec *execution,
+	cfg parsedConfig[transformprocessor.Config],
+	index int,
+	signal transformSignal[T],
+	context string,
+	conditions []string,
+	restore []string,
+	condition string,
+	payload T,
+) (*WhereReport, error) {
+	if context == "" {
+		return nil, nil
 	}
 
-	res.Value = string(marshal)
-	return res, nil
-}
+	// The probe is never reported, nor accounted in the step's timings.
+	defer exec.takeTimings()
+	defer exec.discardTelemetry()
 
-func (t transformProcessorDebugger) DebugProfiles(config, input string) (*Result, error) {
-	configs, err := parseConfig[transformprocessor.Config](t.consumer.id, config, func() *transformprocessor.Config {
-		return t.consumer.factory.CreateDefaultConfig().(*transformprocessor.Config)
+	marker := fmt.Sprintf("%s[%q]", contextAttributes(context), whereMatchKey)
+	stepConfig, err := debugStep(cfg, index, signal, statementGroup{
+		context:    context,
+		conditions: conditions,
+		statements: slices.Concat(restore, debuggerAttributesRemovals(context), []string{fmt.Sprintf("set(%s, true) where %s", marker, condition)}),
 	})
 	if err != nil {
 		return nil, err
 	}
+	probed, err := signal.consume(exec, stepConfig, signal.copyPayload(payload))
+	if err != nil {
+		return nil, err
+	}
 
-	res := &Result{}
-	res.Debug = true
-	var results []*Result
-	for _, cfg := range configs {
-		cfgs := make([]configWithLine, 0, len(configs)*len(cfg.Value.ProfileStatements))
-		for i, contextStatements := range cfg.Value.ProfileStatements {
-			pathIndex, err := findYAMLPathIndex(config, cfg.Key, "profile_statements", i)
-			if err != nil {
-				return nil, fmt.Errorf("failed to find YAML path index: %w", err)
-			}
-			for i, sv := range contextStatements.Statements {
-				cp, err := cfg.clone()
-				if err != nil {
-					return nil, err
-				}
-				contextStatementsCp := contextStatements
-				contextStatementsCp.Statements = append(contextStatements.Statements[:i], []string{sv}...)
-				cp.ProfileStatements = nil
-				cp.ProfileStatements = append(cp.ProfileStatements, contextStatementsCp)
-				cfgs = append(cfgs, configWithLine{cp, int64(pathIndex.Content[i].Line)})
-			}
-		}
-
-		um := pprofile.JSONUnmarshaler{}
-		inputProfiles, err := um.UnmarshalProfiles([]byte(input))
-		if err != nil {
-			return nil, err
-		}
-
-		ma := pprofile.JSONMarshaler{}
-		for i, c := range cfgs {
-			cpProfiles := pprofile.NewProfiles()
-			inputProfiles.CopyTo(cpProfiles)
+	records := signal.marked(probed, context, whereMatchKey)
+	return &WhereReport{
+		Context:        context,
+		Condition:      condition,
+		Matched:        len(records.marked),
+		MatchedRecords: records.marked,
+		SkippedRecords: records.unmarked,
+	}, nil
+}
 
-			result, err := newExecutionResult(t, ma.MarshalProfiles, func() (pprofile.Profiles, error) {
-				return t.consumer.ConsumeProfiles(&c.config, cpProfiles)
-			})
-			if err != nil {
-				return nil, err
-			}
-			result.Line = c.line
-			results = append(results, result)
-			if i+1 == len(cfgs) {
-				input = result.Value
+// whereClause returns the where clause of the statement, if any. The where
+// keyword is only looked for outside of strings and of the editor's arguments.
+func whereClause(statement string) (string, bool) {
+	const keyword = "where"
+	depth := 0
+	inString := false
+	for i := 0; i < len(statement); i++ {
+		c := statement[i]
+		switch {
+		case inString:
+			if c == '\\' {
+				i++
+			} else if c == '"' {
+				inString = false
 			}
+		case c == '"':
+			inString = true
+		case c == '(' || c == '[' || c == '{':
+			depth++
+		case c == ')' || c == ']' || c == '}':
+			depth--
+		case depth == 0 && strings.HasPrefix(statement[i:], keyword) &&
+			(i == 0 || !isIdentifierByte(statement[i-1])) &&
+			(i+len(keyword) == len(statement) || !isIdentifierByte(statement[i+len(keyword)])):
+			return strings.TrimSpace(statement[i+len(keyword):]), true
 		}
 	}
+	return "", false
+}
 
-	marshal, err := json.Marshal(results)
-	if err != nil {
-		return nil, err
-	}
-
-	res.Value = string(marshal)
-	return res, nil
+func isIdentifierByte(c byte) bool {
+	return c == '_' || c >= '0' && c <= '9' || c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z'
 }
 
-func (t transformProcessorDebugger) ObservedLogs() *ObservedLogs {
-	return t.consumer.ObservedLogs()
+// isDebuggerEntry reports whether the log entry shows the attributes the
+// debugger stores its data in, such as the ones logged by the statements
+// stashing the cache, which aren't part of the configuration.
+func isDebuggerEntry(entry LoggedEntry) bool {
+	return strings.Contains(entry.consoleEncodedEntry, cacheStashKey) ||
+		strings.Contains(entry.consoleEncodedEntry, conditionsMatchKey)
 }
 
 func NewTransformProcessorDebugger() Debugger {
-	consumer := newProcessorConsumer[transformprocessor.Config](transformprocessor.NewFactory())
+	consumer := newTransformProcessorConsumer()
 	return &transformProcessorDebugger{consumer}
 }
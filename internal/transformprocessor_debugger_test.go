@@ -20,10 +20,10 @@
 package internal
 
 import (
+	"context"
 	"encoding/json"
 	"testing"
 
-	"github.com/open-telemetry/opentelemetry-collector-contrib/processor/transformprocessor"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 	"gopkg.in/yaml.v3"
@@ -42,15 +42,6 @@ func Test_NewTransformProcessorDebugger(t *testing.T) {
 	require.True(t, ok)
 }
 
-func Test_transformProcessorDebugger_ObservedLogs(t *testing.T) {
-	debugger := NewTransformProcessorDebugger().(*transformProcessorDebugger)
-	observedLogs := debugger.ObservedLogs()
-	require.NotNil(t, observedLogs)
-
-	// Test that it's the same instance as the consumer's observed logs
-	assert.Equal(t, debugger.consumer.ObservedLogs(), observedLogs)
-}
-
 func Test_findYAMLPathIndex_SimpleConfig(t *testing.T) {
 	yamlData := `trace_statements:
   - context: resource
@@ -102,6 +93,31 @@ func Test_findYAMLPathIndex_WithFlatConfig(t *testing.T) {
 	assert.Len(t, node.Content, 2)
 }
 
+func Test_findYAMLPathIndex_WithMixedConfig(t *testing.T) {
+	yamlData := `log_statements:
+  - context: resource
+    statements:
+      - set(attributes["test"], true)
+  - set(log.attributes["test"], true)
+  - set(log.attributes["test2"], false)`
+
+	group, err := findYAMLPathIndex(yamlData, "", "log_statements", 0)
+	require.NoError(t, err)
+	assert.Equal(t, 4, group.Line)
+	assert.Len(t, group.Content, 1)
+
+	// The plain statements are the last group
+	flat, err := findYAMLPathIndex(yamlData, "", "log_statements", 1)
+	require.NoError(t, err)
+	assert.Equal(t, 5, flat.Line)
+	assert.Equal(t, yaml.SequenceNode, flat.Kind)
+	require.Len(t, flat.Content, 2)
+	assert.Equal(t, 6, flat.Content[1].Line)
+
+	_, err = findYAMLPathIndex(yamlData, "", "log_statements", 2)
+	assert.ErrorContains(t, err, "statement group 2 not found")
+}
+
 func Test_findYAMLPathIndex_WithMultipleConfigs(t *testing.T) {
 	yamlData := `transform/first:
   trace_statements:
@@ -180,6 +196,9 @@ func Test_transformProcessorDebugger_DebugLogs(t *testing.T) {
 	// Verify debug result structure
 	assert.True(t, result.Debug)
 	assert.NotEmpty(t, result.Value)
+	require.NotNil(t, result.Timings)
+	assert.Positive(t, result.Timings.Parse)
+	assert.Positive(t, result.Timings.Create)
 
 	// Parse the result JSON to verify it's valid
 	var debugResults []*Result
@@ -292,6 +311,33 @@ func Test_transformProcessorDebugger_DebugLogs_MultipleConfigs(t *testing.T) {
 	assert.NotEmpty(t, debugResults)
 }
 
+func Test_transformProcessorDebugger_DebugTraces_StepLocations(t *testing.T) {
+	debugger := NewTransformProcessorDebugger()
+	config := readTestData(t, configMultiple)
+	payload := readTestData(t, "traces.json")
+
+	result, err := debugger.DebugTraces(config, payload)
+	require.NoError(t, err)
+
+	var debugResults []*Result
+	require.NoError(t, json.Unmarshal([]byte(result.Value), &debugResults))
+	require.Len(t, debugResults, 4)
+
+	expected := []struct {
+		line int64
+		step DebugStep
+	}{
+		{line: 5, step: DebugStep{ConfigKey: "transform", Context: "resource"}},
+		{line: 6, step: DebugStep{ConfigKey: "transform", Index: 1, Context: "resource"}},
+		{line: 20, step: DebugStep{ConfigKey: "transform/b", Context: "resource"}},
+		{line: 34, step: DebugStep{ConfigKey: "transform/a", Context: "resource"}},
+	}
+	for i, e := range expected {
+		assert.Equal(t, e.line, debugResults[i].Line)
+		assert.Equal(t, &e.step, debugResults[i].Step)
+	}
+}
+
 func Test_transformProcessorDebugger_DebugLogs_InvalidConfig(t *testing.T) {
 	debugger := NewTransformProcessorDebugger().(*transformProcessorDebugger)
 	config := "invalid: yaml: [unclosed"
@@ -368,19 +414,6 @@ func Test_transformProcessorDebugger_DebugWithComplexConfig(t *testing.T) {
 	}
 }
 
-func Test_configWithLine_Structure(t *testing.T) {
-	config := &transformprocessor.Config{}
-	line := int64(42)
-
-	cwl := configWithLine{
-		config: *config,
-		line:   line,
-	}
-
-	assert.NotNil(t, cwl.config)
-	assert.Equal(t, line, cwl.line)
-}
-
 func Test_transformProcessorDebugger_DebugLogs_LineNumberAccuracy(t *testing.T) {
 	debugger := NewTransformProcessorDebugger().(*transformProcessorDebugger)
 
@@ -406,3 +439,440 @@ func Test_transformProcessorDebugger_DebugLogs_LineNumberAccuracy(t *testing.T)
 	assert.Equal(t, int64(4), debugResults[0].Line)
 	assert.Equal(t, int64(5), debugResults[1].Line)
 }
+
+func Test_transformProcessorDebugger_DebugLogs_Cancelled(t *testing.T) {
+	debugger := NewTransformProcessorDebugger().(*transformProcessorDebugger)
+	config := readTestData(t, transformprocessorConfig)
+	payload := readTestData(t, "logs.json")
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	result, err := debugger.DebugLogs(config, payload, WithContext(ctx))
+	require.NoError(t, err)
+	assert.True(t, result.Cancelled)
+	assert.Equal(t, context.Canceled.Error(), result.CancellationReason)
+
+	// The debugger stops at the first cancelled step
+	var debugResults []*Result
+	require.NoError(t, json.Unmarshal([]byte(result.Value), &debugResults))
+	require.Len(t, debugResults, 1)
+	assert.True(t, debugResults[0].Cancelled)
+}
+
+func Test_transformProcessorDebugger_DebugLogs_Incremental(t *testing.T) {
+	debugger := NewTransformProcessorDebugger()
+	config := `log_statements:
+  - context: resource
+    statements:
+      - set(resource.attributes["group"], "first")
+  - context: log
+    statements:
+      - set(log.attributes["group"], resource.attributes["group"])
+      - set(log.attributes["count"], 1)
+      - set(log.attributes["count"], 2) where log.attributes["count"] == 1`
+	payload := `{"resourceLogs":[{"scopeLogs":[{"logRecords":[{"body":{"stringValue":"log"}}]}]}]}`
+
+	result, err := debugger.DebugLogs(config, payload)
+	require.NoError(t, err)
+
+	var debugResults []*Result
+	require.NoError(t, json.Unmarshal([]byte(result.Value), &debugResults))
+	require.Len(t, debugResults, 4)
+	// Each step runs on the output of the previous one, across groups
+	assert.Contains(t, debugResults[1].Value, `{"key":"group","value":{"stringValue":"first"}}`)
+	assert.NotContains(t, debugResults[1].Value, `"count"`)
+	assert.Contains(t, debugResults[2].Value, `{"key":"count","value":{"intValue":"1"}}`)
+	assert.Contains(t, debugResults[3].Value, `{"key":"count","value":{"intValue":"2"}}`)
+}
+
+func Test_transformProcessorDebugger_DebugLogs_Cache(t *testing.T) {
+	debugger := NewTransformProcessorDebugger()
+	config := `log_statements:
+  - set(log.cache["value"], log.body)
+  - set(log.attributes["from_cache"], log.cache["value"])
+  - set(log.attributes["still_cached"], log.cache["value"])`
+	payload := `{"resourceLogs":[{"scopeLogs":[{"logRecords":[{"body":{"stringValue":"cached"}}]}]}]}`
+
+	result, err := debugger.DebugLogs(config, payload)
+	require.NoError(t, err)
+
+	var debugResults []*Result
+	require.NoError(t, json.Unmarshal([]byte(result.Value), &debugResults))
+	require.Len(t, debugResults, 3)
+	assert.Contains(t, debugResults[1].Value, `{"key":"from_cache","value":{"stringValue":"cached"}}`)
+	assert.Contains(t, debugResults[2].Value, `{"key":"still_cached","value":{"stringValue":"cached"}}`)
+	for _, debugResult := range debugResults {
+		assert.NotContains(t, debugResult.Value, cacheStashKey)
+		assert.NotContains(t, debugResult.Logs, cacheStashKey)
+		assert.Empty(t, debugResult.Batches)
+	}
+}
+
+func Test_transformProcessorDebugger_DebugLogs_Caches(t *testing.T) {
+	debugger := NewTransformProcessorDebugger()
+	config := `log_statements:
+  - context: log
+    conditions:
+      - body != "skipped"
+    statements:
+      - merge_maps(cache, ParseJSON(body), "upsert")
+      - set(cache["level"], "info") where cache["level"] == nil
+      - set(attributes["level"], cache["level"])
+  - context: log
+    statements:
+      - set(attributes["other"], true)`
+	payload := `{"resourceLogs":[{"scopeLogs":[{"logRecords":[` +
+		`{"body":{"stringValue":"{\"level\":\"warn\"}"}},{"body":{"stringValue":"{}"}},{"body":{"stringValue":"skipped"}}]}]}]}`
+
+	result, err := debugger.DebugLogs(config, payload)
+	require.NoError(t, err)
+
+	var debugResults []*Result
+	require.NoError(t, json.Unmarshal([]byte(result.Value), &debugResults))
+	require.Len(t, debugResults, 5)
+
+	// The records skipped by the group's conditions have no cache
+	assert.Equal(t, []RecordCache{
+		{Index: []int{0, 0, 0}, Cache: map[string]any{"level": "warn"}},
+		{Index: []int{0, 0, 1}, Cache: map[string]any{}},
+	}, debugResults[1].Caches)
+	assert.Equal(t, []RecordCache{
+		{Index: []int{0, 0, 0}, Cache: map[string]any{"level": "warn"}},
+		{Index: []int{0, 0, 1}, Cache: map[string]any{"level": "info"}},
+	}, debugResults[2].Caches)
+	assert.Equal(t, debugResults[2].Caches, debugResults[3].Caches)
+	assert.Contains(t, debugResults[3].Value, `{"key":"level","value":{"stringValue":"info"}}`)
+
+	// The groups not using the cache don't report it
+	assert.Empty(t, debugResults[4].Caches)
+	for _, debugResult := range debugResults {
+		assert.NotContains(t, debugResult.Value, cacheStashKey)
+	}
+}
+
+func Test_transformProcessorDebugger_DebugMetrics_Cache(t *testing.T) {
+	debugger := NewTransformProcessorDebugger()
+	config := `metric_statements:
+  - context: metric
+    statements:
+      - set(metric.cache["name"], metric.name)
+      - set(metric.description, metric.cache["name"])
+  - context: resource
+    statements:
+      - set(resource.cache["a"], "b")
+      - set(resource.attributes["a"], resource.cache["a"])`
+	payload := `{"resourceMetrics":[{"scopeMetrics":[{"metrics":[{"name":"m","gauge":{"dataPoints":[{"asInt":"1"}]}}]}]}]}`
+
+	result, err := debugger.DebugMetrics(config, payload)
+	require.NoError(t, err)
+
+	var debugResults []*Result
+	require.NoError(t, json.Unmarshal([]byte(result.Value), &debugResults))
+	require.Len(t, debugResults, 4)
+	assert.Contains(t, debugResults[1].Value, `"description":"m"`)
+	assert.Contains(t, debugResults[3].Value, `{"key":"a","value":{"stringValue":"b"}}`)
+	for _, debugResult := range debugResults {
+		assert.NotContains(t, debugResult.Value, cacheStashKey)
+	}
+}
+
+func Test_debugStep_DoesNotShareStatements(t *testing.T) {
+	consumer := newTransformProcessorConsumer()
+	cfgs, err := parseConfig(consumer.id, "log_statements:\n  - set(log.attributes[\"a\"], 1)\n  - set(log.attributes[\"b\"], 2)", consumer.CreateDefaultConfig)
+	require.NoError(t, err)
+	signal := newLogsTransformSignal(consumer)
+
+	step, err := debugStep(cfgs[0], 0, signal, statementGroup{statements: []string{"set(log.attributes[\"b\"], 3)"}})
+	require.NoError(t, err)
+	step.Value.LogStatements[0].Statements[0] = "changed"
+
+	assert.Equal(t, []string{`set(log.attributes["a"], 1)`, `set(log.attributes["b"], 2)`}, cfgs[0].Value.LogStatements[0].Statements)
+}
+
+func Test_newCacheStash(t *testing.T) {
+	_, ok := newCacheStash(statementGroup{context: "log", statements: []string{`set(log.attributes["a"], 1)`, `set(log.attributes["b"], 2)`}})
+	assert.False(t, ok, "the cache isn't used")
+	_, ok = newCacheStash(statementGroup{context: "log", statements: []string{`set(log.cache["a"], 1)`}})
+	assert.True(t, ok, "a single statement reports its cache")
+	_, ok = newCacheStash(statementGroup{statements: []string{`set(cache["a"], 1)`, `set(attributes["a"], cache["a"])`}})
+	assert.False(t, ok, "the context is unknown")
+
+	stash, ok := newCacheStash(statementGroup{context: "metric", statements: []string{`set(metric.cache["a"], 1)`, `set(metric.description, "a")`}})
+	require.True(t, ok)
+	assert.Equal(t, `set(metric.metadata["playground.debugger.cache"], metric.cache)`, stash.save)
+	assert.Equal(t, `delete_key(metric.metadata, "playground.debugger.cache")`, stash.remove)
+}
+
+func Test_transformProcessorDebugger_DebugLogs_Where(t *testing.T) {
+	debugger := NewTransformProcessorDebugger()
+	config := `log_statements:
+  - context: log
+    statements:
+      - set(attributes["level"], "debug") where severity_number < 9
+      - set(attributes["never"], true) where body == "unknown"
+      - set(attributes["all"], true)
+  - context: resource
+    statements:
+      - set(attributes["env"], "prod") where attributes["service.name"] == "b"`
+	payload := `{"resourceLogs":[` +
+		`{"resource":{"attributes":[{"key":"service.name","value":{"stringValue":"a"}}]},"scopeLogs":[{"logRecords":[{"severityNumber":5},{"severityNumber":9}]}]},` +
+		`{"resource":{"attributes":[{"key":"service.name","value":{"stringValue":"b"}}]},"scopeLogs":[{"logRecords":[{"severityNumber":1}]}]}]}`
+
+	result, err := debugger.DebugLogs(config, payload)
+	require.NoError(t, err)
+
+	var debugResults []*Result
+	require.NoError(t, json.Unmarshal([]byte(result.Value), &debugResults))
+	require.Len(t, debugResults, 4)
+
+	assert.Equal(t, &WhereReport{
+		Context:        "log",
+		Condition:      "severity_number < 9",
+		Matched:        2,
+		MatchedRecords: [][]int{{0, 0, 0}, {1, 0, 0}},
+		SkippedRecords: [][]int{{0, 0, 1}},
+	}, debugResults[0].Where)
+	assert.Equal(t, &WhereReport{
+		Context:        "log",
+		Condition:      `body == "unknown"`,
+		Matched:        0,
+		SkippedRecords: [][]int{{0, 0, 0}, {0, 0, 1}, {1, 0, 0}},
+	}, debugResults[1].Where)
+	assert.Nil(t, debugResults[2].Where)
+	assert.Equal(t, &WhereReport{
+		Context:        "resource",
+		Condition:      `attributes["service.name"] == "b"`,
+		Matched:        1,
+		MatchedRecords: [][]int{{1}},
+		SkippedRecords: [][]int{{0}},
+	}, debugResults[3].Where)
+
+	// The probe leaves no trace in the steps
+	for _, debugResult := range debugResults {
+		assert.NotContains(t, debugResult.Value, whereMatchKey)
+		assert.NotContains(t, debugResult.Logs, whereMatchKey)
+	}
+}
+
+func Test_transformProcessorDebugger_DebugLogs_WhereConditions(t *testing.T) {
+	debugger := NewTransformProcessorDebugger()
+	config := `log_statements:
+  - context: log
+    conditions:
+      - severity_number == 1
+    statements:
+      - set(attributes["a"], true) where body != nil`
+	payload := `{"resourceLogs":[{"scopeLogs":[{"logRecords":[` +
+		`{"severityNumber":1,"body":{"stringValue":"a"}},{"severityNumber":2,"body":{"stringValue":"b"}}]}]}]}`
+
+	result, err := debugger.DebugLogs(config, payload)
+	require.NoError(t, err)
+
+	var debugResults []*Result
+	require.NoError(t, json.Unmarshal([]byte(result.Value), &debugResults))
+	require.Len(t, debugResults, 2)
+	// The records the group's conditions don't match are skipped as well
+	require.NotNil(t, debugResults[1].Where)
+	assert.Equal(t, [][]int{{0, 0, 0}}, debugResults[1].Where.MatchedRecords)
+	assert.Equal(t, [][]int{{0, 0, 1}}, debugResults[1].Where.SkippedRecords)
+}
+
+func Test_transformProcessorDebugger_DebugLogs_GroupConditions(t *testing.T) {
+	debugger := NewTransformProcessorDebugger()
+	config := `log_statements:
+  - context: log
+    conditions:
+      - severity_number == 1
+      - body == "c"
+    statements:
+      - set(attributes["a"], true)`
+	payload := `{"resourceLogs":[{"scopeLogs":[{"logRecords":[` +
+		`{"severityNumber":1,"body":{"stringValue":"a"}},{"severityNumber":2,"body":{"stringValue":"b"}},{"severityNumber":3,"body":{"stringValue":"c"}}]}]}]}`
+
+	result, err := debugger.DebugLogs(config, payload)
+	require.NoError(t, err)
+
+	var debugResults []*Result
+	require.NoError(t, json.Unmarshal([]byte(result.Value), &debugResults))
+	require.Len(t, debugResults, 3)
+
+	// Each condition is a step of its own, which doesn't change the payload
+	assert.Equal(t, int64(4), debugResults[0].Line)
+	assert.Equal(t, &WhereReport{
+		Context:        "log",
+		Condition:      "severity_number == 1",
+		Matched:        1,
+		MatchedRecords: [][]int{{0, 0, 0}},
+		SkippedRecords: [][]int{{0, 0, 1}, {0, 0, 2}},
+	}, debugResults[0].Where)
+	assert.Empty(t, debugResults[0].Changes)
+	assert.NotContains(t, debugResults[0].Value, `"attributes"`)
+	assert.Empty(t, debugResults[0].Logs)
+
+	assert.Equal(t, int64(5), debugResults[1].Line)
+	assert.Equal(t, &DebugStep{Index: 1, Condition: true, Context: "log"}, debugResults[1].Step)
+	assert.Equal(t, [][]int{{0, 0, 2}}, debugResults[1].Where.MatchedRecords)
+
+	// The statements only apply to the records matched by any condition
+	assert.Equal(t, int64(7), debugResults[2].Line)
+	assert.Len(t, debugResults[2].Changes, 2)
+	assert.NotContains(t, debugResults[2].Value, whereMatchKey)
+}
+
+func Test_transformProcessorDebugger_DebugLogs_GroupConditionsEvaluatedOnce(t *testing.T) {
+	debugger := NewTransformProcessorDebugger()
+	config := `log_statements:
+  - context: log
+    conditions:
+      - log.attributes["x"] == nil
+    statements:
+      - set(log.attributes["x"], 1)
+      - set(log.attributes["y"], 2) where log.attributes["x"] == 1`
+	payload := `{"resourceLogs":[{"scopeLogs":[{"logRecords":[` +
+		`{"body":{"stringValue":"a"}},{"body":{"stringValue":"b"},"attributes":[{"key":"x","value":{"intValue":"1"}}]}]}]}]}`
+
+	result, err := debugger.DebugLogs(config, payload)
+	require.NoError(t, err)
+
+	var debugResults []*Result
+	require.NoError(t, json.Unmarshal([]byte(result.Value), &debugResults))
+	require.Len(t, debugResults, 3)
+
+	// The conditions no longer match the first record once the first
+	// statement ran, but the processor evaluates them before it
+	assert.Equal(t, [][]int{{0, 0, 0}}, debugResults[2].Where.MatchedRecords)
+	assert.Equal(t, [][]int{{0, 0, 1}}, debugResults[2].Where.SkippedRecords)
+	for _, debugResult := range debugResults {
+		assert.NotContains(t, debugResult.Value, conditionsMatchKey)
+		assert.NotContains(t, debugResult.Logs, conditionsMatchKey)
+	}
+
+	executed, err := NewTransformProcessorExecutor().ExecuteLogs(config, payload)
+	require.NoError(t, err)
+	assert.JSONEq(t, executed.Value, debugResults[2].Value)
+}
+
+func Test_transformProcessorDebugger_DebugLogs_GroupConditionError(t *testing.T) {
+	debugger := NewTransformProcessorDebugger()
+	config := `error_mode: propagate
+log_statements:
+  - context: log
+    conditions:
+      - ParseJSON(body)["a"] == 1
+    statements:
+      - set(attributes["a"], true)`
+	payload := `{"resourceLogs":[{"scopeLogs":[{"logRecords":[{"body":{"stringValue":"not json"}}]}]}]}`
+
+	result, err := debugger.DebugLogs(config, payload)
+	require.Error(t, err)
+	require.NotNil(t, result)
+}
+
+func Test_whereClause(t *testing.T) {
+	tests := []struct {
+		statement string
+		condition string
+		ok        bool
+	}{
+		{`set(attributes["a"], 1) where name == "b"`, `name == "b"`, true},
+		{`set(attributes["a"], "x where y")`, "", false},
+		{`set(attributes["where"], 1)`, "", false},
+		{`set(attributes["a"], "\" where") where IsMatch(body, "where")`, `IsMatch(body, "where")`, true},
+		{`set(attributes["a"], nowhere)`, "", false},
+		{`delete_key(attributes, "a")`, "", false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.statement, func(t *testing.T) {
+			condition, ok := whereClause(tt.statement)
+			assert.Equal(t, tt.ok, ok)
+			assert.Equal(t, tt.condition, condition)
+		})
+	}
+}
+
+func Test_transformProcessorDebugger_DebugLogs_Changes(t *testing.T) {
+	debugger := NewTransformProcessorDebugger()
+	config := `log_statements:
+  - context: log
+    statements:
+      - set(attributes["a"], "b")
+      - set(attributes["a"], "c") where attributes["a"] == "b"
+      - delete_key(attributes, "a")
+      - set(attributes["unchanged"], nil) where false`
+	payload := `{"resourceLogs":[{"scopeLogs":[{"logRecords":[{"body":{"stringValue":"log"}}]}]}]}`
+
+	result, err := debugger.DebugLogs(config, payload)
+	require.NoError(t, err)
+
+	var debugResults []*Result
+	require.NoError(t, json.Unmarshal([]byte(result.Value), &debugResults))
+	require.Len(t, debugResults, 4)
+	// Each step's changes are relative to the previous step's output
+	assert.Equal(t, []FieldChange{{Index: []int{0, 0, 0}, Path: `attributes["a"]`, Operation: "add", NewValue: "b"}}, debugResults[0].Changes)
+	assert.Equal(t, []FieldChange{{Index: []int{0, 0, 0}, Path: `attributes["a"]`, Operation: "set", OldValue: "b", NewValue: "c"}}, debugResults[1].Changes)
+	assert.Equal(t, []FieldChange{{Index: []int{0, 0, 0}, Path: `attributes["a"]`, Operation: "delete", OldValue: "c"}}, debugResults[2].Changes)
+	assert.Empty(t, debugResults[3].Changes)
+}
+
+func Test_transformProcessorDebugger_DebugLogs_FlatStatements(t *testing.T) {
+	debugger := NewTransformProcessorDebugger()
+	config := `log_statements:
+  - set(log.attributes["a"], resource.attributes["service.name"])
+  - set(resource.attributes["b"], true)`
+	payload := `{"resourceLogs":[{"resource":{"attributes":[{"key":"service.name","value":{"stringValue":"svc"}}]},"scopeLogs":[{"logRecords":[{"body":{"stringValue":"a"}}]}]}]}`
+
+	result, err := debugger.DebugLogs(config, payload)
+	require.NoError(t, err)
+
+	var debugResults []*Result
+	require.NoError(t, json.Unmarshal([]byte(result.Value), &debugResults))
+	require.Len(t, debugResults, 2)
+
+	// The group's context is inferred from all of its statements
+	for i, line := range []int64{2, 3} {
+		assert.Equal(t, line, debugResults[i].Line)
+		assert.Equal(t, &DebugStep{Index: i, Context: "log", InferredContext: true}, debugResults[i].Step)
+		assert.Empty(t, debugResults[i].Error)
+	}
+	assert.Contains(t, debugResults[0].Value, `"svc"`)
+	assert.Contains(t, debugResults[1].Value, `"b"`)
+}
+
+func Test_transformProcessorDebugger_DebugLogs_MixedStatements(t *testing.T) {
+	debugger := NewTransformProcessorDebugger()
+	config := `log_statements:
+  - context: resource
+    statements:
+      - set(attributes["a"], true)
+  - context: scope
+    statements:
+      - set(attributes["b"], true)
+  - set(log.attributes["c"], true)
+  - set(resource.attributes["d"], true)`
+	payload := `{"resourceLogs":[{"scopeLogs":[{"logRecords":[{"body":{"stringValue":"a"}}]}]}]}`
+
+	result, err := debugger.DebugLogs(config, payload)
+	require.NoError(t, err)
+
+	var debugResults []*Result
+	require.NoError(t, json.Unmarshal([]byte(result.Value), &debugResults))
+	require.Len(t, debugResults, 4)
+
+	// The plain statements make up a group of their own
+	expected := []struct {
+		line int64
+		step DebugStep
+	}{
+		{line: 4, step: DebugStep{Context: "resource"}},
+		{line: 7, step: DebugStep{Group: 1, Context: "scope"}},
+		{line: 8, step: DebugStep{Group: 2, Context: "log", InferredContext: true}},
+		{line: 9, step: DebugStep{Group: 2, Index: 1, Context: "log", InferredContext: true}},
+	}
+	for i, e := range expected {
+		assert.Equal(t, e.line, debugResults[i].Line)
+		assert.Equal(t, &e.step, debugResults[i].Step)
+		assert.Empty(t, debugResults[i].Error)
+	}
+	assert.Contains(t, debugResults[3].Value, `"d"`)
+}
@@ -20,9 +20,18 @@
 package internal
 
 import (
+	"github.com/open-telemetry/opentelemetry-collector-contrib/pkg/ottl/contexts/ottllog"
+	"github.com/open-telemetry/opentelemetry-collector-contrib/pkg/ottl/contexts/ottlprofile"
+	"github.com/open-telemetry/opentelemetry-collector-contrib/pkg/ottl/contexts/ottlspanevent"
+	"github.com/open-telemetry/opentelemetry-collector-contrib/pkg/ottl/ottlfuncs"
 	"github.com/open-telemetry/opentelemetry-collector-contrib/processor/transformprocessor"
 )
 
+// transformProcessorFixedTimeContexts are the OTTL contexts in which the
+// transformprocessor only registers the standard functions, which can therefore
+// be replaced to pin Now(). The others also hold the processor's own functions.
+var transformProcessorFixedTimeContexts = []string{"log", "spanevent", "profile"}
+
 var transformProcessorConfigExamples = []ConfigExample{
 	{
 		Name:   "Rename an attribute",
@@ -124,12 +133,25 @@ var transformProcessorConfigExamples = []ConfigExample{
 	},
 }
 
+// newTransformProcessorConsumer creates the Consumer running the [transformprocessor].
+func newTransformProcessorConsumer() *processorConsumer[transformprocessor.Config] {
+	return newProcessorConsumer[transformprocessor.Config](
+		transformprocessor.NewFactory(),
+		withFixedTimeFactory[transformprocessor.Config](transformprocessor.NewFactoryWithOptions(
+			transformprocessor.WithLogFunctionsNew(withFixedTimeNow(ottlfuncs.StandardFuncs[*ottllog.TransformContext]())),
+			transformprocessor.WithSpanEventFunctionsNew(withFixedTimeNow(ottlfuncs.StandardFuncs[*ottlspanevent.TransformContext]())),
+			transformprocessor.WithProfileFunctions(withFixedTimeNow(ottlfuncs.StandardFuncs[ottlprofile.TransformContext]())),
+		)),
+		withReusableProcessors[transformprocessor.Config](),
+	)
+}
+
 // NewTransformProcessorExecutor creates an internal.Executor that runs OTTL statements using
 // the [transformprocessor].
 func NewTransformProcessorExecutor() Executor {
 	debugger := NewTransformProcessorDebugger()
 	return NewJSONExecutor[transformprocessor.Config](
-		newProcessorConsumer[transformprocessor.Config](transformprocessor.NewFactory()),
+		newTransformProcessorConsumer(),
 		newMetadata(
 			ComponentTypeProcessor,
 			"transform_processor",
@@ -137,7 +159,10 @@ func NewTransformProcessorExecutor() Executor {
 			"https://github.com/open-telemetry/opentelemetry-collector-contrib/blob/main/processor/transformprocessor",
 			"https://github.com/open-telemetry/opentelemetry-collector-contrib/blob/main/processor/transformprocessor",
 			withConfigExamples(transformProcessorConfigExamples...),
+			withClientInfo(),
+			withFixedTimeContexts(transformProcessorFixedTimeContexts...),
 		),
 		withDebugger[transformprocessor.Config](debugger),
+		withProfiler[transformprocessor.Config](NewTransformProcessorProfiler()),
 	)
 }
@@ -169,15 +169,3 @@ func Test_TransformProcessorExecutor_ExecuteProfiles(t *testing.T) {
 
 	require.Positive(t, count)
 }
-
-func Test_TransformProcessorExecutor_ObservedLogs(t *testing.T) {
-	executor := NewJSONExecutor[transformprocessor.Config](
-		newProcessorConsumer[transformprocessor.Config](transformprocessor.NewFactory()),
-		&Metadata{},
-	).(*defaultExecutor[transformprocessor.Config])
-
-	executor.consumer.TelemetrySettings().Logger.Sugar().Debug("this is a log")
-	logEntries := executor.ObservedLogs().TakeAll()
-	assert.Len(t, logEntries, 1)
-	assert.Contains(t, logEntries[0].ConsoleEncodedEntry(), "this is a log")
-}
@@ -0,0 +1,366 @@
+/*
+ * Copyright Elasticsearch B.V. and/or licensed to Elasticsearch B.V. under one
+ * or more contributor license agreements. See the NOTICE file distributed with
+ * this work for additional information regarding copyright
+ * ownership. Elasticsearch B.V. licenses this file to you under
+ * the Apache License, Version 2.0 (the "License"); you may
+ * not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *	http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing,
+ * software distributed under the License is distributed on an
+ * "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+ * KIND, either express or implied.  See the License for the
+ * specific language governing permissions and limitations
+ * under the License.
+ */
+
+package internal
+
+import (
+	"fmt"
+	"slices"
+	"time"
+
+	"github.com/open-telemetry/opentelemetry-collector-contrib/processor/transformprocessor"
+	"go.uber.org/zap/zapcore"
+)
+
+// defaultProfileRuns is the number of times each statement and condition is
+// run when the execution has no benchmark limits, see WithBenchmark.
+const defaultProfileRuns = 100
+
+// StatementCost is the cost of a statement, or of a condition, measured by a
+// Profiler.
+type StatementCost struct {
+	StatementLocation
+	Statement string `json:"statement"`
+	// Condition reports whether Statement is a condition of the context
+	// rather than a statement.
+	Condition bool `json:"condition,omitempty"`
+	Runs      int  `json:"runs"`
+	// Time is the average time, in microseconds, spent on the statement by a
+	// run over the whole payload.
+	Time float64 `json:"time"`
+	// AllocsPerRun and BytesPerRun are the average number of heap allocations,
+	// and of bytes allocated, by the statement in a run.
+	AllocsPerRun int64 `json:"allocsPerRun"`
+	BytesPerRun  int64 `json:"bytesPerRun"`
+	// Share is the percentage of the time spent on all the profiled
+	// statements and conditions that was spent on this one.
+	Share float64 `json:"share"`
+}
+
+// runCost is the average cost of a run measured by measureRuns.
+type runCost struct {
+	runs   int
+	time   time.Duration
+	allocs int64
+	bytes  int64
+}
+
+func newRunCost(durations []time.Duration, allocs, bytes uint64) runCost {
+	if len(durations) == 0 {
+		return runCost{}
+	}
+	var total time.Duration
+	for _, d := range durations {
+		total += d
+	}
+	runs := len(durations)
+	return runCost{
+		runs:   runs,
+		time:   total / time.Duration(runs),
+		allocs: int64(allocs) / int64(runs),
+		bytes:  int64(bytes) / int64(runs),
+	}
+}
+
+// minus returns the cost of c that isn't accounted for by other. Measurements
+// are noisy, so it's never negative.
+func (c runCost) minus(other runCost) runCost {
+	return runCost{
+		runs:   c.runs,
+		time:   max(c.time-other.time, 0),
+		allocs: max(c.allocs-other.allocs, 0),
+		bytes:  max(c.bytes-other.bytes, 0),
+	}
+}
+
+type transformProcessorProfiler struct {
+	consumer *processorConsumer[transformprocessor.Config]
+}
+
+func (t transformProcessorProfiler) ProfileLogs(config, input string, options ...ExecutionOption) (*Result, error) {
+	return profileStatements(t.consumer, config, input, options, newLogsTransformSignal(t.consumer))
+}
+
+func (t transformProcessorProfiler) ProfileTraces(config, input string, options ...ExecutionOption) (*Result, error) {
+	return profileStatements(t.consumer, config, input, options, newTracesTransformSignal(t.consumer))
+}
+
+func (t transformProcessorProfiler) ProfileMetrics(config, input string, options ...ExecutionOption) (*Result, error) {
+	return profileStatements(t.consumer, config, input, options, newMetricsTransformSignal(t.consumer))
+}
+
+func (t transformProcessorProfiler) ProfileProfiles(config, input string, options ...ExecutionOption) (*Result, error) {
+	return profileStatements(t.consumer, config, input, options, newProfilesTransformSignal(t.consumer))
+}
+
+// profileStatements measures the cost of each statement and condition of the
+// configuration, running each of them on its own against the payload they get
+// in the configuration. The payload overhead, such as iterating over the items
+// of the context, is measured separately and subtracted. Debug logs would
+// dominate the costs, so the logs are at the info level unless set otherwise.
+func profileStatements[T any](
+	consumer *processorConsumer[transformprocessor.Config],
+	config, input string,
+	options []ExecutionOption,
+	signal transformSignal[T],
+) (*Result, error) {
+	restoreFeatureGates, err := applyFeatureGates(options...)
+	if err != nil {
+		return nil, err
+	}
+	defer restoreFeatureGates()
+
+	payload, err := signal.unmarshal([]byte(input))
+	if err != nil {
+		return nil, err
+	}
+
+	exec := newExecution(append([]ExecutionOption{WithLogLevel(zapcore.InfoLevel)}, options...)...)
+	defer exec.close()
+
+	configs, err := parseExecutionConfig(exec, consumer.id, config, consumer.CreateDefaultConfig)
+	if err != nil {
+		return nil, err
+	}
+
+	locator, err := newStatementLocator(config, configs)
+	if err != nil {
+		return nil, err
+	}
+
+	if err = exec.startExtensions(config); err != nil {
+		return nil, err
+	}
+
+	profiler := &statementProfiler[T]{
+		exec:    exec,
+		signal:  signal,
+		config:  config,
+		locator: locator,
+		limits:  exec.settings.benchmark,
+	}
+	if !exec.benchmarking() {
+		profiler.limits = benchmarkSettings{runs: defaultProfileRuns}
+	}
+
+	res := &Result{}
+	res.Timings = exec.takeTimings()
+	start := time.Now()
+profile:
+	for _, cfg := range configs {
+		for g, group := range signal.groups(cfg.Value) {
+			payload, err = profiler.profileGroup(cfg, g, group, payload)
+			if exec.cancellationCause() != nil {
+				break profile
+			}
+			if err != nil {
+				return nil, err
+			}
+		}
+	}
+	res.ExecutionTime = time.Since(start).Milliseconds()
+
+	if cause := exec.cancellationCause(); cause != nil {
+		res.Cancelled = true
+		res.CancellationReason = cause.Error()
+	}
+	res.Profile = profiler.costs
+	rankStatementCosts(res.Profile)
+
+	value, err := signal.marshal(payload)
+	if err != nil {
+		return nil, err
+	}
+	res.Value = string(value)
+	res.takeLogs(exec)
+	return res, nil
+}
+
+// statementProfiler holds the state of a profileStatements call.
+type statementProfiler[T any] struct {
+	exec    *execution
+	signal  transformSignal[T]
+	config  string
+	locator *statementLocator
+	limits  benchmarkSettings
+	costs   []StatementCost
+}
+
+// profileGroup measures the cost of the conditions and statements of the group
+// at the given index of cfg, and returns the payload modified by the group.
+func (p *statementProfiler[T]) profileGroup(
+	cfg parsedConfig[transformprocessor.Config],
+	index int,
+	group statementGroup,
+	payload T,
+) (T, error) {
+	pathIndex, err := findYAMLPathIndex(p.config, cfg.Key, p.signal.configKey, index)
+	if err != nil {
+		return payload, fmt.Errorf("failed to find YAML path index: %w", err)
+	}
+	if group.context == "" {
+		group.context = groupContext(group)
+	}
+
+	// Without a context, an empty group can't be parsed, so the overhead is
+	// left in the costs.
+	var overhead runCost
+	if group.context != "" {
+		overhead, err = p.measure(cfg, index, statementGroup{context: group.context}, payload)
+		if err != nil {
+			return payload, err
+		}
+	}
+
+	conditionsOverhead := overhead
+	for _, condition := range group.conditions {
+		cost, err := p.measure(cfg, index, statementGroup{context: group.context, conditions: []string{condition}}, payload)
+		if err != nil {
+			return payload, err
+		}
+		location, _ := p.locator.locate(cfg.Key, condition)
+		p.costs = append(p.costs, newStatementCost(location, condition, true, cost.minus(overhead)))
+	}
+	if len(group.conditions) > 0 {
+		conditionsOverhead, err = p.measure(cfg, index, statementGroup{context: group.context, conditions: group.conditions}, payload)
+		if err != nil {
+			return payload, err
+		}
+	}
+
+	// Statements are measured against the payload as modified by the previous
+	// ones, which are applied one at a time, as the debugger does: with the
+	// cache they left, and on the records the group's conditions matched before
+	// the first one. Moving the cache and the mark is part of the overhead.
+	stash, stashed := newCacheStash(group)
+	mark, marked := newConditionsMark(group)
+	statementPayload := p.signal.copyPayload(payload)
+	for i, statement := range group.statements {
+		step := transformDebugStep{group: group, stash: stash, stashed: stashed, mark: mark, marked: marked, step: DebugStep{Index: i}}
+		statementOverhead := conditionsOverhead
+		if stashed || marked {
+			overheadGroup := statementGroup{context: group.context, conditions: step.conditions(), statements: step.surround()}
+			statementOverhead, err = p.measure(cfg, index, overheadGroup, statementPayload)
+			if err != nil {
+				return payload, err
+			}
+		}
+
+		stepGroup := statementGroup{context: group.context, conditions: step.conditions(), statements: step.statements()}
+		cost, err := p.measure(cfg, index, stepGroup, statementPayload)
+		if err != nil {
+			return payload, err
+		}
+		line := int64(pathIndex.Content[i].Line)
+		location, ok := p.locator.locateLine(cfg.Key, line, statement)
+		if !ok {
+			location.Line = line
+		}
+		p.costs = append(p.costs, newStatementCost(location, statement, false, cost.minus(statementOverhead)))
+
+		if statementPayload, err = p.consume(cfg, index, stepGroup, statementPayload); err != nil {
+			return payload, err
+		}
+	}
+
+	// The next group gets the payload modified by the whole group.
+	return p.consume(cfg, index, group, payload)
+}
+
+// step returns the configuration of cfg running the given group only, in place
+// of the group at the given index. The steps have no hash, so they aren't kept
+// by the consumer, and don't evict the processors kept for the executions.
+func (p *statementProfiler[T]) step(cfg parsedConfig[transformprocessor.Config], index int, group statementGroup) (parsedConfig[transformprocessor.Config], error) {
+	cp, err := cfg.clone()
+	if err != nil {
+		return parsedConfig[transformprocessor.Config]{}, err
+	}
+	p.signal.setGroup(&cp, index, group)
+	return parsedConfig[transformprocessor.Config]{Key: cfg.Key, Value: &cp}, nil
+}
+
+// measure returns the average cost of running the given group on the payload.
+// The processor running the group is started once for all the runs, which
+// only time the processor consuming the payload.
+func (p *statementProfiler[T]) measure(cfg parsedConfig[transformprocessor.Config], index int, group statementGroup, payload T) (runCost, error) {
+	stepConfig, err := p.step(cfg, index, group)
+	if err != nil {
+		return runCost{}, err
+	}
+	consume, release, err := p.signal.start(p.exec, stepConfig)
+	if err != nil {
+		return runCost{}, err
+	}
+	defer p.exec.discardTelemetry()
+	defer release()
+	durations, allocs, bytes, err := measureRuns(p.exec, p.limits, payload, p.signal.copyPayload, consume)
+	if err != nil {
+		return runCost{}, err
+	}
+	return newRunCost(durations, allocs, bytes), nil
+}
+
+// consume runs the given group on the payload once.
+func (p *statementProfiler[T]) consume(cfg parsedConfig[transformprocessor.Config], index int, group statementGroup, payload T) (T, error) {
+	stepConfig, err := p.step(cfg, index, group)
+	if err != nil {
+		return payload, err
+	}
+	defer p.exec.discardTelemetry()
+	return p.signal.consume(p.exec, stepConfig, payload)
+}
+
+func newStatementCost(location StatementLocation, statement string, condition bool, cost runCost) StatementCost {
+	return StatementCost{
+		StatementLocation: location,
+		Statement:         statement,
+		Condition:         condition,
+		Runs:              cost.runs,
+		Time:              float64(cost.time.Nanoseconds()) / float64(time.Microsecond),
+		AllocsPerRun:      cost.allocs,
+		BytesPerRun:       cost.bytes,
+	}
+}
+
+// rankStatementCosts sorts the costs from the highest to the lowest, and sets
+// their share of the total time.
+func rankStatementCosts(costs []StatementCost) {
+	var total float64
+	for _, cost := range costs {
+		total += cost.Time
+	}
+	for i := range costs {
+		if total > 0 {
+			costs[i].Share = costs[i].Time / total * 100
+		}
+	}
+	slices.SortStableFunc(costs, func(a, b StatementCost) int {
+		switch {
+		case a.Time > b.Time:
+			return -1
+		case a.Time < b.Time:
+			return 1
+		}
+		return 0
+	})
+}
+
+func NewTransformProcessorProfiler() Profiler {
+	return &transformProcessorProfiler{newTransformProcessorConsumer()}
+}
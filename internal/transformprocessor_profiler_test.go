@@ -0,0 +1,172 @@
+/*
+ * Copyright Elasticsearch B.V. and/or licensed to Elasticsearch B.V. under one
+ * or more contributor license agreements. See the NOTICE file distributed with
+ * this work for additional information regarding copyright
+ * ownership. Elasticsearch B.V. licenses this file to you under
+ * the Apache License, Version 2.0 (the "License"); you may
+ * not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *	http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing,
+ * software distributed under the License is distributed on an
+ * "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+ * KIND, either express or implied.  See the License for the
+ * specific language governing permissions and limitations
+ * under the License.
+ */
+
+package internal
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_transformProcessorProfiler_ProfileLogs(t *testing.T) {
+	profiler := NewTransformProcessorProfiler()
+	config := "transform:\n" +
+		"  log_statements:\n" +
+		"    - context: log\n" +
+		"      conditions:\n" +
+		"        - IsMatch(log.body, \"^a\")\n" +
+		"      statements:\n" +
+		"        - set(log.attributes[\"first\"], true)\n" +
+		"        - set(log.attributes[\"copy\"], log.attributes[\"first\"])\n"
+	payload := `{"resourceLogs":[{"scopeLogs":[{"logRecords":[{"body":{"stringValue":"a"}},{"body":{"stringValue":"b"}}]}]}]}`
+
+	result, err := profiler.ProfileLogs(config, payload, WithBenchmark(5, 0))
+	require.NoError(t, err)
+	assert.Contains(t, result.Value, `{"key":"copy","value":{"boolValue":true}}`)
+	assert.NotContains(t, result.Value, conditionsMatchKey)
+	require.Len(t, result.Profile, 3)
+
+	var totalShare float64
+	costs := map[string]StatementCost{}
+	for i, cost := range result.Profile {
+		if i > 0 {
+			assert.GreaterOrEqual(t, result.Profile[i-1].Time, cost.Time, "costs are ranked")
+		}
+		assert.Equal(t, 5, cost.Runs)
+		totalShare += cost.Share
+		costs[cost.Statement] = cost
+	}
+	if totalShare > 0 {
+		assert.InDelta(t, 100, totalShare, 0.001)
+	}
+
+	condition := costs[`IsMatch(log.body, "^a")`]
+	assert.True(t, condition.Condition)
+	assert.Equal(t, StatementLocation{ConfigKey: "transform", Path: "log_statements.0.conditions", Index: 0, Line: 5}, condition.StatementLocation)
+
+	statement := costs[`set(log.attributes["copy"], log.attributes["first"])`]
+	assert.False(t, statement.Condition)
+	assert.Equal(t, StatementLocation{ConfigKey: "transform", Path: "log_statements.0.statements", Index: 1, Line: 8}, statement.StatementLocation)
+}
+
+func Test_transformProcessorProfiler_ProfileTraces_FlatStatements(t *testing.T) {
+	profiler := NewTransformProcessorProfiler()
+	config := "transform:\n" +
+		"  trace_statements:\n" +
+		"    - set(resource.attributes[\"a\"], \"a\")\n" +
+		"    - set(span.attributes[\"b\"], resource.attributes[\"a\"])\n"
+	payload := `{"resourceSpans":[{"scopeSpans":[{"spans":[{"name":"span"}]}]}]}`
+
+	result, err := profiler.ProfileTraces(config, payload, WithBenchmark(2, 0))
+	require.NoError(t, err)
+	assert.Contains(t, result.Value, `{"key":"b","value":{"stringValue":"a"}}`)
+	require.Len(t, result.Profile, 2)
+	for _, cost := range result.Profile {
+		assert.Equal(t, "trace_statements", cost.Path)
+		assert.Positive(t, cost.Line)
+	}
+}
+
+func Test_transformProcessorProfiler_ProfileMetrics(t *testing.T) {
+	profiler := NewTransformProcessorProfiler()
+	config := "transform:\n  metric_statements:\n    - set(metric.description, \"desc\")"
+	payload := `{"resourceMetrics":[{"scopeMetrics":[{"metrics":[{"name":"m","gauge":{"dataPoints":[{"asInt":"1"}]}}]}]}]}`
+
+	result, err := profiler.ProfileMetrics(config, payload, WithBenchmark(2, 0))
+	require.NoError(t, err)
+	assert.Contains(t, result.Value, `"description":"desc"`)
+	require.Len(t, result.Profile, 1)
+}
+
+func Test_transformProcessorProfiler_ProfileProfiles(t *testing.T) {
+	profiler := NewTransformProcessorProfiler()
+	config := readTestData(t, transformprocessorConfig)
+	payload := readTestData(t, "profiles.json")
+
+	result, err := profiler.ProfileProfiles(config, payload, WithBenchmark(2, 0))
+	require.NoError(t, err)
+	assert.NotEmpty(t, result.Value)
+}
+
+func Test_transformProcessorProfiler_Cache(t *testing.T) {
+	profiler := NewTransformProcessorProfiler()
+	config := "transform:\n" +
+		"  log_statements:\n" +
+		"    - context: log\n" +
+		"      statements:\n" +
+		"        - set(log.cache[\"name\"], log.body)\n" +
+		"        - set(log.attributes[\"upper\"], ToUpperCase(log.cache[\"name\"]))\n"
+	payload := `{"resourceLogs":[{"scopeLogs":[{"logRecords":[{"body":{"stringValue":"a"}}]}]}]}`
+
+	// The second statement fails on an empty cache
+	result, err := profiler.ProfileLogs(config, payload, WithBenchmark(2, 0))
+	require.NoError(t, err)
+	assert.Contains(t, result.Value, `{"key":"upper","value":{"stringValue":"A"}}`)
+	assert.NotContains(t, result.Value, cacheStashKey)
+	require.Len(t, result.Profile, 2)
+}
+
+func Test_transformProcessorProfiler_DefaultRuns(t *testing.T) {
+	profiler := NewTransformProcessorProfiler()
+	config := "transform:\n  log_statements:\n    - set(log.attributes[\"a\"], 1)"
+	payload := `{"resourceLogs":[{"scopeLogs":[{"logRecords":[{"body":{"stringValue":"a"}}]}]}]}`
+
+	result, err := profiler.ProfileLogs(config, payload)
+	require.NoError(t, err)
+	require.Len(t, result.Profile, 1)
+	assert.Equal(t, defaultProfileRuns, result.Profile[0].Runs)
+}
+
+func Test_transformProcessorProfiler_Cancelled(t *testing.T) {
+	profiler := NewTransformProcessorProfiler()
+	config := "transform:\n  log_statements:\n    - set(log.attributes[\"a\"], 1)"
+	payload := `{"resourceLogs":[{"scopeLogs":[{"logRecords":[{"body":{"stringValue":"a"}}]}]}]}`
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	result, err := profiler.ProfileLogs(config, payload, WithContext(ctx), WithBenchmark(0, time.Hour))
+	require.NoError(t, err)
+	assert.True(t, result.Cancelled)
+}
+
+func Test_transformProcessorProfiler_InvalidConfig(t *testing.T) {
+	profiler := NewTransformProcessorProfiler()
+	_, err := profiler.ProfileLogs("transform:\n  log_statements:\n    - invalid(", "{}")
+	assert.Error(t, err)
+}
+
+func Test_rankStatementCosts(t *testing.T) {
+	costs := []StatementCost{{Statement: "a", Time: 1}, {Statement: "b", Time: 3}}
+	rankStatementCosts(costs)
+	assert.Equal(t, []StatementCost{{Statement: "b", Time: 3, Share: 75}, {Statement: "a", Time: 1, Share: 25}}, costs)
+}
+
+func Test_Executor_Profiler(t *testing.T) {
+	profiler, err := NewTransformProcessorExecutor().(ProfilableExecutor).Profiler()
+	require.NoError(t, err)
+	assert.NotNil(t, profiler)
+	assert.True(t, NewTransformProcessorExecutor().Metadata().Profilable)
+
+	_, err = NewFilterProcessorExecutor().(ProfilableExecutor).Profiler()
+	assert.ErrorContains(t, err, "does not support profiling")
+}
@@ -0,0 +1,322 @@
+/*
+ * Copyright Elasticsearch B.V. and/or licensed to Elasticsearch B.V. under one
+ * or more contributor license agreements. See the NOTICE file distributed with
+ * this work for additional information regarding copyright
+ * ownership. Elasticsearch B.V. licenses this file to you under
+ * the Apache License, Version 2.0 (the "License"); you may
+ * not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *	http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing,
+ * software distributed under the License is distributed on an
+ * "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+ * KIND, either express or implied.  See the License for the
+ * specific language governing permissions and limitations
+ * under the License.
+ */
+
+package internal
+
+import (
+	"slices"
+
+	"github.com/open-telemetry/opentelemetry-collector-contrib/processor/transformprocessor"
+	"go.opentelemetry.io/collector/pdata/pcommon"
+	"go.opentelemetry.io/collector/pdata/plog"
+	"go.opentelemetry.io/collector/pdata/pmetric"
+	"go.opentelemetry.io/collector/pdata/pprofile"
+	"go.opentelemetry.io/collector/pdata/ptrace"
+)
+
+// statementGroup is a signal-agnostic view of the statements a transform
+// processor configuration runs in the same context.
+type statementGroup struct {
+	context    string
+	conditions []string
+	statements []string
+}
+
+// transformSignal adapts a signal's statements and data to the signal-agnostic
+// transform processor debugger and profiler.
+type transformSignal[T any] struct {
+	// configKey is the configuration key holding the signal's statements, such
+	// as "log_statements".
+	configKey string
+	// groups returns the statement groups of the signal in cfg.
+	groups func(cfg *transformprocessor.Config) []statementGroup
+	// setGroup replaces the signal's statement groups in cfg with the one at
+	// the given index, holding the given group's context, conditions and
+	// statements instead.
+	setGroup    func(cfg *transformprocessor.Config, index int, group statementGroup)
+	unmarshal   func([]byte) (T, error)
+	marshal     func(T) ([]byte, error)
+	copyPayload func(T) T
+	consume     func(exec *execution, cfg parsedConfig[transformprocessor.Config], payload T) (T, error)
+	// start starts a processor running cfg, and returns the function passing
+	// it a payload, and the one shutting it down, see processorConsumer.startLogs.
+	start func(exec *execution, cfg parsedConfig[transformprocessor.Config]) (func(T) (T, error), func(), error)
+	// records calls visit with the attributes of each record of the given
+	// context, and the record's position from the resource down.
+	records func(payload T, context string, visit func(attributes pcommon.Map, index []int))
+}
+
+func newLogsTransformSignal(consumer *processorConsumer[transformprocessor.Config]) transformSignal[plog.Logs] {
+	um := plog.JSONUnmarshaler{}
+	ma := plog.JSONMarshaler{}
+	return transformSignal[plog.Logs]{
+		configKey: "log_statements",
+		groups: func(cfg *transformprocessor.Config) []statementGroup {
+			groups := make([]statementGroup, 0, len(cfg.LogStatements))
+			for _, cs := range cfg.LogStatements {
+				groups = append(groups, statementGroup{string(cs.Context), cs.Conditions, cs.Statements})
+			}
+			return groups
+		},
+		setGroup: func(cfg *transformprocessor.Config, index int, group statementGroup) {
+			cs := cfg.LogStatements[index]
+			setString(&cs.Context, group.context)
+			cs.Conditions, cs.Statements = group.conditions, group.statements
+			cfg.LogStatements = append(cfg.LogStatements[:0:0], cs)
+		},
+		unmarshal:   um.UnmarshalLogs,
+		marshal:     ma.MarshalLogs,
+		copyPayload: copyLogs,
+		records:     visitLogs,
+		consume:     consumer.ConsumeLogs,
+		start:       consumer.startLogs,
+	}
+}
+
+func newTracesTransformSignal(consumer *processorConsumer[transformprocessor.Config]) transformSignal[ptrace.Traces] {
+	um := ptrace.JSONUnmarshaler{}
+	ma := ptrace.JSONMarshaler{}
+	return transformSignal[ptrace.Traces]{
+		configKey: "trace_statements",
+		groups: func(cfg *transformprocessor.Config) []statementGroup {
+			groups := make([]statementGroup, 0, len(cfg.TraceStatements))
+			for _, cs := range cfg.TraceStatements {
+				groups = append(groups, statementGroup{string(cs.Context), cs.Conditions, cs.Statements})
+			}
+			return groups
+		},
+		setGroup: func(cfg *transformprocessor.Config, index int, group statementGroup) {
+			cs := cfg.TraceStatements[index]
+			setString(&cs.Context, group.context)
+			cs.Conditions, cs.Statements = group.conditions, group.statements
+			cfg.TraceStatements = append(cfg.TraceStatements[:0:0], cs)
+		},
+		unmarshal:   um.UnmarshalTraces,
+		marshal:     ma.MarshalTraces,
+		copyPayload: copyTraces,
+		records:     visitTraces,
+		consume:     consumer.ConsumeTraces,
+		start:       consumer.startTraces,
+	}
+}
+
+func newMetricsTransformSignal(consumer *processorConsumer[transformprocessor.Config]) transformSignal[pmetric.Metrics] {
+	um := pmetric.JSONUnmarshaler{}
+	ma := pmetric.JSONMarshaler{}
+	return transformSignal[pmetric.Metrics]{
+		configKey: "metric_statements",
+		groups: func(cfg *transformprocessor.Config) []statementGroup {
+			groups := make([]statementGroup, 0, len(cfg.MetricStatements))
+			for _, cs := range cfg.MetricStatements {
+				groups = append(groups, statementGroup{string(cs.Context), cs.Conditions, cs.Statements})
+			}
+			return groups
+		},
+		setGroup: func(cfg *transformprocessor.Config, index int, group statementGroup) {
+			cs := cfg.MetricStatements[index]
+			setString(&cs.Context, group.context)
+			cs.Conditions, cs.Statements = group.conditions, group.statements
+			cfg.MetricStatements = append(cfg.MetricStatements[:0:0], cs)
+		},
+		unmarshal:   um.UnmarshalMetrics,
+		marshal:     ma.MarshalMetrics,
+		copyPayload: copyMetrics,
+		records:     visitMetrics,
+		consume:     consumer.ConsumeMetrics,
+		start:       consumer.startMetrics,
+	}
+}
+
+func newProfilesTransformSignal(consumer *processorConsumer[transformprocessor.Config]) transformSignal[pprofile.Profiles] {
+	um := pprofile.JSONUnmarshaler{}
+	ma := pprofile.JSONMarshaler{}
+	return transformSignal[pprofile.Profiles]{
+		configKey: "profile_statements",
+		groups: func(cfg *transformprocessor.Config) []statementGroup {
+			groups := make([]statementGroup, 0, len(cfg.ProfileStatements))
+			for _, cs := range cfg.ProfileStatements {
+				groups = append(groups, statementGroup{string(cs.Context), cs.Conditions, cs.Statements})
+			}
+			return groups
+		},
+		setGroup: func(cfg *transformprocessor.Config, index int, group statementGroup) {
+			cs := cfg.ProfileStatements[index]
+			setString(&cs.Context, group.context)
+			cs.Conditions, cs.Statements = group.conditions, group.statements
+			cfg.ProfileStatements = append(cfg.ProfileStatements[:0:0], cs)
+		},
+		unmarshal:   um.UnmarshalProfiles,
+		marshal:     ma.MarshalProfiles,
+		copyPayload: copyProfiles,
+		records:     visitProfiles,
+		consume:     consumer.ConsumeProfiles,
+		start:       consumer.startProfiles,
+	}
+}
+
+// markedRecords holds the positions of records, from the resource down, split
+// by whether they hold a marker attribute.
+type markedRecords struct {
+	marked   [][]int
+	unmarked [][]int
+}
+
+// marked returns the records of the given context holding the given
+// attribute, and the ones that don't.
+func (s transformSignal[T]) marked(payload T, context, key string) markedRecords {
+	var records markedRecords
+	s.records(payload, context, func(attributes pcommon.Map, index []int) {
+		if _, ok := attributes.Get(key); ok {
+			records.marked = append(records.marked, index)
+		} else {
+			records.unmarked = append(records.unmarked, index)
+		}
+	})
+	return records
+}
+
+// caches returns the cache stashed in each record of the given context, see
+// cacheStash.
+func (s transformSignal[T]) caches(payload T, context string) []RecordCache {
+	var caches []RecordCache
+	s.records(payload, context, func(attributes pcommon.Map, index []int) {
+		if stashed, ok := attributes.Get(cacheStashKey); ok && stashed.Type() == pcommon.ValueTypeMap {
+			caches = append(caches, RecordCache{Index: index, Cache: stashed.Map().AsRaw()})
+		}
+	})
+	return caches
+}
+
+func visitLogs(ld plog.Logs, context string, visit func(attributes pcommon.Map, index []int)) {
+	for r, rl := range ld.ResourceLogs().All() {
+		if context == "resource" {
+			visit(rl.Resource().Attributes(), []int{r})
+			continue
+		}
+		for s, sl := range rl.ScopeLogs().All() {
+			if context == "scope" {
+				visit(sl.Scope().Attributes(), []int{r, s})
+				continue
+			}
+			for l, lr := range sl.LogRecords().All() {
+				visit(lr.Attributes(), []int{r, s, l})
+			}
+		}
+	}
+}
+
+func visitTraces(td ptrace.Traces, context string, visit func(attributes pcommon.Map, index []int)) {
+	for r, rs := range td.ResourceSpans().All() {
+		if context == "resource" {
+			visit(rs.Resource().Attributes(), []int{r})
+			continue
+		}
+		for s, ss := range rs.ScopeSpans().All() {
+			if context == "scope" {
+				visit(ss.Scope().Attributes(), []int{r, s})
+				continue
+			}
+			for sp, span := range ss.Spans().All() {
+				if context == "span" {
+					visit(span.Attributes(), []int{r, s, sp})
+					continue
+				}
+				for e, event := range span.Events().All() {
+					visit(event.Attributes(), []int{r, s, sp, e})
+				}
+			}
+		}
+	}
+}
+
+func visitMetrics(md pmetric.Metrics, context string, visit func(attributes pcommon.Map, index []int)) {
+	for r, rm := range md.ResourceMetrics().All() {
+		if context == "resource" {
+			visit(rm.Resource().Attributes(), []int{r})
+			continue
+		}
+		for s, sm := range rm.ScopeMetrics().All() {
+			if context == "scope" {
+				visit(sm.Scope().Attributes(), []int{r, s})
+				continue
+			}
+			for m, metric := range sm.Metrics().All() {
+				if context == "metric" {
+					visit(metric.Metadata(), []int{r, s, m})
+					continue
+				}
+				for d, attributes := range dataPointAttributes(metric) {
+					visit(attributes, []int{r, s, m, d})
+				}
+			}
+		}
+	}
+}
+
+func visitProfiles(pd pprofile.Profiles, context string, visit func(attributes pcommon.Map, index []int)) {
+	dictionary := pd.Dictionary()
+	for r, rp := range pd.ResourceProfiles().All() {
+		if context == "resource" {
+			visit(rp.Resource().Attributes(), []int{r})
+			continue
+		}
+		for s, sp := range rp.ScopeProfiles().All() {
+			if context == "scope" {
+				visit(sp.Scope().Attributes(), []int{r, s})
+				continue
+			}
+			for p, profile := range sp.Profiles().All() {
+				visit(pprofile.FromAttributeIndices(dictionary.AttributeTable(), profile, dictionary), []int{r, s, p})
+			}
+		}
+	}
+}
+
+// dataPointAttributes returns the attributes of each data point of the metric.
+func dataPointAttributes(metric pmetric.Metric) []pcommon.Map {
+	dataPoints := metricDataPoints(metric)
+	attributes := make([]pcommon.Map, 0, len(dataPoints))
+	for _, dp := range dataPoints {
+		attributes = append(attributes, dp.(interface{ Attributes() pcommon.Map }).Attributes())
+	}
+	return attributes
+}
+
+// setString sets a string-based value, whose type may not be importable.
+func setString[S ~string](dst *S, value string) {
+	*dst = S(value)
+}
+
+// groupContext returns the context inferred from the paths used by the group's
+// statements and conditions, that is, the most specific one, or an empty string
+// if it can't be inferred.
+func groupContext(group statementGroup) string {
+	used := map[string]bool{}
+	for _, text := range slices.Concat(group.conditions, group.statements) {
+		if context := statementContext(locatedStatement{text: text}); context != "" {
+			used[context] = true
+		}
+	}
+	for _, name := range inferredContexts {
+		if used[name] {
+			return name
+		}
+	}
+	return ""
+}
@@ -24,8 +24,11 @@ import (
 	"fmt"
 	"slices"
 	"strings"
+	"sync"
+	"time"
 
 	"github.com/elastic/ottl-playground/internal"
+	"go.uber.org/zap/zapcore"
 )
 
 var (
@@ -49,43 +52,147 @@ func registerStatementsExecutor(executor internal.Executor) {
 	statementsExecutorsLookup[executor.Metadata().ID] = executor
 }
 
+// ExecutionOptions holds the optional settings of an execution request.
+type ExecutionOptions struct {
+	// LogLevel is the minimum level of the logs included in the result,
+	// defaults to debug.
+	LogLevel string `json:"logLevel,omitempty"`
+	// FeatureGates enables or disables feature gates, keyed by ID, for the
+	// execution only.
+	FeatureGates map[string]bool `json:"featureGates,omitempty"`
+	// ClientMetadata holds the client metadata, such as request headers,
+	// injected into the components' context.
+	ClientMetadata map[string][]string `json:"clientMetadata,omitempty"`
+	// AuthAttributes holds the client auth attributes injected into the
+	// components' context.
+	AuthAttributes map[string]any `json:"authAttributes,omitempty"`
+	// TimeoutMillis cancels the execution after the given number of
+	// milliseconds, returning the partial result.
+	TimeoutMillis int64 `json:"timeoutMillis,omitempty"`
+	// FixedTime is the RFC 3339 timestamp returned by the OTTL Now() function,
+	// making the statements using it produce the same output on every run.
+	FixedTime string `json:"fixedTime,omitempty"`
+	// BenchmarkRuns and BenchmarkDurationMillis run the configuration again
+	// the given number of times, or for the given number of milliseconds,
+	// reporting the runs' statistics in the result.
+	BenchmarkRuns           int   `json:"benchmarkRuns,omitempty"`
+	BenchmarkDurationMillis int64 `json:"benchmarkDurationMillis,omitempty"`
+	// Profile measures the cost of each statement and condition instead of
+	// only executing the configuration, see internal.Profiler. The benchmark
+	// options set the number of runs of each of them.
+	Profile bool `json:"profile,omitempty"`
+}
+
+// ParseExecutionOptions decodes the JSON-encoded execution options. An empty
+// string yields the default options.
+func ParseExecutionOptions(options string) (ExecutionOptions, error) {
+	var executionOptions ExecutionOptions
+	if options == "" {
+		return executionOptions, nil
+	}
+	if err := json.Unmarshal([]byte(options), &executionOptions); err != nil {
+		return executionOptions, fmt.Errorf("invalid execution options: %w", err)
+	}
+	return executionOptions, nil
+}
+
+func (o ExecutionOptions) executionOptions() ([]internal.ExecutionOption, error) {
+	var options []internal.ExecutionOption
+	if o.LogLevel != "" {
+		level, err := zapcore.ParseLevel(o.LogLevel)
+		if err != nil {
+			return nil, err
+		}
+		options = append(options, internal.WithLogLevel(level))
+	}
+	if len(o.FeatureGates) > 0 {
+		options = append(options, internal.WithFeatureGates(o.FeatureGates))
+	}
+	if o.ClientMetadata != nil {
+		options = append(options, internal.WithClientMetadata(o.ClientMetadata))
+	}
+	if o.AuthAttributes != nil {
+		options = append(options, internal.WithAuthAttributes(o.AuthAttributes))
+	}
+	if o.TimeoutMillis < 0 {
+		return nil, fmt.Errorf("invalid timeout %dms", o.TimeoutMillis)
+	}
+	if o.TimeoutMillis > 0 {
+		options = append(options, internal.WithTimeout(time.Duration(o.TimeoutMillis)*time.Millisecond))
+	}
+	if o.FixedTime != "" {
+		fixedTime, err := time.Parse(time.RFC3339Nano, o.FixedTime)
+		if err != nil {
+			return nil, fmt.Errorf("invalid fixed time %q: %w", o.FixedTime, err)
+		}
+		options = append(options, internal.WithFixedTime(fixedTime))
+	}
+	if o.BenchmarkRuns < 0 {
+		return nil, fmt.Errorf("invalid benchmark runs %d", o.BenchmarkRuns)
+	}
+	if o.BenchmarkDurationMillis < 0 {
+		return nil, fmt.Errorf("invalid benchmark duration %dms", o.BenchmarkDurationMillis)
+	}
+	if o.BenchmarkRuns > 0 || o.BenchmarkDurationMillis > 0 {
+		options = append(options, internal.WithBenchmark(o.BenchmarkRuns, time.Duration(o.BenchmarkDurationMillis)*time.Millisecond))
+	}
+	return options, nil
+}
+
 func Execute(config, signal, ottlDataPayload, executorName string, debug bool) map[string]any {
+	return ExecuteWithOptions(config, signal, ottlDataPayload, executorName, debug, ExecutionOptions{})
+}
+
+func ExecuteWithOptions(config, signal, ottlDataPayload, executorName string, debug bool, options ExecutionOptions) map[string]any {
 	executor, ok := statementsExecutorsLookup[executorName]
 	if !ok {
 		return internal.NewErrorResult(fmt.Sprintf("unsupported executor %s", executorName), "").AsRaw()
 	}
 
+	executionOptions, err := options.executionOptions()
+	if err != nil {
+		return internal.NewErrorResult(fmt.Sprintf("invalid execution options: %v", err), "").AsRaw()
+	}
+
 	var result *internal.Result
-	var err error
-	if debug {
-		result, err = debugConfig(config, signal, ottlDataPayload, executorName, executor)
+	if debug && options.Profile {
+		return internal.NewErrorResult("invalid execution options: profiling and debugging can't be combined", "").AsRaw()
+	}
+	if options.Profile {
+		result, err = profileConfig(config, signal, ottlDataPayload, executorName, executor, executionOptions...)
+	} else if debug {
+		result, err = debugConfig(config, signal, ottlDataPayload, executorName, executor, executionOptions...)
 	} else {
-		result, err = executeConfig(config, signal, ottlDataPayload, executorName, executor)
+		result, err = executeConfig(config, signal, ottlDataPayload, executorName, executor, executionOptions...)
 	}
 
 	if err != nil {
-		result = internal.NewErrorResult(fmt.Sprintf("unable to run %s configuration. Error: %v", signal, err), executor.ObservedLogs().TakeAllString())
+		var logs string
+		if result != nil {
+			logs = result.Logs
+		}
+		result = internal.NewErrorResult(fmt.Sprintf("unable to run %s configuration. Error: %v", signal, err), logs)
 	}
 
 	return result.AsRaw()
 }
 
-func executeConfig(config, signal, ottlDataPayload, _ string, executor internal.Executor) (*internal.Result, error) {
+func executeConfig(config, signal, ottlDataPayload, _ string, executor internal.Executor, options ...internal.ExecutionOption) (*internal.Result, error) {
 	switch signal {
 	case "logs":
-		return executor.ExecuteLogs(config, ottlDataPayload)
+		return executor.ExecuteLogs(config, ottlDataPayload, options...)
 	case "traces":
-		return executor.ExecuteTraces(config, ottlDataPayload)
+		return executor.ExecuteTraces(config, ottlDataPayload, options...)
 	case "metrics":
-		return executor.ExecuteMetrics(config, ottlDataPayload)
+		return executor.ExecuteMetrics(config, ottlDataPayload, options...)
 	case "profiles":
-		return executor.ExecuteProfiles(config, ottlDataPayload)
+		return executor.ExecuteProfiles(config, ottlDataPayload, options...)
 	default:
 		return internal.NewErrorResult(fmt.Sprintf("unsupported OTLP signal type %s", signal), ""), nil
 	}
 }
 
-func debugConfig(config, signal, ottlDataPayload, executorName string, executor internal.Executor) (*internal.Result, error) {
+func debugConfig(config, signal, ottlDataPayload, executorName string, executor internal.Executor, options ...internal.ExecutionOption) (*internal.Result, error) {
 	debuggableExecutor, ok := executor.(internal.DebuggableExecutor)
 	if !ok {
 		return internal.NewErrorResult(fmt.Sprintf("executor %q does not support debugging", executorName), ""), nil
@@ -98,18 +205,226 @@ func debugConfig(config, signal, ottlDataPayload, executorName string, executor
 
 	switch signal {
 	case "logs":
-		return debugger.DebugLogs(config, ottlDataPayload)
+		return debugger.DebugLogs(config, ottlDataPayload, options...)
+	case "traces":
+		return debugger.DebugTraces(config, ottlDataPayload, options...)
+	case "metrics":
+		return debugger.DebugMetrics(config, ottlDataPayload, options...)
+	case "profiles":
+		return debugger.DebugProfiles(config, ottlDataPayload, options...)
+	default:
+		return internal.NewErrorResult(fmt.Sprintf("unsupported OTLP signal type %s", signal), ""), nil
+	}
+}
+
+func profileConfig(config, signal, ottlDataPayload, executorName string, executor internal.Executor, options ...internal.ExecutionOption) (*internal.Result, error) {
+	profilableExecutor, ok := executor.(internal.ProfilableExecutor)
+	if !ok {
+		return internal.NewErrorResult(fmt.Sprintf("executor %q does not support profiling", executorName), ""), nil
+	}
+
+	profiler, err := profilableExecutor.Profiler()
+	if err != nil {
+		return nil, err
+	}
+
+	switch signal {
+	case "logs":
+		return profiler.ProfileLogs(config, ottlDataPayload, options...)
 	case "traces":
-		return debugger.DebugTraces(config, ottlDataPayload)
+		return profiler.ProfileTraces(config, ottlDataPayload, options...)
 	case "metrics":
-		return debugger.DebugMetrics(config, ottlDataPayload)
+		return profiler.ProfileMetrics(config, ottlDataPayload, options...)
 	case "profiles":
-		return debugger.DebugProfiles(config, ottlDataPayload)
+		return profiler.ProfileProfiles(config, ottlDataPayload, options...)
 	default:
 		return internal.NewErrorResult(fmt.Sprintf("unsupported OTLP signal type %s", signal), ""), nil
 	}
 }
 
+// maxDebugSessions is the number of paused debug sessions kept open. Sessions
+// may be abandoned without being closed, so the least recently used one is
+// closed to open a new one.
+const maxDebugSessions = 8
+
+// pausedDebugSession is a paused debug session, and the sequence number of the
+// last call it was paused by, telling the least recently used sessions.
+type pausedDebugSession struct {
+	session internal.DebugSession
+	used    int
+}
+
+var (
+	debugSessions     = map[int]pausedDebugSession{}
+	debugSessionsLock sync.Mutex
+	lastDebugSession  int
+	lastDebugCall     int
+)
+
+// StartDebugSession debugs the configuration like ExecuteWithOptions, running
+// its steps up to the first of the JSON-encoded breakpoints, see
+// internal.Breakpoint. Unless the session ran all of its steps, the result
+// holds the handle of the session under the "session" key, which the other
+// debug session functions take. Sessions are closed once they ran all of their
+// steps, or failed, and the least recently used ones once more than
+// maxDebugSessions are paused.
+func StartDebugSession(config, signal, ottlDataPayload, executorName, breakpoints string, options ExecutionOptions) map[string]any {
+	executor, ok := statementsExecutorsLookup[executorName]
+	if !ok {
+		return internal.NewErrorResult(fmt.Sprintf("unsupported executor %s", executorName), "").AsRaw()
+	}
+
+	if options.Profile {
+		return internal.NewErrorResult("invalid execution options: profiling and debugging can't be combined", "").AsRaw()
+	}
+	executionOptions, err := options.executionOptions()
+	if err != nil {
+		return internal.NewErrorResult(fmt.Sprintf("invalid execution options: %v", err), "").AsRaw()
+	}
+
+	var sessionBreakpoints []internal.Breakpoint
+	if breakpoints != "" {
+		if err = json.Unmarshal([]byte(breakpoints), &sessionBreakpoints); err != nil {
+			return internal.NewErrorResult(fmt.Sprintf("invalid breakpoints: %v", err), "").AsRaw()
+		}
+	}
+
+	session, result, err := startDebugSession(config, signal, ottlDataPayload, executorName, executor, sessionBreakpoints, executionOptions...)
+	if err != nil {
+		var logs string
+		if result != nil {
+			logs = result.Logs
+		}
+		return internal.NewErrorResult(fmt.Sprintf("unable to run %s configuration. Error: %v", signal, err), logs).AsRaw()
+	}
+	debugSessionsLock.Lock()
+	lastDebugSession++
+	handle := lastDebugSession
+	debugSessionsLock.Unlock()
+	return debugSessionResult(handle, session, result)
+}
+
+// StepDebugSession runs the next step of the debug session with the given
+// handle, see StartDebugSession.
+func StepDebugSession(handle int) map[string]any {
+	return runDebugSession(handle, internal.DebugSession.Step)
+}
+
+// ContinueDebugSession runs the next step of the debug session with the given
+// handle, and the following ones up to the next breakpoint, see
+// StartDebugSession.
+func ContinueDebugSession(handle int) map[string]any {
+	return runDebugSession(handle, internal.DebugSession.Continue)
+}
+
+// CloseDebugSession closes the debug session with the given handle, if it's
+// still open.
+func CloseDebugSession(handle int) {
+	debugSessionsLock.Lock()
+	paused, ok := debugSessions[handle]
+	delete(debugSessions, handle)
+	debugSessionsLock.Unlock()
+	if ok {
+		paused.session.Close()
+	}
+}
+
+func runDebugSession(handle int, run func(internal.DebugSession) (*internal.Result, error)) map[string]any {
+	debugSessionsLock.Lock()
+	paused, ok := debugSessions[handle]
+	delete(debugSessions, handle)
+	debugSessionsLock.Unlock()
+	if !ok {
+		return internal.NewErrorResult(fmt.Sprintf("unknown debug session %d", handle), "").AsRaw()
+	}
+	session := paused.session
+
+	result, err := run(session)
+	if err != nil {
+		session.Close()
+		var logs string
+		if result != nil {
+			logs = result.Logs
+		}
+		return internal.NewErrorResult(fmt.Sprintf("unable to run debug session. Error: %v", err), logs).AsRaw()
+	}
+	return debugSessionResult(handle, session, result)
+}
+
+// debugSessionResult returns the raw result of a debug session call, holding
+// the session's handle if it's paused. Otherwise, the session is closed.
+func debugSessionResult(handle int, session internal.DebugSession, result *internal.Result) map[string]any {
+	if result.Paused == nil {
+		session.Close()
+		return result.AsRaw()
+	}
+
+	debugSessionsLock.Lock()
+	var evicted []internal.DebugSession
+	for len(debugSessions) >= maxDebugSessions {
+		oldest := leastRecentlyUsedDebugSession()
+		evicted = append(evicted, debugSessions[oldest].session)
+		delete(debugSessions, oldest)
+	}
+	lastDebugCall++
+	debugSessions[handle] = pausedDebugSession{session: session, used: lastDebugCall}
+	debugSessionsLock.Unlock()
+	for _, session := range evicted {
+		session.Close()
+	}
+
+	raw := result.AsRaw()
+	raw["session"] = handle
+	return raw
+}
+
+// leastRecentlyUsedDebugSession returns the handle of the paused debug session
+// used the least recently. It must be called holding debugSessionsLock.
+func leastRecentlyUsedDebugSession() int {
+	oldest := -1
+	for handle, paused := range debugSessions {
+		if oldest < 0 || paused.used < debugSessions[oldest].used {
+			oldest = handle
+		}
+	}
+	return oldest
+}
+
+func startDebugSession(
+	config, signal, ottlDataPayload, executorName string,
+	executor internal.Executor,
+	breakpoints []internal.Breakpoint,
+	options ...internal.ExecutionOption,
+) (internal.DebugSession, *internal.Result, error) {
+	debuggableExecutor, ok := executor.(internal.DebuggableExecutor)
+	if !ok {
+		return nil, nil, fmt.Errorf("executor %q does not support debugging", executorName)
+	}
+
+	debugger, err := debuggableExecutor.Debugger()
+	if err != nil {
+		return nil, nil, err
+	}
+
+	sessionDebugger, ok := debugger.(internal.SessionDebugger)
+	if !ok {
+		return nil, nil, fmt.Errorf("executor %q does not support debug sessions", executorName)
+	}
+
+	switch signal {
+	case "logs":
+		return sessionDebugger.StartLogs(config, ottlDataPayload, breakpoints, options...)
+	case "traces":
+		return sessionDebugger.StartTraces(config, ottlDataPayload, breakpoints, options...)
+	case "metrics":
+		return sessionDebugger.StartMetrics(config, ottlDataPayload, breakpoints, options...)
+	case "profiles":
+		return sessionDebugger.StartProfiles(config, ottlDataPayload, breakpoints, options...)
+	default:
+		return nil, nil, fmt.Errorf("unsupported OTLP signal type %s", signal)
+	}
+}
+
 func Executors() []any {
 	var res []any
 	for _, executor := range statementsExecutors {
@@ -127,3 +442,15 @@ func Executors() []any {
 	}
 	return res
 }
+
+func FeatureGates() []any {
+	var res []any
+	for _, gate := range internal.FeatureGates() {
+		var gateValue map[string]any
+		if gateBytes, err := json.Marshal(gate); err == nil {
+			_ = json.Unmarshal(gateBytes, &gateValue)
+		}
+		res = append(res, gateValue)
+	}
+	return res
+}
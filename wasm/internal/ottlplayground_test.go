@@ -27,7 +27,7 @@ import (
 	"github.com/elastic/ottl-playground/internal"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
-	"go.uber.org/zap"
+	"github.com/stretchr/testify/require"
 )
 
 func Test_NewErrorResult(t *testing.T) {
@@ -164,7 +164,6 @@ func Test_ExecuteStatements(t *testing.T) {
 		ottlDataPayload = "{}"
 	)
 
-	_, observedLogs := internal.NewLogObserver(zap.NewNop().Core(), zap.NewDevelopmentEncoderConfig())
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
 			executorName := tt.name
@@ -179,9 +178,6 @@ func Test_ExecuteStatements(t *testing.T) {
 
 			registerStatementsExecutor(mockExecutor)
 			mockExecutor.On(tt.executorFunc, testConfig, ottlDataPayload).Return(&internal.Result{Value: tt.expectedOutput, Debug: tt.debug}, tt.expectedError)
-			if tt.expectedError != nil {
-				mockExecutor.On("ObservedLogs").Return(observedLogs)
-			}
 
 			result := Execute(testConfig, tt.otlpDataType, ottlDataPayload, executorName, tt.debug)
 
@@ -202,17 +198,27 @@ func Test_ExecuteStatements(t *testing.T) {
 	}
 }
 
-func Test_TakeObserved_Logs(t *testing.T) {
-	mockExecutor := new(MockExecutor)
-	core, observedLogs := internal.NewLogObserver(zap.DebugLevel, zap.NewDevelopmentEncoderConfig())
-	mockExecutor.On("ObservedLogs").Return(observedLogs)
+func Test_ExecuteStatements_ErrorLogs(t *testing.T) {
+	config := "empty"
+	otlpDataType := "logs"
+	otlpDataPayload := "{}"
+	executorName := "failing_processor_with_logs"
+
+	mockExecutor := &MockExecutor{
+		metadata: internal.Metadata{
+			ID:   executorName,
+			Name: executorName,
+		},
+	}
 
-	logger := zap.New(core)
-	logger.Debug("debug logs")
+	registerStatementsExecutor(mockExecutor)
+	mockExecutor.On("ExecuteLogs", config, otlpDataPayload).Return(&internal.Result{Logs: "execution logs"}, errors.New("execution error"))
 
-	logs := mockExecutor.ObservedLogs().TakeAllString()
+	result := Execute(config, otlpDataType, otlpDataPayload, executorName, false)
 
-	assert.Contains(t, logs, "debug logs")
+	assert.Equal(t, "", result["value"])
+	assert.Equal(t, "execution logs", result["logs"])
+	assert.Contains(t, result["error"], "execution error")
 	mockExecutor.AssertExpectations(t)
 }
 
@@ -232,8 +238,6 @@ func Test_ExecuteStatements_Debug_UnsupportedExecutor(t *testing.T) {
 	}
 
 	registerStatementsExecutor(mockExecutor)
-	_, observedLogs := internal.NewLogObserver(zap.NewNop().Core(), zap.NewDevelopmentEncoderConfig())
-	mockExecutor.On("ObservedLogs").Return(observedLogs)
 
 	result := Execute(config, otlpDataType, otlpDataPayload, executorName, true)
 
@@ -306,7 +310,6 @@ func Test_ExecuteStatements_Debug(t *testing.T) {
 		ottlDataPayload = "{}"
 	)
 
-	_, observedLogs := internal.NewLogObserver(zap.NewNop().Core(), zap.NewDevelopmentEncoderConfig())
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
 			executorName := tt.name
@@ -324,7 +327,6 @@ func Test_ExecuteStatements_Debug(t *testing.T) {
 
 			if tt.expectedError != nil {
 				mockDebugger.On(tt.debugFunc, testConfig, ottlDataPayload).Return(&internal.Result{Debug: true}, tt.expectedError)
-				mockExecutor.On("ObservedLogs").Return(observedLogs)
 			} else {
 				result := &internal.Result{
 					Value: tt.expectedOutput,
@@ -396,8 +398,6 @@ func Test_ExecuteStatements_Debug_DebuggerCreationError(t *testing.T) {
 	}
 
 	registerStatementsExecutor(mockExecutor)
-	_, observedLogs := internal.NewLogObserver(zap.NewNop().Core(), zap.NewDevelopmentEncoderConfig())
-	mockExecutor.On("ObservedLogs").Return(observedLogs)
 
 	result := Execute(config, otlpDataType, otlpDataPayload, executorName, true)
 
@@ -414,31 +414,26 @@ type MockExecutor struct {
 	debugger   *MockDebugger
 }
 
-func (m *MockExecutor) ExecuteLogs(config, payload string) (*internal.Result, error) {
+func (m *MockExecutor) ExecuteLogs(config, payload string, _ ...internal.ExecutionOption) (*internal.Result, error) {
 	args := m.Called(config, payload)
 	return args.Get(0).(*internal.Result), args.Error(1)
 }
 
-func (m *MockExecutor) ExecuteTraces(config, payload string) (*internal.Result, error) {
+func (m *MockExecutor) ExecuteTraces(config, payload string, _ ...internal.ExecutionOption) (*internal.Result, error) {
 	args := m.Called(config, payload)
 	return args.Get(0).(*internal.Result), args.Error(1)
 }
 
-func (m *MockExecutor) ExecuteMetrics(config, payload string) (*internal.Result, error) {
+func (m *MockExecutor) ExecuteMetrics(config, payload string, _ ...internal.ExecutionOption) (*internal.Result, error) {
 	args := m.Called(config, payload)
 	return args.Get(0).(*internal.Result), args.Error(1)
 }
 
-func (m *MockExecutor) ExecuteProfiles(config, payload string) (*internal.Result, error) {
+func (m *MockExecutor) ExecuteProfiles(config, payload string, _ ...internal.ExecutionOption) (*internal.Result, error) {
 	args := m.Called(config, payload)
 	return args.Get(0).(*internal.Result), args.Error(1)
 }
 
-func (m *MockExecutor) ObservedLogs() *internal.ObservedLogs {
-	args := m.Called()
-	return args.Get(0).(*internal.ObservedLogs)
-}
-
 func (m *MockExecutor) Metadata() *internal.Metadata {
 	return &m.metadata
 }
@@ -454,27 +449,252 @@ type MockDebugger struct {
 	mock.Mock
 }
 
-func (m *MockDebugger) DebugLogs(config, payload string) (*internal.Result, error) {
+func (m *MockDebugger) DebugLogs(config, payload string, _ ...internal.ExecutionOption) (*internal.Result, error) {
 	args := m.Called(config, payload)
 	return args.Get(0).(*internal.Result), args.Error(1)
 }
 
-func (m *MockDebugger) DebugTraces(config, payload string) (*internal.Result, error) {
+func (m *MockDebugger) DebugTraces(config, payload string, _ ...internal.ExecutionOption) (*internal.Result, error) {
 	args := m.Called(config, payload)
 	return args.Get(0).(*internal.Result), args.Error(1)
 }
 
-func (m *MockDebugger) DebugMetrics(config, payload string) (*internal.Result, error) {
+func (m *MockDebugger) DebugMetrics(config, payload string, _ ...internal.ExecutionOption) (*internal.Result, error) {
 	args := m.Called(config, payload)
 	return args.Get(0).(*internal.Result), args.Error(1)
 }
 
-func (m *MockDebugger) DebugProfiles(config, payload string) (*internal.Result, error) {
+func (m *MockDebugger) DebugProfiles(config, payload string, _ ...internal.ExecutionOption) (*internal.Result, error) {
 	args := m.Called(config, payload)
 	return args.Get(0).(*internal.Result), args.Error(1)
 }
 
-func (m *MockDebugger) ObservedLogs() *internal.ObservedLogs {
-	args := m.Called()
-	return args.Get(0).(*internal.ObservedLogs)
+func Test_ParseExecutionOptions(t *testing.T) {
+	options, err := ParseExecutionOptions("")
+	assert.NoError(t, err)
+	assert.Equal(t, ExecutionOptions{}, options)
+
+	options, err = ParseExecutionOptions(`{"logLevel":"warn"}`)
+	assert.NoError(t, err)
+	assert.Equal(t, ExecutionOptions{LogLevel: "warn"}, options)
+
+	options, err = ParseExecutionOptions(`{"featureGates":{"some.gate":true}}`)
+	assert.NoError(t, err)
+	assert.Equal(t, ExecutionOptions{FeatureGates: map[string]bool{"some.gate": true}}, options)
+
+	options, err = ParseExecutionOptions(`{"clientMetadata":{"x-tenant":["a"]},"authAttributes":{"subject":"user"}}`)
+	assert.NoError(t, err)
+	assert.Equal(t, ExecutionOptions{
+		ClientMetadata: map[string][]string{"x-tenant": {"a"}},
+		AuthAttributes: map[string]any{"subject": "user"},
+	}, options)
+
+	options, err = ParseExecutionOptions(`{"timeoutMillis":500}`)
+	assert.NoError(t, err)
+	assert.Equal(t, ExecutionOptions{TimeoutMillis: 500}, options)
+
+	options, err = ParseExecutionOptions(`{"fixedTime":"2025-01-02T03:04:05Z"}`)
+	assert.NoError(t, err)
+	assert.Equal(t, ExecutionOptions{FixedTime: "2025-01-02T03:04:05Z"}, options)
+
+	options, err = ParseExecutionOptions(`{"benchmarkRuns":10,"benchmarkDurationMillis":100}`)
+	assert.NoError(t, err)
+	assert.Equal(t, ExecutionOptions{BenchmarkRuns: 10, BenchmarkDurationMillis: 100}, options)
+
+	_, err = ParseExecutionOptions("{invalid")
+	assert.ErrorContains(t, err, "invalid execution options")
+}
+
+func Test_ExecuteWithOptions_InvalidLogLevel(t *testing.T) {
+	result := ExecuteWithOptions("empty", "logs", "{}", "transform_processor", false, ExecutionOptions{LogLevel: "verbose"})
+	assert.Contains(t, result["error"], "invalid execution options")
+}
+
+func Test_ExecuteWithOptions_LogLevel(t *testing.T) {
+	config := "transform:\n  error_mode: ignore\n  log_statements:\n    - merge_maps(log.attributes, ParseJSON(log.body), \"upsert\")"
+	payload := `{"resourceLogs":[{"scopeLogs":[{"logRecords":[{"body":{"stringValue":"log"}}]}]}]}`
+
+	result := ExecuteWithOptions(config, "logs", payload, "transform_processor", false, ExecutionOptions{LogLevel: "warn"})
+	assert.NotContains(t, result, "error")
+
+	entries, ok := result["logEntries"].([]any)
+	if assert.True(t, ok) && assert.NotEmpty(t, entries) {
+		for _, entry := range entries {
+			assert.Equal(t, "warn", entry.(map[string]any)["level"])
+		}
+	}
+}
+
+func Test_ExecuteWithOptions_UnknownFeatureGate(t *testing.T) {
+	config := "transform:\n  log_statements:\n    - set(log.attributes[\"a\"], 1)"
+	payload := `{"resourceLogs":[{"scopeLogs":[{"logRecords":[{"body":{"stringValue":"log"}}]}]}]}`
+
+	result := ExecuteWithOptions(config, "logs", payload, "transform_processor", false, ExecutionOptions{FeatureGates: map[string]bool{"unknown.gate": true}})
+	assert.Contains(t, result["error"], `unknown feature gate "unknown.gate"`)
+}
+
+func Test_FeatureGates(t *testing.T) {
+	gates := FeatureGates()
+	require.NotEmpty(t, gates)
+	for _, gate := range gates {
+		gateValue := gate.(map[string]any)
+		assert.NotEmpty(t, gateValue["id"])
+		assert.NotEmpty(t, gateValue["stage"])
+		assert.Contains(t, gateValue, "enabled")
+	}
+}
+
+func Test_ExecuteWithOptions_InvalidTimeout(t *testing.T) {
+	result := ExecuteWithOptions("empty", "logs", "{}", "transform_processor", false, ExecutionOptions{TimeoutMillis: -1})
+	assert.Contains(t, result["error"], "invalid timeout -1ms")
+}
+
+func Test_ExecuteWithOptions_FixedTime(t *testing.T) {
+	config := "transform:\n  log_statements:\n    - set(log.attributes[\"now\"], String(Now()))"
+	payload := `{"resourceLogs":[{"scopeLogs":[{"logRecords":[{"body":{"stringValue":"log"}}]}]}]}`
+
+	result := ExecuteWithOptions(config, "logs", payload, "transform_processor", false, ExecutionOptions{FixedTime: "2025-01-02T03:04:05Z"})
+	assert.NotContains(t, result, "error")
+	assert.Contains(t, result["value"], "2025-01-02T03:04:05Z")
+	assert.Equal(t, true, result["deterministic"])
+
+	result = ExecuteWithOptions(config, "logs", payload, "transform_processor", false, ExecutionOptions{FixedTime: "yesterday"})
+	assert.Contains(t, result["error"], `invalid fixed time "yesterday"`)
+}
+
+func Test_ExecuteWithOptions_Benchmark(t *testing.T) {
+	config := "transform:\n  log_statements:\n    - set(log.attributes[\"a\"], log.body)"
+	payload := `{"resourceLogs":[{"scopeLogs":[{"logRecords":[{"body":{"stringValue":"log"}}]}]}]}`
+
+	result := ExecuteWithOptions(config, "logs", payload, "transform_processor", false, ExecutionOptions{BenchmarkRuns: 3})
+	assert.NotContains(t, result, "error")
+	benchmark, ok := result["benchmark"].(map[string]any)
+	require.True(t, ok)
+	assert.Equal(t, float64(3), benchmark["runs"])
+
+	result = ExecuteWithOptions(config, "logs", payload, "transform_processor", false, ExecutionOptions{BenchmarkRuns: -1})
+	assert.Contains(t, result["error"], "invalid benchmark runs -1")
+
+	result = ExecuteWithOptions(config, "logs", payload, "transform_processor", false, ExecutionOptions{BenchmarkDurationMillis: -1})
+	assert.Contains(t, result["error"], "invalid benchmark duration -1ms")
+}
+
+func Test_ExecuteWithOptions_Profile(t *testing.T) {
+	config := "transform:\n  log_statements:\n    - set(log.attributes[\"a\"], log.body)"
+	payload := `{"resourceLogs":[{"scopeLogs":[{"logRecords":[{"body":{"stringValue":"log"}}]}]}]}`
+
+	result := ExecuteWithOptions(config, "logs", payload, "transform_processor", false, ExecutionOptions{Profile: true, BenchmarkRuns: 2})
+	assert.NotContains(t, result, "error")
+	profile, ok := result["profile"].([]any)
+	require.True(t, ok)
+	require.Len(t, profile, 1)
+	assert.Equal(t, `set(log.attributes["a"], log.body)`, profile[0].(map[string]any)["statement"])
+
+	result = ExecuteWithOptions(config, "logs", payload, "transform_processor", true, ExecutionOptions{Profile: true})
+	assert.Contains(t, result["error"], "profiling and debugging can't be combined")
+
+	result = ExecuteWithOptions("filter:\n  logs:\n    log_record:\n      - 'true'", "logs", payload, "filter_processor", false, ExecutionOptions{Profile: true})
+	assert.Contains(t, result["error"], `executor "Filter" does not support profiling`)
+}
+
+func Test_DebugSession(t *testing.T) {
+	config := "transform:\n  log_statements:\n    - set(log.attributes[\"a\"], true)\n    - set(log.attributes[\"b\"], true)\n    - set(log.attributes[\"c\"], true)"
+	payload := `{"resourceLogs":[{"scopeLogs":[{"logRecords":[{"body":{"stringValue":"log"}}]}]}]}`
+
+	result := StartDebugSession(config, "logs", payload, "transform_processor", `[{"line":4}]`, ExecutionOptions{})
+	require.NotContains(t, result, "error")
+	handle, ok := result["session"].(int)
+	require.True(t, ok)
+	paused := result["paused"].(map[string]any)
+	assert.Equal(t, float64(4), paused["line"])
+	assert.Contains(t, paused["payload"], `"a"`)
+
+	result = StepDebugSession(handle)
+	require.NotContains(t, result, "error")
+	assert.Equal(t, handle, result["session"])
+	assert.Equal(t, float64(5), result["paused"].(map[string]any)["line"])
+
+	// The session is closed once it ran all of its steps
+	result = ContinueDebugSession(handle)
+	require.NotContains(t, result, "error")
+	assert.NotContains(t, result, "session")
+	assert.NotContains(t, result, "paused")
+
+	result = StepDebugSession(handle)
+	assert.Equal(t, fmt.Sprintf("unknown debug session %d", handle), result["error"])
+}
+
+func Test_CloseDebugSession(t *testing.T) {
+	config := "transform:\n  log_statements:\n    - set(log.attributes[\"a\"], true)"
+	payload := `{"resourceLogs":[{"scopeLogs":[{"logRecords":[{"body":{"stringValue":"log"}}]}]}]}`
+
+	result := StartDebugSession(config, "logs", payload, "transform_processor", `[{"line":3}]`, ExecutionOptions{})
+	require.NotContains(t, result, "error")
+	handle := result["session"].(int)
+
+	CloseDebugSession(handle)
+	CloseDebugSession(handle)
+	result = ContinueDebugSession(handle)
+	assert.Contains(t, result["error"], "unknown debug session")
+}
+
+func Test_DebugSession_Evicted(t *testing.T) {
+	config := "transform:\n  log_statements:\n    - set(log.attributes[\"a\"], true)\n    - set(log.attributes[\"b\"], true)\n    - set(log.attributes[\"c\"], true)"
+	payload := `{"resourceLogs":[{"scopeLogs":[{"logRecords":[{"body":{"stringValue":"log"}}]}]}]}`
+
+	var handles []int
+	defer func() {
+		for _, handle := range handles {
+			CloseDebugSession(handle)
+		}
+	}()
+	start := func() int {
+		result := StartDebugSession(config, "logs", payload, "transform_processor", `[{"line":3}]`, ExecutionOptions{})
+		require.NotContains(t, result, "error")
+		handle := result["session"].(int)
+		handles = append(handles, handle)
+		return handle
+	}
+
+	first := start()
+	for len(debugSessions) < maxDebugSessions {
+		start()
+	}
+	second := handles[1]
+
+	// The first session is used again, so the second one is the least
+	// recently used when opening a new one
+	result := StepDebugSession(first)
+	require.NotContains(t, result, "error")
+	start()
+	assert.Len(t, debugSessions, maxDebugSessions)
+
+	result = StepDebugSession(second)
+	assert.Equal(t, fmt.Sprintf("unknown debug session %d", second), result["error"])
+	result = StepDebugSession(first)
+	require.NotContains(t, result, "error")
+	assert.Equal(t, first, result["session"])
+}
+
+func Test_StartDebugSession_Errors(t *testing.T) {
+	config := "transform:\n  log_statements:\n    - set(log.attributes[\"a\"], true)"
+	payload := `{"resourceLogs":[{"scopeLogs":[{"logRecords":[{"body":{"stringValue":"log"}}]}]}]}`
+
+	result := StartDebugSession(config, "logs", payload, "unknown", "", ExecutionOptions{})
+	assert.Contains(t, result["error"], "unsupported executor unknown")
+
+	result = StartDebugSession(config, "logs", payload, "transform_processor", "{", ExecutionOptions{})
+	assert.Contains(t, result["error"], "invalid breakpoints")
+
+	result = StartDebugSession(config, "logs", payload, "transform_processor", `[{}]`, ExecutionOptions{})
+	assert.Contains(t, result["error"], "invalid breakpoint 0")
+
+	result = StartDebugSession(config, "logs", payload, "transform_processor", "", ExecutionOptions{Profile: true})
+	assert.Contains(t, result["error"], "profiling and debugging can't be combined")
+
+	result = StartDebugSession(config, "spans", payload, "transform_processor", "", ExecutionOptions{})
+	assert.Contains(t, result["error"], "unsupported OTLP signal type spans")
+
+	result = StartDebugSession("filter:\n  logs:\n    log_record:\n      - 'true'", "logs", payload, "filter_processor", "", ExecutionOptions{})
+	assert.Contains(t, result["error"], `executor "filter_processor" does not support debug sessions`)
 }
@@ -42,7 +42,7 @@ func handlePanic() {
 func executeWrapper() js.Func {
 	return js.FuncOf(func(_ js.Value, args []js.Value) any {
 		defer handlePanic()
-		if len(args) != 5 {
+		if len(args) != 5 && len(args) != 6 {
 			return map[string]any{"error": "invalid number of arguments"}
 		}
 
@@ -51,7 +51,61 @@ func executeWrapper() js.Func {
 		ottlDataPayload := args[2].String()
 		executorName := args[3].String()
 		debug := args[4].Bool()
-		return js.ValueOf(internal.Execute(config, ottlDataType, ottlDataPayload, executorName, debug))
+
+		var options internal.ExecutionOptions
+		if len(args) == 6 && args[5].Type() == js.TypeString {
+			var err error
+			options, err = internal.ParseExecutionOptions(args[5].String())
+			if err != nil {
+				return map[string]any{"error": err.Error()}
+			}
+		}
+		return js.ValueOf(internal.ExecuteWithOptions(config, ottlDataType, ottlDataPayload, executorName, debug, options))
+	})
+}
+
+func startDebugSessionWrapper() js.Func {
+	return js.FuncOf(func(_ js.Value, args []js.Value) any {
+		defer handlePanic()
+		if len(args) != 5 && len(args) != 6 {
+			return map[string]any{"error": "invalid number of arguments"}
+		}
+
+		config := args[0].String()
+		ottlDataType := args[1].String()
+		ottlDataPayload := args[2].String()
+		executorName := args[3].String()
+		breakpoints := args[4].String()
+
+		var options internal.ExecutionOptions
+		if len(args) == 6 && args[5].Type() == js.TypeString {
+			var err error
+			options, err = internal.ParseExecutionOptions(args[5].String())
+			if err != nil {
+				return map[string]any{"error": err.Error()}
+			}
+		}
+		return js.ValueOf(internal.StartDebugSession(config, ottlDataType, ottlDataPayload, executorName, breakpoints, options))
+	})
+}
+
+func debugSessionWrapper(run func(handle int) map[string]any) js.Func {
+	return js.FuncOf(func(_ js.Value, args []js.Value) any {
+		defer handlePanic()
+		if len(args) != 1 {
+			return map[string]any{"error": "invalid number of arguments"}
+		}
+		return js.ValueOf(run(args[0].Int()))
+	})
+}
+
+func closeDebugSessionWrapper() js.Func {
+	return js.FuncOf(func(_ js.Value, args []js.Value) any {
+		defer handlePanic()
+		if len(args) == 1 {
+			internal.CloseDebugSession(args[0].Int())
+		}
+		return nil
 	})
 }
 
@@ -62,8 +116,20 @@ func getExecutorsWrapper() js.Func {
 	})
 }
 
+func getFeatureGatesWrapper() js.Func {
+	return js.FuncOf(func(_ js.Value, _ []js.Value) any {
+		defer handlePanic()
+		return js.ValueOf(internal.FeatureGates())
+	})
+}
+
 func main() {
 	js.Global().Set("execute", executeWrapper())
+	js.Global().Set("startDebugSession", startDebugSessionWrapper())
+	js.Global().Set("stepDebugSession", debugSessionWrapper(internal.StepDebugSession))
+	js.Global().Set("continueDebugSession", debugSessionWrapper(internal.ContinueDebugSession))
+	js.Global().Set("closeDebugSession", closeDebugSessionWrapper())
 	js.Global().Set("getExecutors", getExecutorsWrapper())
+	js.Global().Set("getFeatureGates", getFeatureGatesWrapper())
 	<-make(chan struct{})
 }
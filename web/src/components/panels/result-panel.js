@@ -33,6 +33,9 @@ const VIEW_VISUAL_DELTA = 'visual_delta';
 const VIEW_ANNOTATED_DELTA = 'annotated_delta';
 const VIEW_JSON = 'json';
 const VIEW_LOGS = 'logs';
+const VIEW_INTERNAL_METRICS = 'internal_metrics';
+const VIEW_INTERNAL_SPANS = 'internal_spans';
+const VIEW_BATCHES = 'batches';
 
 export class PlaygroundResultPanel extends LitElement {
   static properties = {
@@ -55,6 +58,9 @@ export class PlaygroundResultPanel extends LitElement {
       [VIEW_ANNOTATED_DELTA]: {enabled: true},
       [VIEW_JSON]: {enabled: true},
       [VIEW_LOGS]: {enabled: true},
+      [VIEW_INTERNAL_METRICS]: {enabled: true},
+      [VIEW_INTERNAL_SPANS]: {enabled: true},
+      [VIEW_BATCHES]: {enabled: true},
     };
     this._wrapLines = false;
     this._showUnchanged = false;
@@ -122,7 +128,7 @@ export class PlaygroundResultPanel extends LitElement {
             ? html`
                 <div
                   class="execution-time-header"
-                  title="Estimated execution time"
+                  title="${this._executionTimeTitle(this.result)}"
                 >
                   <span>
                     <span>${this.result?.executionTime} ms</span>
@@ -203,7 +209,14 @@ export class PlaygroundResultPanel extends LitElement {
   }
 
   _showWrapLinesOption() {
-    return this.view && (this.view === VIEW_JSON || this.view === VIEW_LOGS);
+    return (
+      this.view &&
+      (this.view === VIEW_JSON ||
+        this.view === VIEW_LOGS ||
+        this.view === VIEW_INTERNAL_METRICS ||
+        this.view === VIEW_INTERNAL_SPANS ||
+        this.view === VIEW_BATCHES)
+    );
   }
 
   _selectedViewChanged(event) {
@@ -267,6 +280,18 @@ export class PlaygroundResultPanel extends LitElement {
       if (this.viewConfig[VIEW_LOGS]?.enabled) {
         this._views.push({id: VIEW_LOGS, name: 'Execution logs'});
       }
+      if (this.viewConfig[VIEW_INTERNAL_METRICS]?.enabled) {
+        this._views.push({
+          id: VIEW_INTERNAL_METRICS,
+          name: 'Internal metrics',
+        });
+      }
+      if (this.viewConfig[VIEW_INTERNAL_SPANS]?.enabled) {
+        this._views.push({id: VIEW_INTERNAL_SPANS, name: 'Internal spans'});
+      }
+      if (this.viewConfig[VIEW_BATCHES]?.enabled) {
+        this._views.push({id: VIEW_BATCHES, name: 'Emitted batches'});
+      }
       if (this.viewConfig[this.view]?.enabled === false) {
         this.view = this._views[0].id;
       }
@@ -282,7 +307,28 @@ export class PlaygroundResultPanel extends LitElement {
 
     this._errored = !!this.result.error;
     if (this.view === VIEW_LOGS) {
-      this._renderExecutionLogsResult(rerender);
+      this._renderTextViewResult(rerender, this.result.logs);
+      return;
+    }
+
+    if (this.view === VIEW_INTERNAL_METRICS) {
+      this._renderTextViewResult(
+        rerender,
+        this._prettyJson(this.result.internalMetrics)
+      );
+      return;
+    }
+
+    if (this.view === VIEW_INTERNAL_SPANS) {
+      this._renderTextViewResult(
+        rerender,
+        this._prettyJson(this.result.internalSpans)
+      );
+      return;
+    }
+
+    if (this.view === VIEW_BATCHES) {
+      this._renderTextViewResult(rerender, this._batchesText(this.result));
       return;
     }
 
@@ -294,7 +340,47 @@ export class PlaygroundResultPanel extends LitElement {
     }
   }
 
-  _renderExecutionLogsResult(rerender) {
+  _executionTimeTitle(result) {
+    let title = 'Estimated execution time';
+    if (result.timings) {
+      let timings = result.timings;
+      title += `\nCompile: ${timings.compile} µs`;
+      title += `\n  Parse: ${timings.parse} µs`;
+      title += `\n  Validate: ${timings.validate} µs`;
+      title += `\n  Create: ${timings.create} µs`;
+      title += `\n  Start: ${timings.start} µs`;
+      title += `\nExecution: ${timings.execution} µs`;
+      title += `\nMarshal: ${timings.marshal} µs`;
+    }
+    if (result.cache) {
+      title += `\nCache: ${result.cache}`;
+    }
+    return title;
+  }
+
+  _batchesText(result) {
+    if (result.error) {
+      return result.error;
+    }
+    // Results only list the batches when the component emitted more than one
+    let batches = result.batches ?? (result.value ? [result.value] : []);
+    return batches
+      .map((batch, i) => `// Batch ${i + 1}\n${this._prettyJson(batch)}`)
+      .join('\n\n');
+  }
+
+  _prettyJson(value) {
+    if (!value) {
+      return '';
+    }
+    try {
+      return JSON.stringify(JSON.parse(value), null, 2);
+    } catch (e) {
+      return value;
+    }
+  }
+
+  _renderTextViewResult(rerender, text) {
     if (!this._logsViewEditor) {
       const selectionHighlight = EditorView.theme({
         '&.cm-focused > .cm-scroller > .cm-selectionLayer .cm-selectionBackground, .cm-selectionBackground, .cm-content ::selection':
@@ -324,16 +410,17 @@ export class PlaygroundResultPanel extends LitElement {
       }
     }
 
+    let content = text || '';
     let anchor = this._logsViewEditor.state?.selection?.main?.anchor;
-    if (anchor > this.result.logs.length) {
-      anchor = this.result.logs.length;
+    if (anchor > content.length) {
+      anchor = content.length;
     }
 
     this._logsViewEditor.dispatch({
       changes: {
         from: 0,
         to: this._logsViewEditor.state.doc.length,
-        insert: this.result.logs,
+        insert: content,
       },
       selection: {anchor: anchor},
     });